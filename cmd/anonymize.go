@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+)
+
+// anonymizedNameLength is the number of hex characters of the SHA-256 of a repository name shown by --anonymize.
+const anonymizedNameLength = 8
+
+// anonymizeRepoNames replaces every repository name with its hash, so the same repository gets the same name in
+// every run. Node IDs are cleared too, since they can be resolved back to the repository through the API.
+// The results are sorted again by the new names and then by user, as the real names' order would give them away.
+func anonymizeRepoNames(results []*domain.RepoStats) {
+	names := make([]string, len(results))
+	for i, r := range results {
+		names[i] = r.Name
+	}
+	hashed := anonymizedNames(names, anonymizedNameLength)
+	for _, r := range results {
+		r.Name = hashed[r.Name]
+		r.NodeID = ""
+	}
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Name != results[j].Name {
+			return results[i].Name < results[j].Name
+		}
+		return results[i].User < results[j].User
+	})
+}
+
+// anonymizedNames maps each name to the first length hex characters of its SHA-256.
+// Names whose shortened hashes collide get the full hash instead, so distinct repositories stay distinct.
+// A name's result thus depends on the other names: it only stays the same across runs while no colliding name joins it.
+func anonymizedNames(names []string, length int) map[string]string {
+	full := make(map[string]string, len(names))
+	byShort := make(map[string][]string, len(names))
+	for _, name := range names {
+		if _, ok := full[name]; ok {
+			continue // The same repository for several users.
+		}
+		sum := sha256.Sum256([]byte(name))
+		full[name] = hex.EncodeToString(sum[:])
+		short := full[name][:length]
+		byShort[short] = append(byShort[short], name)
+	}
+
+	hashed := make(map[string]string, len(full))
+	for short, group := range byShort {
+		for _, name := range group {
+			if len(group) > 1 {
+				hashed[name] = full[name]
+			} else {
+				hashed[name] = short
+			}
+		}
+	}
+	return hashed
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeRepoNames(t *testing.T) {
+	run := func() []*domain.RepoStats {
+		results := []*domain.RepoStats{
+			{Name: "org/repo-a", User: "alice", NodeID: "R_1", Commits: 3},
+			{Name: "org/repo-b", User: "alice", Commits: 1},
+			{Name: "org/repo-a", User: "bob", NodeID: "R_1", Commits: 2},
+		}
+		anonymizeRepoNames(results)
+		return results
+	}
+
+	first, second := run(), run()
+	assert.Equal(t, first, second, "the same repository gets the same name in every run")
+	byCommits := make(map[int]*domain.RepoStats, len(first))
+	for _, r := range first {
+		assert.Len(t, r.Name, anonymizedNameLength)
+		assert.NotContains(t, r.Name, "repo")
+		assert.Empty(t, r.NodeID)
+		byCommits[r.Commits] = r
+	}
+	assert.Equal(t, byCommits[3].Name, byCommits[2].Name, "a repository shared by several users keeps one name")
+	assert.NotEqual(t, byCommits[3].Name, byCommits[1].Name)
+}
+
+func TestAnonymizeRepoNames_SortsByAnonymizedName(t *testing.T) {
+	names := []string{"org/alpha", "org/bravo", "org/charlie", "org/delta", "org/echo"}
+	var results []*domain.RepoStats
+	for _, name := range names {
+		// bob before alice, to check the users are sorted within a repository too.
+		results = append(results, &domain.RepoStats{Name: name, User: "bob"}, &domain.RepoStats{Name: name, User: "alice"})
+	}
+	hashed := anonymizedNames(names, anonymizedNameLength)
+
+	anonymizeRepoNames(results)
+
+	expected := make([]string, 0, len(names))
+	for _, name := range names {
+		expected = append(expected, hashed[name])
+	}
+	sort.Strings(expected)
+	for i, r := range results {
+		assert.Equal(t, expected[i/2], r.Name, "row %d follows the anonymized names, not the real ones", i)
+		assert.Equal(t, []string{"alice", "bob"}[i%2], r.User)
+	}
+}
+
+func TestAnonymizedNames_Collisions(t *testing.T) {
+	// With a single hex character, 20 names are bound to collide.
+	names := make([]string, 20)
+	for i := range names {
+		names[i] = fmt.Sprintf("org/repo-%d", i)
+	}
+	hashed := anonymizedNames(names, 1)
+
+	seen := make(map[string]bool)
+	collided := 0
+	for _, name := range names {
+		assert.False(t, seen[hashed[name]], "%s shares its anonymized name", name)
+		seen[hashed[name]] = true
+		if len(hashed[name]) > 1 {
+			collided++
+		}
+	}
+	assert.Positive(t, collided, "colliding names fall back to the full hash")
+	assert.Equal(t, hashed, anonymizedNames(names, 1))
+}
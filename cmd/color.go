@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Supported values for the --color flag.
+const (
+	colorAuto   = "auto"
+	colorAlways = "always"
+	colorNever  = "never"
+)
+
+// ANSI escape codes used to highlight table cells.
+const (
+	ansiBold  = "\x1b[1m"
+	ansiReset = "\x1b[0m"
+)
+
+// validateColor returns an error when mode is not a supported --color value.
+func validateColor(mode string) error {
+	switch mode {
+	case colorAuto, colorAlways, colorNever:
+		return nil
+	}
+	return fmt.Errorf("unsupported color mode %q (valid: %s, %s, %s)", mode, colorAuto, colorAlways, colorNever)
+}
+
+// useColor decides whether output written to out gets ANSI colors. In auto mode it does when out is a terminal
+// and the NO_COLOR environment variable (https://no-color.org) is unset or empty.
+func useColor(mode string, out io.Writer, getenv func(string) string) bool {
+	switch mode {
+	case colorAlways:
+		return true
+	case colorNever:
+		return false
+	}
+	f, ok := out.(*os.File)
+	return ok && getenv("NO_COLOR") == "" && isTerminal(f)
+}
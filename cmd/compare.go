@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/montanaflynn/stats"
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/naka-gawa/github-stats/internal/gateway"
+	"github.com/naka-gawa/github-stats/internal/usecase"
+	"github.com/spf13/cobra"
+)
+
+// PeriodStats holds the compared metrics of one repository in one period.
+type PeriodStats struct {
+	Commits          int      `json:"commits"`
+	CreatedPRs       int      `json:"created_prs"`
+	ReviewedPRs      int      `json:"reviewed_prs"`
+	LeadTimeP50Hours *float64 `json:"lead_time_p50_hours,omitempty"`
+}
+
+// MetricDelta is the change of a metric from the previous to the current period.
+// Percent is relative to the previous value and null when the previous value is zero.
+type MetricDelta struct {
+	Absolute float64  `json:"absolute"`
+	Percent  *float64 `json:"percent"`
+}
+
+// PeriodDelta holds the change of every compared metric.
+// LeadTimeP50Hours is only present when both periods have a lead time.
+type PeriodDelta struct {
+	Commits          MetricDelta  `json:"commits"`
+	CreatedPRs       MetricDelta  `json:"created_prs"`
+	ReviewedPRs      MetricDelta  `json:"reviewed_prs"`
+	LeadTimeP50Hours *MetricDelta `json:"lead_time_p50_hours,omitempty"`
+}
+
+// CompareRepoStats is one repository in the output of the compare command.
+type CompareRepoStats struct {
+	Name     string      `json:"name"`
+	User     string      `json:"user,omitempty"`
+	Current  PeriodStats `json:"current"`
+	Previous PeriodStats `json:"previous"`
+	Delta    PeriodDelta `json:"delta"`
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare",
+	Short: "Compares a user's activity between two periods",
+	Long: `Aggregates the activity of the current (--from/--to) and the previous (--prev-from/--prev-to) period
+and outputs both per repository along with the absolute and percentage change, in JSON format.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		verbose, _ := cmd.InheritedFlags().GetBool("verbose")
+		logFormat, _ := cmd.InheritedFlags().GetString("log-format")
+		logger, err := newLogger(os.Stderr, verbose, logFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		orgs, _ := cmd.Flags().GetStringSlice("org")
+		users, _ := cmd.Flags().GetStringSlice("user")
+		tokenFile, _ := cmd.Flags().GetString("token-file")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+
+		orgs, err = normalizeNames("--org", orgs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		users, err = normalizeNames("--user", users)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		var dates [4]time.Time
+		for i, name := range []string{"from", "to", "prev-from", "prev-to"} {
+			value, _ := cmd.Flags().GetString(name)
+			dates[i], err = time.Parse("2006/01/02", value)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --%s date format: %v\n", name, err)
+				os.Exit(1)
+			}
+		}
+		from, to, prevFrom, prevTo := dates[0], dates[1], dates[2], dates[3]
+		if to.Before(from) || prevTo.Before(prevFrom) {
+			fmt.Fprintln(os.Stderr, "Error: --to and --prev-to must not be before --from and --prev-from.")
+			os.Exit(1)
+		}
+
+		token, err := resolveToken(tokenFile, os.Getenv, execRunner{})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		gatewayOpts, _ := serverOptions(baseURL, os.Getenv)
+		githubGateway, err := gateway.NewGitHubGateway(token, logger, gatewayOpts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize GitHub gateway: %v\n", err)
+			os.Exit(1)
+		}
+		aggregator := usecase.NewAggregator(githubGateway, logger)
+
+		ctx := context.Background()
+		aggregate := func(from, to time.Time) ([]*domain.RepoStats, error) {
+			logger.Printf("Aggregating %s\n", describeDateRange(from, to))
+			commitDateRange, prDateRange := usecase.DateRangeQualifiers(from, to)
+			return aggregator.AggregateUsers(ctx, strings.Join(orgs, ","), users, usecase.Options{
+				CommitDateRange:   commitDateRange,
+				PRDateRange:       prDateRange,
+				CalculateLeadTime: true,
+			})
+		}
+		current, err := aggregate(from, to)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to aggregate the current period: %v\n", err)
+			os.Exit(1)
+		}
+		previous, err := aggregate(prevFrom, prevTo)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to aggregate the previous period: %v\n", err)
+			os.Exit(1)
+		}
+
+		jsonData, err := json.MarshalIndent(compareResults(current, previous), "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to marshal results to JSON: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println(string(jsonData))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(compareCmd)
+	compareCmd.Flags().StringSliceP("org", "o", nil, "Target GitHub organization names, comma-separated or repeated (required)")
+	compareCmd.Flags().StringSliceP("user", "u", nil, "Target GitHub user names, comma-separated or repeated (required)")
+	compareCmd.Flags().String("from", "", "Start of the current period (YYYY/MM/DD, required)")
+	compareCmd.Flags().String("to", "", "End of the current period (YYYY/MM/DD, required)")
+	compareCmd.Flags().String("prev-from", "", "Start of the previous period (YYYY/MM/DD, required)")
+	compareCmd.Flags().String("prev-to", "", "End of the previous period (YYYY/MM/DD, required)")
+	compareCmd.Flags().String("token-file", "", "Read the GitHub token from `file` instead of GITHUB_TOKEN")
+	compareCmd.Flags().String("base-url", "", "GitHub Enterprise Server URL, e.g. https://github.example.com (default: GITHUB_BASE_URL, then the GITHUB_API_URL set by GitHub Actions, or github.com)")
+	for _, name := range []string{"org", "user", "from", "to", "prev-from", "prev-to"} {
+		compareCmd.MarkFlagRequired(name)
+	}
+}
+
+// compareResults pairs the repositories of both periods by user and name and computes the deltas.
+// A repository active in only one period is compared against zeros. The result is sorted by user and name.
+func compareResults(current, previous []*domain.RepoStats) []CompareRepoStats {
+	type key struct{ user, name string }
+	byKey := make(map[key]*CompareRepoStats)
+	entry := func(repoStat *domain.RepoStats) *CompareRepoStats {
+		k := key{repoStat.User, repoStat.Name}
+		if byKey[k] == nil {
+			byKey[k] = &CompareRepoStats{Name: repoStat.Name, User: repoStat.User}
+		}
+		return byKey[k]
+	}
+	for _, repoStat := range current {
+		entry(repoStat).Current = periodStats(repoStat)
+	}
+	for _, repoStat := range previous {
+		entry(repoStat).Previous = periodStats(repoStat)
+	}
+
+	results := make([]CompareRepoStats, 0, len(byKey))
+	for _, result := range byKey {
+		result.Delta = PeriodDelta{
+			Commits:     metricDelta(float64(result.Current.Commits), float64(result.Previous.Commits)),
+			CreatedPRs:  metricDelta(float64(result.Current.CreatedPRs), float64(result.Previous.CreatedPRs)),
+			ReviewedPRs: metricDelta(float64(result.Current.ReviewedPRs), float64(result.Previous.ReviewedPRs)),
+		}
+		if result.Current.LeadTimeP50Hours != nil && result.Previous.LeadTimeP50Hours != nil {
+			delta := metricDelta(*result.Current.LeadTimeP50Hours, *result.Previous.LeadTimeP50Hours)
+			result.Delta.LeadTimeP50Hours = &delta
+		}
+		results = append(results, *result)
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].User != results[j].User {
+			return results[i].User < results[j].User
+		}
+		return results[i].Name < results[j].Name
+	})
+	return results
+}
+
+// periodStats extracts the compared metrics of a repository. The lead time P50 is nil without analyzed PRs.
+func periodStats(repoStat *domain.RepoStats) PeriodStats {
+	period := PeriodStats{
+		Commits:     repoStat.Commits,
+		CreatedPRs:  repoStat.CreatedPRs,
+		ReviewedPRs: repoStat.ReviewedPRs,
+	}
+	if p50, err := stats.Percentile(repoStat.LeadTimeToLastReviewSeconds, 50); err == nil {
+		hours := p50 / unitSecondsPerUnit[unitHours]
+		period.LeadTimeP50Hours = &hours
+	}
+	return period
+}
+
+// metricDelta returns the change from previous to current.
+func metricDelta(current, previous float64) MetricDelta {
+	delta := MetricDelta{Absolute: current - previous}
+	if previous != 0 {
+		percent := (current - previous) / previous * 100
+		delta.Percent = &percent
+	}
+	return delta
+}
@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestCompareResults(t *testing.T) {
+	current := []*domain.RepoStats{
+		{Name: "org/api", Commits: 15, CreatedPRs: 4, ReviewedPRs: 2, LeadTimeToLastReviewSeconds: []float64{3600, 7200, 10800}},
+		{Name: "org/new", Commits: 3},
+	}
+	previous := []*domain.RepoStats{
+		{Name: "org/api", Commits: 10, CreatedPRs: 4, ReviewedPRs: 4, LeadTimeToLastReviewSeconds: []float64{14400}},
+		{Name: "org/old", CreatedPRs: 2},
+	}
+
+	assert.Equal(t, []CompareRepoStats{
+		{
+			Name:     "org/api",
+			Current:  PeriodStats{Commits: 15, CreatedPRs: 4, ReviewedPRs: 2, LeadTimeP50Hours: ptr(2.0)},
+			Previous: PeriodStats{Commits: 10, CreatedPRs: 4, ReviewedPRs: 4, LeadTimeP50Hours: ptr(4.0)},
+			Delta: PeriodDelta{
+				Commits:          MetricDelta{Absolute: 5, Percent: ptr(50.0)},
+				CreatedPRs:       MetricDelta{Absolute: 0, Percent: ptr(0.0)},
+				ReviewedPRs:      MetricDelta{Absolute: -2, Percent: ptr(-50.0)},
+				LeadTimeP50Hours: &MetricDelta{Absolute: -2, Percent: ptr(-50.0)},
+			},
+		},
+		{
+			Name:    "org/new",
+			Current: PeriodStats{Commits: 3},
+			Delta: PeriodDelta{
+				Commits: MetricDelta{Absolute: 3},
+			},
+		},
+		{
+			Name:     "org/old",
+			Previous: PeriodStats{CreatedPRs: 2},
+			Delta: PeriodDelta{
+				CreatedPRs: MetricDelta{Absolute: -2, Percent: ptr(-100.0)},
+			},
+		},
+	}, compareResults(current, previous))
+}
+
+func TestCompareResults_SeparatesUsers(t *testing.T) {
+	current := []*domain.RepoStats{{Name: "org/api", User: "bob", Commits: 1}, {Name: "org/api", User: "alice", Commits: 2}}
+	previous := []*domain.RepoStats{{Name: "org/api", User: "alice", Commits: 4}}
+
+	results := compareResults(current, previous)
+
+	assert.Len(t, results, 2)
+	assert.Equal(t, "alice", results[0].User)
+	assert.Equal(t, MetricDelta{Absolute: -2, Percent: ptr(-50.0)}, results[0].Delta.Commits)
+	assert.Equal(t, "bob", results[1].User)
+	assert.Nil(t, results[1].Delta.Commits.Percent)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// configEnvPrefix prefixes the environment variables that set flags, e.g. GHSTATS_ORG or GHSTATS_LEAD_TIME.
+const configEnvPrefix = "GHSTATS"
+
+// defaultConfigPath returns $XDG_CONFIG_HOME/github-stats.yaml, falling back to ~/.config like the XDG spec does.
+func defaultConfigPath() (string, error) {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "github-stats.yaml"), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".config", "github-stats.yaml"), nil
+}
+
+// loadConfig reads the config file at path and the GHSTATS_* environment variables.
+// With an empty path the default file is read if it exists; an explicitly given file must exist.
+func loadConfig(path string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetEnvPrefix(configEnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+
+	if path == "" {
+		defaultPath, err := defaultConfigPath()
+		if err != nil {
+			return v, nil // Without a home directory there is no default file to read.
+		}
+		if _, err := os.Stat(defaultPath); errors.Is(err, os.ErrNotExist) {
+			return v, nil
+		}
+		path = defaultPath
+	}
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return v, nil
+}
+
+// applyConfig sets every flag of cmd that was not given on the command line from v, so flags take precedence.
+// The keys mirror the flag names, e.g. "org" or "lead-time". Set flags count as changed, as if they were given.
+func applyConfig(cmd *cobra.Command, v *viper.Viper) error {
+	var errs []error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if flag.Changed || flag.Name == "config" || !v.IsSet(flag.Name) {
+			return
+		}
+		values := []string{v.GetString(flag.Name)}
+		if _, ok := flag.Value.(pflag.SliceValue); ok {
+			// YAML lists become several values; a comma-separated string is split by the flag itself.
+			values = v.GetStringSlice(flag.Name)
+		}
+		for _, value := range values {
+			if err := cmd.Flags().Set(flag.Name, value); err != nil {
+				errs = append(errs, fmt.Errorf("invalid config value for %s: %w", flag.Name, err))
+				return
+			}
+		}
+	})
+	return errors.Join(errs...)
+}
+
+// initConfig is the root command's PersistentPreRunE. It applies the config file and environment to the flags.
+func initConfig(cmd *cobra.Command, args []string) error {
+	path, _ := cmd.Flags().GetString("config")
+	v, err := loadConfig(path)
+	if err != nil {
+		return err
+	}
+	return applyConfig(cmd, v)
+}
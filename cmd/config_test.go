@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConfigTestCommand returns a command with a few flags of each kind, parsed from args.
+func newConfigTestCommand(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{Use: "test"}
+	cmd.Flags().String("config", "", "")
+	cmd.Flags().StringSlice("org", nil, "")
+	cmd.Flags().StringArray("include-repo", nil, "")
+	cmd.Flags().String("from", "", "")
+	cmd.Flags().Bool("lead-time", false, "")
+	require.NoError(t, cmd.ParseFlags(args))
+	return cmd
+}
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "github-stats.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestApplyConfig(t *testing.T) {
+	path := writeConfig(t, "org: [acme, widgets]\nfrom: 2025/01/01\nlead-time: true\ninclude-repo:\n  - 'acme/*'\n")
+
+	testCases := []struct {
+		name     string
+		args     []string
+		wantOrgs []string
+		wantFrom string
+	}{
+		{
+			name:     "config values fill absent flags",
+			args:     []string{"--config", path},
+			wantOrgs: []string{"acme", "widgets"},
+			wantFrom: "2025/01/01",
+		},
+		{
+			name:     "flags override the config",
+			args:     []string{"--config", path, "--org", "other", "--from", "2025/06/01"},
+			wantOrgs: []string{"other"},
+			wantFrom: "2025/06/01",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := newConfigTestCommand(t, tc.args...)
+			require.NoError(t, initConfig(cmd, nil))
+
+			orgs, _ := cmd.Flags().GetStringSlice("org")
+			from, _ := cmd.Flags().GetString("from")
+			leadTime, _ := cmd.Flags().GetBool("lead-time")
+			includeRepos, _ := cmd.Flags().GetStringArray("include-repo")
+			assert.Equal(t, tc.wantOrgs, orgs)
+			assert.Equal(t, tc.wantFrom, from)
+			assert.True(t, leadTime)
+			assert.Equal(t, []string{"acme/*"}, includeRepos)
+		})
+	}
+}
+
+func TestApplyConfig_Environment(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir()) // No default config file.
+	t.Setenv("GHSTATS_ORG", "acme,widgets")
+	t.Setenv("GHSTATS_LEAD_TIME", "true")
+
+	cmd := newConfigTestCommand(t)
+	require.NoError(t, initConfig(cmd, nil))
+
+	orgs, _ := cmd.Flags().GetStringSlice("org")
+	leadTime, _ := cmd.Flags().GetBool("lead-time")
+	assert.Equal(t, []string{"acme", "widgets"}, orgs)
+	assert.True(t, leadTime)
+}
+
+func TestApplyConfig_InvalidValue(t *testing.T) {
+	path := writeConfig(t, "lead-time: maybe\n")
+
+	cmd := newConfigTestCommand(t, "--config", path)
+	assert.ErrorContains(t, initConfig(cmd, nil), "invalid config value for lead-time")
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("missing explicit file", func(t *testing.T) {
+		_, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.ErrorContains(t, err, "failed to read config file")
+	})
+	t.Run("missing default file", func(t *testing.T) {
+		t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+		_, err := loadConfig("")
+		assert.NoError(t, err)
+	})
+	t.Run("default file", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("XDG_CONFIG_HOME", dir)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "github-stats.yaml"), []byte("user: alice\n"), 0o600))
+
+		v, err := loadConfig("")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", v.GetString("user"))
+	})
+}
@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/naka-gawa/github-stats/internal/gateway"
+	"github.com/naka-gawa/github-stats/internal/usecase"
+)
+
+// explainUser stands in for the user in the queries --explain prints, since one run may cover several users.
+const explainUser = "<user>"
+
+// metricDefinition explains one output field for --explain.
+type metricDefinition struct {
+	name        string
+	description string
+	// query builds the search query the field is counted from, or is nil when it doesn't come from a search of its own.
+	query func(org, user string, opts usecase.Options) string
+}
+
+// prSearch and commitSearch adapt a gateway query builder to the date range of its search.
+func prSearch(build func(org, user, dateRange string) string) func(string, string, usecase.Options) string {
+	return func(org, user string, opts usecase.Options) string { return build(org, user, opts.PRDateRange) }
+}
+
+// labeledPRSearch is prSearch for the query builders that take the --label filter.
+func labeledPRSearch(build func(org, user, dateRange string, labels []string) string) func(string, string, usecase.Options) string {
+	return func(org, user string, opts usecase.Options) string {
+		return build(org, user, opts.PRDateRange, opts.Labels)
+	}
+}
+
+func commitSearch(org, user string, opts usecase.Options) string {
+	if opts.MergedCommitsOnly {
+		return gateway.MergedPRCommitsQuery(org, user, opts.PRDateRange)
+	}
+	return gateway.CommitsQuery(org, user, opts.CommitDateRange)
+}
+
+// metricDefinitions lists every field of OutputRepoStats, in output order.
+var metricDefinitions = []metricDefinition{
+	{name: "name", description: "The repository as owner/name, or TOTAL for the --totals row."},
+	{name: "user", description: "The user the row belongs to."},
+	{name: "node_id", description: "The GraphQL node ID of the repository."},
+	{name: "primary_language", description: "The primary language GitHub detected for the repository."},
+	{name: "commits", description: "Commits authored by the user; with --merged-commits-only, merged PRs' commits. --commits-source graphql counts the default branch history instead.", query: commitSearch},
+	{name: "created_prs", description: "Pull requests opened by the user.", query: labeledPRSearch(gateway.CreatedPRsQuery)},
+	{name: "draft_prs", description: "Pull requests opened by the user that are still drafts.", query: labeledPRSearch(gateway.CreatedPRsQuery)},
+	{name: "merged_prs", description: "Pull requests opened by the user that have been merged.", query: labeledPRSearch(gateway.MergedPRsQuery)},
+	{name: "merge_rate", description: "merged_prs divided by created_prs."},
+	{name: "reviewed_prs", description: "Pull requests the user reviewed, whatever the review's state.", query: labeledPRSearch(gateway.ReviewedPRsQuery)},
+	{name: "commented_prs", description: "Pull requests the user commented on.", query: prSearch(gateway.CommentedPRsQuery)},
+	{name: "review_comments", description: "Inline comments in the user's reviews.", query: labeledPRSearch(gateway.ReviewedPRsQuery)},
+	{name: "approvals_given", description: "Reviewed pull requests the user approved at least once.", query: labeledPRSearch(gateway.ReviewedPRsQuery)},
+	{name: "assigned_prs", description: "Pull requests assigned to the user, whoever opened them.", query: prSearch(gateway.AssignedPRsQuery)},
+	{name: "open_prs", description: "Pull requests opened by the user that are open now, whatever the date range.", query: func(org, user string, _ usecase.Options) string {
+		return gateway.OpenPRsQuery(org, user)
+	}},
+	{name: "created_issues", description: "Issues opened by the user.", query: prSearch(gateway.CreatedIssuesQuery)},
+	{name: "closed_issues", description: "Closed issues assigned to the user.", query: prSearch(gateway.ClosedIssuesQuery)},
+	{name: "total_additions", description: "Lines added by the user's pull requests.", query: labeledPRSearch(gateway.CreatedPRsQuery)},
+	{name: "total_deletions", description: "Lines deleted by the user's pull requests.", query: labeledPRSearch(gateway.CreatedPRsQuery)},
+	{name: "median_pr_size_lines", description: "The median of additions plus deletions per pull request of the user.", query: labeledPRSearch(gateway.CreatedPRsQuery)},
+	{name: "pr_size_percentiles_lines", description: "Percentiles of additions plus deletions per pull request of the user.", query: labeledPRSearch(gateway.CreatedPRsQuery)},
+	{name: "commit_additions", description: "Lines added by the user's commits, from the weekly contributor statistics of each repository."},
+	{name: "commit_deletions", description: "Lines deleted by the user's commits, from the weekly contributor statistics of each repository."},
+	{name: "analyzed_pr_count", description: "The user's closed pull requests the lead times are computed from.", query: labeledPRSearch(gateway.PRLeadTimesQuery)},
+	{name: "lead_time_percentiles_hours", description: "Time from a PR's creation to its last review.", query: labeledPRSearch(gateway.PRLeadTimesQuery)},
+	{name: "time_to_first_review_percentiles_hours", description: "Time from a PR's creation to its first review.", query: labeledPRSearch(gateway.PRLeadTimesQuery)},
+	{name: "merge_time_percentiles_hours", description: "Time from a PR's creation to its merge.", query: labeledPRSearch(gateway.PRLeadTimesQuery)},
+	{name: "reviewer_latency_percentiles_hours", description: "Time from a PR becoming ready for review to the user's first review of it.", query: labeledPRSearch(gateway.ReviewedPRsQuery)},
+	{name: "author_wait_percentiles_hours", description: "Time from the user opening a PR to its first review by someone else; unreviewed PRs are skipped.", query: labeledPRSearch(gateway.CreatedPRsQuery)},
+	{name: "weighted_lead_time_hours", description: "Lead time statistics in which recent pull requests count more.", query: labeledPRSearch(gateway.PRLeadTimesQuery)},
+	{name: "lead_time_histogram", description: "The user's pull requests per lead time bucket.", query: labeledPRSearch(gateway.PRLeadTimesQuery)},
+	{name: "share", description: "The user's commits and PRs relative to those of all authors, searched per repository with repo:<owner/name>."},
+	{name: "series", description: "The commit and PR counts per time bucket, with the same queries limited to each bucket."},
+	{name: "commit_heatmap", description: "The user's commits per weekday, Sunday first, and hour of their author date in the --timezone.", query: func(org, user string, opts usecase.Options) string {
+		return gateway.CommitsQuery(org, user, opts.CommitDateRange)
+	}},
+}
+
+// writeExplanation describes the fields that appear in results, with the search query each is counted from.
+func writeExplanation(w io.Writer, results []OutputRepoStats, org string, opts usecase.Options) error {
+	present, err := outputFieldNames(results)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(w, "Metrics:")
+	for _, metric := range metricDefinitions {
+		if !present[metric.name] {
+			continue
+		}
+		fmt.Fprintf(w, "  %s: %s\n", metric.name, metric.description)
+		if metric.query != nil {
+			fmt.Fprintf(w, "    query: %s\n", metric.query(org, explainUser, opts))
+		}
+	}
+	return nil
+}
+
+// writeExplanationOrExit is writeExplanation for the stats command, which exits on failure.
+func writeExplanationOrExit(w io.Writer, results []OutputRepoStats, org string, opts usecase.Options) {
+	if err := writeExplanation(w, results, org, opts); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to write explanation: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// outputFieldNames returns the JSON field names present in any of the results.
+// Duration fields renamed by --lead-time-unit are reported under their "_hours" name.
+func outputFieldNames(results []OutputRepoStats) (map[string]bool, error) {
+	present := make(map[string]bool)
+	for _, result := range results {
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s to JSON: %w", result.Name, err)
+		}
+		var fields map[string]json.RawMessage
+		if err := json.Unmarshal(data, &fields); err != nil {
+			return nil, err
+		}
+		for name := range fields {
+			if unit := unitOrDefault(result.unit); unit != unitHours {
+				if trimmed, ok := strings.CutSuffix(name, "_"+unit); ok {
+					name = trimmed + "_" + unitHours
+				}
+			}
+			present[name] = true
+		}
+	}
+	return present, nil
+}
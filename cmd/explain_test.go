@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricDefinitionsCoverOutput(t *testing.T) {
+	defined := make(map[string]bool)
+	for _, metric := range metricDefinitions {
+		assert.False(t, defined[metric.name], "%s is defined twice", metric.name)
+		defined[metric.name] = true
+		assert.NotEmpty(t, metric.description, metric.name)
+	}
+	fields, _ := jsonFields(OutputRepoStats{})
+	for _, field := range fields {
+		assert.True(t, defined[field], "output field %s has no explanation in metricDefinitions", field)
+	}
+}
+
+func TestWriteExplanation(t *testing.T) {
+	merged := 1
+	results := []OutputRepoStats{
+		{Name: "org/repo", User: "alice", Commits: 2, ReviewedPRs: 1, MergedPRs: &merged, unit: unitDays,
+			LeadTimePercentiles: LeadTimePercentiles{"p50": 1}},
+	}
+	opts := usecase.Options{CommitDateRange: " author-date:2025-01-01..2025-01-31", PRDateRange: " created:2025-01-01..2025-01-31"}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeExplanation(&buf, results, "org", opts))
+	out := buf.String()
+	assert.Contains(t, out, "  commits: ")
+	assert.Contains(t, out, "    query: org:org author:<user> author-date:2025-01-01..2025-01-31\n")
+	assert.Contains(t, out, "  reviewed_prs: ")
+	assert.Contains(t, out, "    query: org:org reviewed-by:<user> is:pr created:2025-01-01..2025-01-31\n")
+	assert.Contains(t, out, "    query: org:org author:<user> is:pr is:merged created:2025-01-01..2025-01-31\n")
+	// Renamed duration fields are explained under their "_hours" name.
+	assert.Contains(t, out, "  lead_time_percentiles_hours: ")
+	// Fields left out of the output aren't explained.
+	assert.NotContains(t, out, "approvals_given")
+
+	buf.Reset()
+	opts.MergedCommitsOnly = true
+	require.NoError(t, writeExplanation(&buf, results[:1], "org", opts))
+	assert.NotContains(t, buf.String(), "author-date:")
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"sigs.k8s.io/yaml"
+)
+
+// Supported values for the --format flag.
+const (
+	formatJSON       = "json"
+	formatScatterCSV = "scatter-csv"
+	formatMarkdown   = "markdown"
+	formatYAML       = "yaml"
+	formatNDJSON     = "ndjson"
+	formatPrometheus = "prometheus"
+	formatHTML       = "html"
+	formatTable      = "table"
+)
+
+// supportedFormats lists the valid --format values in the order they are shown to users.
+var supportedFormats = []string{formatJSON, formatNDJSON, formatYAML, formatTable, formatMarkdown, formatHTML, formatPrometheus, formatScatterCSV}
+
+// validateFormat returns an error when format is not one of the supported output formats.
+func validateFormat(format string) error {
+	for _, supported := range supportedFormats {
+		if format == supported {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported format %q (valid: %s)", format, strings.Join(supportedFormats, ", "))
+}
+
+// writeResults renders the results in the given format.
+// Formats built from individual pull requests use domainResults; the others use outputResults.
+func writeResults(w io.Writer, format string, domainResults []*domain.RepoStats, outputResults []OutputRepoStats, opts outputOptions) error {
+	switch format {
+	case formatScatterCSV:
+		return writeScatterCSV(w, domainResults, opts.unit)
+	case formatNDJSON:
+		return writeNDJSON(w, outputResults)
+	case formatYAML:
+		return writeYAML(w, outputResults)
+	case formatPrometheus:
+		return writePrometheus(w, outputResults, opts)
+	case formatMarkdown:
+		return writeMarkdown(w, outputResults, opts.calculateLeadTime, opts.unit)
+	case formatTable:
+		return writeTable(w, outputResults, opts.calculateLeadTime, opts.unit, opts.maxNameWidth, opts.color)
+	case formatHTML:
+		return writeHTML(w, outputResults, opts.calculateLeadTime, opts.unit)
+	default:
+		if opts.metadata != nil || opts.summary != nil {
+			return writeJSONDocument(w, outputResults, opts.metadata, opts.summary, opts.compact)
+		}
+		return writeJSON(w, outputResults, opts.compact)
+	}
+}
+
+// marshalJSON marshals v pretty-printed, or on a single line when compact is set.
+func marshalJSON(v any, compact bool) ([]byte, error) {
+	if compact {
+		return json.Marshal(v)
+	}
+	return json.MarshalIndent(v, "", "  ")
+}
+
+// writeJSON writes the results as a JSON array, pretty-printed unless compact is set.
+func writeJSON(w io.Writer, results []OutputRepoStats, compact bool) error {
+	jsonData, err := marshalJSON(results, compact)
+	if err != nil {
+		return fmt.Errorf("failed to marshal results to JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(jsonData))
+	return err
+}
+
+// writeNDJSON writes one compact JSON object per result and line, so the output can be processed incrementally.
+func writeNDJSON(w io.Writer, results []OutputRepoStats) error {
+	enc := json.NewEncoder(w)
+	for _, result := range results {
+		if err := enc.Encode(result); err != nil {
+			return fmt.Errorf("failed to write %s as JSON: %w", result.Name, err)
+		}
+	}
+	return nil
+}
+
+// writeYAML writes the results as a YAML list.
+// The keys are the JSON tags, and the keys of each mapping are sorted so the output diffs cleanly.
+func writeYAML(w io.Writer, results []OutputRepoStats) error {
+	yamlData, err := yaml.Marshal(results)
+	if err != nil {
+		return fmt.Errorf("failed to marshal results to YAML: %w", err)
+	}
+	_, err = w.Write(yamlData)
+	return err
+}
+
+// writeMarkdown writes the results as a GitHub-flavored Markdown table with right-aligned numbers.
+// The P50/P90 columns are only added when lead time was calculated; repositories without analyzed PRs,
+// or percentiles left out of --percentiles, show "-". Lead times are shown in unit (hours when empty).
+func writeMarkdown(w io.Writer, results []OutputRepoStats, withLeadTime bool, unit string) error {
+	unit = unitOrDefault(unit)
+	withUser := hasMultipleUsers(results)
+	header := "| Repository | Commits | Created PRs | Reviewed PRs |"
+	align := "|:---|---:|---:|---:|"
+	if withUser {
+		header = "| User " + header
+		align = "|:---" + align
+	}
+	if withLeadTime {
+		abbr := unitAbbreviations[unit]
+		header += fmt.Sprintf(" Lead Time P50 (%s) | Lead Time P90 (%s) |", abbr, abbr)
+		align += "---:|---:|"
+	}
+	var b strings.Builder
+	b.WriteString(header + "\n" + align + "\n")
+	for _, result := range results {
+		if withUser {
+			fmt.Fprintf(&b, "| %s ", result.User)
+		}
+		fmt.Fprintf(&b, "| %s | %d | %d | %d |", result.Name, result.Commits, result.CreatedPRs, result.ReviewedPRs)
+		if withLeadTime {
+			p50, p90 := "-", "-"
+			if v, ok := result.LeadTimePercentiles[percentileKey(50, unit)]; ok {
+				p50 = strconv.FormatFloat(v, 'f', 2, 64)
+			}
+			if v, ok := result.LeadTimePercentiles[percentileKey(90, unit)]; ok {
+				p90 = strconv.FormatFloat(v, 'f', 2, 64)
+			}
+			fmt.Fprintf(&b, " %s | %s |", p50, p90)
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeScatterCSV writes one row per analyzed pull request, ready to be plotted as lead time vs. PR size.
+// Lead times are in unit, which also names their column.
+func writeScatterCSV(w io.Writer, results []*domain.RepoStats, unit string) error {
+	unit = unitOrDefault(unit)
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"repo", "number", "created_at", "lead_time_" + unit, "additions", "deletions"}); err != nil {
+		return err
+	}
+	for _, repoStat := range results {
+		for _, pr := range repoStat.PullRequests {
+			record := []string{
+				repoStat.Name,
+				strconv.Itoa(pr.Number),
+				pr.CreatedAt.UTC().Format(time.RFC3339),
+				strconv.FormatFloat(pr.LeadTimeSeconds/unitSecondsPerUnit[unit], 'f', -1, 64),
+				strconv.Itoa(pr.Additions),
+				strconv.Itoa(pr.Deletions),
+			}
+			if err := cw.Write(record); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// hasMultipleUsers reports whether the results belong to more than one user,
+// in which case tabular formats need a user column to tell the rows apart.
+func hasMultipleUsers(results []OutputRepoStats) bool {
+	for _, result := range results {
+		if result.User != "" && result.User != results[0].User {
+			return true
+		}
+	}
+	return false
+}
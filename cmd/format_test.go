@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestWriteScatterCSV(t *testing.T) {
+	createdAt := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	results := []*domain.RepoStats{
+		{
+			Name: "org/repo-a",
+			PullRequests: []domain.PRLeadTime{
+				{Number: 1, CreatedAt: createdAt, LeadTimeSeconds: 5400, Additions: 120, Deletions: 30},
+				{Number: 2, CreatedAt: createdAt.Add(24 * time.Hour), LeadTimeSeconds: 7200, Additions: 3, Deletions: 0},
+			},
+		},
+		{Name: "org/repo-without-prs"},
+	}
+
+	testCases := []struct {
+		name     string
+		unit     string
+		expected string
+	}{
+		{
+			name: "default unit",
+			expected: "repo,number,created_at,lead_time_hours,additions,deletions\n" +
+				"org/repo-a,1,2025-03-01T09:30:00Z,1.5,120,30\n" +
+				"org/repo-a,2,2025-03-02T09:30:00Z,2,3,0\n",
+		},
+		{
+			name: "minutes",
+			unit: unitMinutes,
+			expected: "repo,number,created_at,lead_time_minutes,additions,deletions\n" +
+				"org/repo-a,1,2025-03-01T09:30:00Z,90,120,30\n" +
+				"org/repo-a,2,2025-03-02T09:30:00Z,120,3,0\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeScatterCSV(&buf, results, tc.unit))
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestWriteMarkdown(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", Commits: 12, CreatedPRs: 3, ReviewedPRs: 8, LeadTimePercentiles: LeadTimePercentiles{"p50_hours": 1.5, "p90_hours": 26.25}},
+		{Name: "org/repo-b", Commits: 1},
+	}
+	testCases := []struct {
+		name         string
+		withLeadTime bool
+		golden       string
+	}{
+		{name: "with lead time", withLeadTime: true, golden: "markdown_lead_time.md"},
+		{name: "without lead time", withLeadTime: false, golden: "markdown.md"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeMarkdown(&buf, results, tc.withLeadTime, ""))
+
+			expected, err := os.ReadFile(filepath.Join("testdata", tc.golden))
+			require.NoError(t, err)
+			assert.Equal(t, string(expected), buf.String())
+		})
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", User: "alice", Commits: 12, CreatedPRs: 3, ReviewedPRs: 8, LeadTimePercentiles: LeadTimePercentiles{"p50_hours": 1.5, "p90_hours": 26.25}},
+		{Name: "org/<script>alert(1)</script>", User: "bob", Commits: 1},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, writeHTML(&buf, results, true, ""))
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "report.html"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+	assert.NotContains(t, buf.String(), "<script>", "repository names must be escaped")
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range supportedFormats {
+		assert.NoError(t, validateFormat(format))
+	}
+	assert.ErrorContains(t, validateFormat("xml"), `unsupported format "xml"`)
+}
+
+func TestWriteMarkdown_MultipleUsers(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", User: "alice", Commits: 1},
+		{Name: "org/repo-a", User: "bob", Commits: 2},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeMarkdown(&buf, results, false, ""))
+
+	expected := "| User | Repository | Commits | Created PRs | Reviewed PRs |\n" +
+		"|:---|:---|---:|---:|---:|\n" +
+		"| alice | org/repo-a | 1 | 0 | 0 |\n" +
+		"| bob | org/repo-a | 2 | 0 | 0 |\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteYAML_RoundTrip(t *testing.T) {
+	commented := 2
+	results := []OutputRepoStats{
+		{
+			Name:                "org/repo-a",
+			Commits:             12,
+			CreatedPRs:          3,
+			CommentedPRs:        &commented,
+			AnalyzedPRCount:     2,
+			LeadTimePercentiles: LeadTimePercentiles{"p50_hours": 1.5, "p90_hours": 26.25},
+			Share:               &ShareStats{OrgCommits: 24, CommitsPercent: 50},
+		},
+		{Name: "org/repo-b", ReviewedPRs: 1},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, formatYAML, nil, results, outputOptions{}))
+	assert.Contains(t, buf.String(), "lead_time_percentiles_hours:\n")
+
+	var got []OutputRepoStats
+	require.NoError(t, yaml.Unmarshal(buf.Bytes(), &got))
+	assert.Equal(t, results, got)
+}
+
+func TestWriteNDJSON(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", Commits: 12, LeadTimePercentiles: LeadTimePercentiles{"p50_hours": 1.5}},
+		{Name: "org/repo-b", User: "alice", ReviewedPRs: 1},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, formatNDJSON, nil, results, outputOptions{}))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, len(results))
+	for i, line := range lines {
+		var got OutputRepoStats
+		require.NoError(t, json.Unmarshal([]byte(line), &got))
+		assert.Equal(t, results[i], got)
+	}
+}
+
+func TestWriteResults_Compact(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", Commits: 12},
+		{Name: "org/repo-b", ReviewedPRs: 1},
+	}
+	metadata := newOutputMetadata(time.Time{}, time.Time{}, []string{"org"}, []string{"alice"}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC))
+	testCases := []struct {
+		name string
+		opts outputOptions
+	}{
+		{name: "array", opts: outputOptions{compact: true}},
+		{name: "with metadata", opts: outputOptions{compact: true, metadata: metadata}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			require.NoError(t, writeResults(&buf, formatJSON, nil, results, tc.opts))
+
+			out := buf.String()
+			// A single line, ending in the newline after the document.
+			assert.Equal(t, 1, strings.Count(out, "\n"))
+			assert.True(t, strings.HasSuffix(out, "\n"))
+			assert.True(t, json.Valid([]byte(out)))
+			assert.Contains(t, out, `{"name":"org/repo-a","commits":12,`)
+		})
+	}
+
+	// Other formats ignore it.
+	var pretty, compact bytes.Buffer
+	require.NoError(t, writeResults(&pretty, formatYAML, nil, results, outputOptions{}))
+	require.NoError(t, writeResults(&compact, formatYAML, nil, results, outputOptions{compact: true}))
+	assert.Equal(t, pretty.String(), compact.String())
+}
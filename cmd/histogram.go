@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultHistogramBuckets are the bucket edges in hours used when --histogram-buckets is not given.
+var defaultHistogramBuckets = []string{"1", "4", "24"}
+
+// LeadTimeHistogram maps a bucket label such as "1-4h" to the number of PRs whose lead time falls into it.
+type LeadTimeHistogram map[string]int
+
+// parseHistogramBuckets parses the values of --histogram-buckets, which must be positive and strictly increasing hours.
+func parseHistogramBuckets(values []string) ([]float64, error) {
+	edges := make([]float64, 0, len(values))
+	for _, value := range values {
+		edge, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || edge <= 0 {
+			return nil, fmt.Errorf("invalid histogram bucket edge %q: must be a positive number of hours", value)
+		}
+		if len(edges) > 0 && edge <= edges[len(edges)-1] {
+			return nil, fmt.Errorf("--histogram-buckets must be in increasing order, but %q follows %s", value, formatHours(edges[len(edges)-1]))
+		}
+		edges = append(edges, edge)
+	}
+	if len(edges) == 0 {
+		return nil, fmt.Errorf("--histogram-buckets must not be empty")
+	}
+	return edges, nil
+}
+
+// histogramLabels returns the label of every bucket the edges define, from "0-<first>h" up to "<last>h+".
+func histogramLabels(edges []float64) []string {
+	labels := make([]string, 0, len(edges)+1)
+	lower := 0.0
+	for _, edge := range edges {
+		labels = append(labels, formatHours(lower)+"-"+formatHours(edge)+"h")
+		lower = edge
+	}
+	return append(labels, formatHours(lower)+"h+")
+}
+
+// calculateLeadTimeHistogram counts the lead times in seconds per bucket. Each bucket includes its lower edge
+// and excludes its upper one, so a PR reviewed in exactly 4 hours counts in "4-24h", not "1-4h".
+// Every bucket is present, with zero if no PR falls into it, and nil is returned for no lead times.
+func calculateLeadTimeHistogram(seconds []float64, edges []float64) LeadTimeHistogram {
+	if len(seconds) == 0 {
+		return nil
+	}
+	labels := histogramLabels(edges)
+	histogram := make(LeadTimeHistogram, len(labels))
+	for _, label := range labels {
+		histogram[label] = 0
+	}
+	for _, s := range seconds {
+		hours := s / 3600
+		bucket := len(edges)
+		for i, edge := range edges {
+			if hours < edge {
+				bucket = i
+				break
+			}
+		}
+		histogram[labels[bucket]]++
+	}
+	return histogram
+}
+
+// formatHours formats an edge without trailing zeros, e.g. 0.5 or 24.
+func formatHours(hours float64) string {
+	return strconv.FormatFloat(hours, 'f', -1, 64)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCalculateLeadTimeHistogram_Edges(t *testing.T) {
+	edges := []float64{1, 4, 24}
+	seconds := []float64{
+		0,           // 0h
+		3599,        // just under 1h
+		3600,        // exactly 1h
+		4 * 3600,    // exactly 4h
+		24*3600 - 1, // just under 24h
+		24 * 3600,   // exactly 24h
+		90 * 3600,   // 90h
+	}
+
+	assert.Equal(t, LeadTimeHistogram{"0-1h": 2, "1-4h": 1, "4-24h": 2, "24h+": 2}, calculateLeadTimeHistogram(seconds, edges))
+	assert.Nil(t, calculateLeadTimeHistogram(nil, edges))
+}
+
+func TestCalculateLeadTimeHistogram_EmptyBuckets(t *testing.T) {
+	histogram := calculateLeadTimeHistogram([]float64{1800}, []float64{0.5, 8})
+	assert.Equal(t, LeadTimeHistogram{"0-0.5h": 0, "0.5-8h": 1, "8h+": 0}, histogram)
+}
+
+func TestParseHistogramBuckets(t *testing.T) {
+	edges, err := parseHistogramBuckets([]string{"0.5", " 2", "48"})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{0.5, 2, 48}, edges)
+
+	for _, invalid := range [][]string{{"0"}, {"-1"}, {"1h"}, {"4", "1"}, {"4", "4"}, {}} {
+		_, err := parseHistogramBuckets(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestBuildOutputResults_Histogram(t *testing.T) {
+	results := buildOutputResults([]*domain.RepoStats{{
+		Name:                        "org/repo-a",
+		LeadTimeToLastReviewSeconds: []float64{1800, 7200},
+	}}, outputOptions{calculateLeadTime: true, percentiles: []float64{50}, histogramBuckets: []float64{1, 4, 24}})
+
+	assert.Equal(t, LeadTimeHistogram{"0-1h": 1, "1-4h": 1, "4-24h": 0, "24h+": 0}, results[0].LeadTimeHistogram)
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+	"strconv"
+)
+
+// reportTemplate is the page written by --format html. It inlines its styles so the file works on its own.
+//
+//go:embed report.html.tmpl
+var reportTemplate string
+
+var reportHTML = template.Must(template.New("report").Parse(reportTemplate))
+
+// htmlReport is the data reportTemplate renders.
+type htmlReport struct {
+	WithUser     bool
+	WithLeadTime bool
+	Unit         string
+	Rows         []htmlRow
+}
+
+// htmlRow is one repository of the report. P50 and P90 are formatted like the Markdown table, "-" when missing,
+// and the widths size their bars relative to the largest P90 of the report; empty widths draw no bar.
+type htmlRow struct {
+	User        string
+	Name        string
+	Commits     int
+	CreatedPRs  int
+	ReviewedPRs int
+	P50, P90    string
+	P50Width    string
+	P90Width    string
+}
+
+// writeHTML writes the results as a self-contained HTML page with one table row per repository.
+// html/template escapes the repository and user names. The lead time columns and bars are only added
+// when lead time was calculated, in unit (hours when empty).
+func writeHTML(w io.Writer, results []OutputRepoStats, withLeadTime bool, unit string) error {
+	unit = unitOrDefault(unit)
+	report := htmlReport{
+		WithUser:     hasMultipleUsers(results),
+		WithLeadTime: withLeadTime,
+		Unit:         unitAbbreviations[unit],
+		Rows:         make([]htmlRow, 0, len(results)),
+	}
+	var longest float64
+	for _, result := range results {
+		longest = max(longest, result.LeadTimePercentiles[percentileKey(90, unit)], result.LeadTimePercentiles[percentileKey(50, unit)])
+	}
+	for _, result := range results {
+		row := htmlRow{
+			User:        result.User,
+			Name:        result.Name,
+			Commits:     result.Commits,
+			CreatedPRs:  result.CreatedPRs,
+			ReviewedPRs: result.ReviewedPRs,
+		}
+		if withLeadTime {
+			row.P50, row.P50Width = htmlLeadTime(result.LeadTimePercentiles, percentileKey(50, unit), longest)
+			row.P90, row.P90Width = htmlLeadTime(result.LeadTimePercentiles, percentileKey(90, unit), longest)
+		}
+		report.Rows = append(report.Rows, row)
+	}
+	return reportHTML.Execute(w, report)
+}
+
+// htmlLeadTime formats the percentile under key and the width of its bar as a percentage of longest.
+func htmlLeadTime(percentiles LeadTimePercentiles, key string, longest float64) (value, width string) {
+	v, ok := percentiles[key]
+	if !ok {
+		return "-", ""
+	}
+	value = strconv.FormatFloat(v, 'f', 2, 64)
+	if longest > 0 {
+		width = strconv.FormatFloat(v/longest*100, 'f', 1, 64)
+	}
+	return value, width
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/gateway"
+)
+
+// outputMetadata describes what a --with-metadata report covers.
+type outputMetadata struct {
+	// From and To are the resolved first and last day of the queried range, or null when that side is open.
+	From        *string   `json:"from"`
+	To          *string   `json:"to"`
+	Org         string    `json:"org"`
+	User        string    `json:"user"`
+	GeneratedAt time.Time `json:"generated_at"`
+	// Retries are the retries of transient failures per fetch phase, left out when nothing was retried.
+	Retries map[string]outputPhaseRetries `json:"retries,omitempty"`
+}
+
+// outputPhaseRetries are the retries of one fetch phase in the --with-metadata block.
+type outputPhaseRetries struct {
+	Count int `json:"count"`
+	// Exhausted is true when a request in the phase still failed after its last retry.
+	Exhausted bool `json:"exhausted"`
+}
+
+// newOutputRetries converts the gateway's retries per phase, returning nil when there were none.
+func newOutputRetries(phases map[string]gateway.PhaseRetries) map[string]outputPhaseRetries {
+	if len(phases) == 0 {
+		return nil
+	}
+	retries := make(map[string]outputPhaseRetries, len(phases))
+	for phase, r := range phases {
+		retries[phase] = outputPhaseRetries{Count: r.Retries, Exhausted: r.Exhausted}
+	}
+	return retries
+}
+
+// outputDocument is the JSON document written with --with-metadata or --with-summary instead of the bare results array.
+type outputDocument struct {
+	Metadata *outputMetadata   `json:"metadata,omitempty"`
+	Summary  *outputSummary    `json:"summary,omitempty"`
+	Results  []OutputRepoStats `json:"results"`
+}
+
+// newOutputMetadata records the resolved date range, the comma-joined orgs and users, and when the report was made.
+// The dates are the days the searches cover, in the --timezone the range was resolved in.
+func newOutputMetadata(from, to time.Time, orgs, users []string, now time.Time) *outputMetadata {
+	day := func(t time.Time) *string {
+		if t.IsZero() {
+			return nil
+		}
+		s := t.Format("2006-01-02")
+		return &s
+	}
+	return &outputMetadata{
+		From:        day(from),
+		To:          day(to),
+		Org:         strings.Join(orgs, ","),
+		User:        strings.Join(users, ","),
+		GeneratedAt: now.UTC().Truncate(time.Second),
+	}
+}
+
+// writeJSONDocument writes the results under "results" next to the metadata and summary, either of which
+// may be nil, as a JSON object, pretty-printed unless compact is set.
+func writeJSONDocument(w io.Writer, results []OutputRepoStats, metadata *outputMetadata, summary *outputSummary, compact bool) error {
+	if results == nil {
+		results = []OutputRepoStats{}
+	}
+	jsonData, err := marshalJSON(outputDocument{Metadata: metadata, Summary: summary, Results: results}, compact)
+	if err != nil {
+		return fmt.Errorf("failed to marshal results to JSON: %w", err)
+	}
+	_, err = fmt.Fprintln(w, string(jsonData))
+	return err
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/gateway"
+	"github.com/naka-gawa/github-stats/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewOutputMetadata(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	now := time.Date(2024, 5, 16, 1, 30, 0, 0, tokyo)
+
+	from, to, err := parsePeriod("last-month", now)
+	require.NoError(t, err)
+	metadata := newOutputMetadata(from, to, []string{"org-a", "org-b"}, []string{"alice"}, now)
+	require.NotNil(t, metadata.From)
+	require.NotNil(t, metadata.To)
+	assert.Equal(t, "2024-04-01", *metadata.From)
+	assert.Equal(t, "2024-04-30", *metadata.To, "the end of the range is inclusive")
+	assert.Equal(t, "org-a,org-b", metadata.Org)
+	assert.Equal(t, "alice", metadata.User)
+	assert.Equal(t, time.Date(2024, 5, 15, 16, 30, 0, 0, time.UTC), metadata.GeneratedAt)
+
+	from, err = parseLast("7d", now)
+	require.NoError(t, err)
+	metadata = newOutputMetadata(from, time.Time{}, []string{"org-a"}, []string{"alice"}, now)
+	assert.Equal(t, "2024-05-09", *metadata.From)
+	assert.Nil(t, metadata.To, "an open end stays null")
+}
+
+func TestWriteResults_WithMetadata(t *testing.T) {
+	metadata := newOutputMetadata(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 4, 30, 0, 0, 0, 0, time.UTC),
+		[]string{"org"}, []string{"alice"}, time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC))
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, formatJSON, nil, []OutputRepoStats{{Name: "org/repo-a", Commits: 2}}, outputOptions{metadata: metadata}))
+
+	assert.JSONEq(t, `{
+		"metadata": {"from": "2024-04-01", "to": "2024-04-30", "org": "org", "user": "alice", "generated_at": "2024-05-01T09:00:00Z"},
+		"results": [{"name": "org/repo-a", "commits": 2, "created_prs": 0, "reviewed_prs": 0}]
+	}`, buf.String())
+	assert.True(t, json.Valid(buf.Bytes()))
+
+	metadata.Retries = newOutputRetries(map[string]gateway.PhaseRetries{
+		"created PRs": {Retries: 2},
+		"lead times":  {Retries: 1, Exhausted: true},
+	})
+	buf.Reset()
+	require.NoError(t, writeResults(&buf, formatJSON, nil, nil, outputOptions{metadata: metadata}))
+	assert.JSONEq(t, `{
+		"metadata": {
+			"from": "2024-04-01", "to": "2024-04-30", "org": "org", "user": "alice", "generated_at": "2024-05-01T09:00:00Z",
+			"retries": {"created PRs": {"count": 2, "exhausted": false}, "lead times": {"count": 1, "exhausted": true}}
+		},
+		"results": []
+	}`, buf.String())
+}
+
+func TestWriteResults_WithSummary(t *testing.T) {
+	summary := newOutputSummary(usecase.Summary{
+		ReposTouched:       2,
+		Commits:            5,
+		CreatedPRs:         3,
+		ReviewedPRs:        1,
+		AnalyzedPRs:        3,
+		LeadTimeP50Seconds: 7200,
+		LeadTimeP90Seconds: 86400,
+		MostActiveRepo:     "org/repo-a",
+	}, unitDays)
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, formatJSON, nil, []OutputRepoStats{{Name: "org/repo-a", Commits: 5}}, outputOptions{summary: summary}))
+
+	assert.JSONEq(t, `{
+		"summary": {
+			"repos_touched": 2, "commits": 5, "created_prs": 3, "reviewed_prs": 1, "analyzed_prs": 3,
+			"lead_time_percentiles": {"p50_days": 0.08333333333333333, "p90_days": 1},
+			"most_active_repo": "org/repo-a"
+		},
+		"results": [{"name": "org/repo-a", "commits": 5, "created_prs": 0, "reviewed_prs": 0}]
+	}`, buf.String())
+}
+
+func TestNewOutputSummary_NoLeadTimes(t *testing.T) {
+	summary := newOutputSummary(usecase.Summary{ReposTouched: 1, Commits: 2}, "")
+	assert.Nil(t, summary.LeadTimePercentiles, "the percentiles are omitted when no PR was analyzed")
+}
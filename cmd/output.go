@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// nopWriteCloser keeps stdout open when the output is closed.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error { return nil }
+
+// openOutput returns the destination for the results: stdout when path is empty or "-",
+// otherwise the file at path, truncated and with its parent directories created.
+func openOutput(path string) (io.WriteCloser, error) {
+	if path == "" || path == "-" {
+		return nopWriteCloser{os.Stdout}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %w", path, err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	return f, nil
+}
+
+// writeOutputDir writes each repository's stats to its own JSON file in dir, creating dir if needed.
+// When the results cover several users, the file names are prefixed with "<user>__".
+func writeOutputDir(dir string, results []OutputRepoStats) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	withUser := hasMultipleUsers(results)
+	used := make(map[string]bool, len(results))
+	for _, result := range results {
+		name := result.Name
+		if withUser && result.User != "" {
+			name = result.User + "/" + name
+		}
+		fileName := uniqueFileName(repoFileName(name), used)
+		jsonData, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s to JSON: %w", result.Name, err)
+		}
+		path := filepath.Join(dir, fileName)
+		if err := os.WriteFile(path, append(jsonData, '\n'), 0o644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// repoFileName turns "owner/repo" into "owner__repo.json".
+// Any other character that is unsafe in a file name is replaced with an underscore.
+func repoFileName(name string) string {
+	name = strings.ReplaceAll(name, "/", "__")
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+	return name + ".json"
+}
+
+// uniqueFileName returns fileName, or fileName with a numeric suffix if it was already used, and marks the result as used.
+func uniqueFileName(fileName string, used map[string]bool) string {
+	candidate := fileName
+	base := strings.TrimSuffix(fileName, ".json")
+	for i := 2; used[strings.ToLower(candidate)]; i++ {
+		candidate = fmt.Sprintf("%s-%d.json", base, i)
+	}
+	// Compare case-insensitively so names differing only in case don't overwrite each other on macOS or Windows.
+	used[strings.ToLower(candidate)] = true
+	return candidate
+}
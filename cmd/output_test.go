@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteOutputDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "out")
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", Commits: 3},
+		{Name: "org/Repo-A", Commits: 1},
+		{Name: "org/repo.b", CreatedPRs: 2},
+	}
+
+	require.NoError(t, writeOutputDir(dir, results))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"org__repo-a.json", "org__Repo-A-2.json", "org__repo.b.json"}, names)
+
+	data, err := os.ReadFile(filepath.Join(dir, "org__repo-a.json"))
+	require.NoError(t, err)
+	var got OutputRepoStats
+	require.NoError(t, json.Unmarshal(data, &got))
+	assert.Equal(t, results[0], got)
+}
+
+func TestRepoFileName(t *testing.T) {
+	assert.Equal(t, "owner__repo.json", repoFileName("owner/repo"))
+	assert.Equal(t, "owner__re_po.json", repoFileName("owner/re po"))
+}
+
+func TestOpenOutput_MatchesStdout(t *testing.T) {
+	results := []OutputRepoStats{{Name: "org/repo-a", Commits: 3, CreatedPRs: 1}}
+
+	var stdout bytes.Buffer
+	require.NoError(t, writeResults(&stdout, formatJSON, nil, results, outputOptions{}))
+
+	path := filepath.Join(t.TempDir(), "reports", "stats.json")
+	// Pre-existing content must be truncated.
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, bytes.Repeat([]byte("x"), 4096), 0o644))
+
+	out, err := openOutput(path)
+	require.NoError(t, err)
+	require.NoError(t, writeResults(out, formatJSON, nil, results, outputOptions{}))
+	require.NoError(t, out.Close())
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, stdout.String(), string(data))
+}
+
+func TestOpenOutput_Stdout(t *testing.T) {
+	for _, path := range []string{"", "-"} {
+		out, err := openOutput(path)
+		require.NoError(t, err)
+		assert.Equal(t, nopWriteCloser{os.Stdout}, out)
+		assert.NoError(t, out.Close())
+	}
+}
+
+func TestOpenOutput_Error(t *testing.T) {
+	// A regular file cannot be used as a parent directory.
+	parent := filepath.Join(t.TempDir(), "file")
+	require.NoError(t, os.WriteFile(parent, nil, 0o644))
+
+	_, err := openOutput(filepath.Join(parent, "stats.json"))
+	assert.Error(t, err)
+}
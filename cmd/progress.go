@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// spinnerFrames are plain ASCII so they render in every terminal.
+var spinnerFrames = []string{"|", "/", "-", `\`}
+
+// spinner shows the current fetch step on a single, continuously redrawn terminal line.
+// It implements gateway.Progress.
+type spinner struct {
+	w        io.Writer
+	interval time.Duration
+
+	mu          sync.Mutex
+	description string
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+func newSpinner(w io.Writer, interval time.Duration) *spinner {
+	return &spinner{w: w, interval: interval, description: "Starting..."}
+}
+
+// Step sets the description shown next to the spinner.
+func (s *spinner) Step(description string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.description = description
+}
+
+// Start begins redrawing the spinner until Stop is called.
+func (s *spinner) Start() {
+	s.stop = make(chan struct{})
+	s.stopped = make(chan struct{})
+	go func() {
+		defer close(s.stopped)
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			s.mu.Lock()
+			// \r returns to the start of the line and \033[K clears the rest of the previous description.
+			fmt.Fprintf(s.w, "\r%s %s\033[K", spinnerFrames[frame%len(spinnerFrames)], s.description)
+			s.mu.Unlock()
+			select {
+			case <-s.stop:
+				fmt.Fprint(s.w, "\r\033[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Stop stops the spinner and clears its line, so later output starts on a clean line.
+func (s *spinner) Stop() {
+	if s.stop == nil {
+		return
+	}
+	close(s.stop)
+	<-s.stopped
+	s.stop = nil
+}
+
+// isTerminal reports whether f is attached to a terminal rather than a pipe or file.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpinner(t *testing.T) {
+	var buf bytes.Buffer
+	s := newSpinner(&buf, time.Millisecond)
+	s.Step("Fetching commits...")
+	s.Start()
+	time.Sleep(20 * time.Millisecond)
+	s.Stop()
+	// Stopping twice is harmless.
+	s.Stop()
+
+	out := buf.String()
+	assert.Contains(t, out, "| Fetching commits...")
+	assert.True(t, strings.HasSuffix(out, "\r\033[K"), "the spinner line must be cleared on stop")
+}
+
+func TestIsTerminal(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "out"))
+	require.NoError(t, err)
+	defer f.Close()
+	assert.False(t, isTerminal(f))
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// prometheusCounter describes a per-repository count exposed as a gauge.
+type prometheusCounter struct {
+	name  string
+	help  string
+	value func(OutputRepoStats) int
+}
+
+var prometheusCounters = []prometheusCounter{
+	{name: "github_stats_commits", help: "Commits authored by the user.", value: func(r OutputRepoStats) int { return r.Commits }},
+	{name: "github_stats_created_prs", help: "Pull requests created by the user.", value: func(r OutputRepoStats) int { return r.CreatedPRs }},
+	{name: "github_stats_reviewed_prs", help: "Pull requests reviewed by the user.", value: func(r OutputRepoStats) int { return r.ReviewedPRs }},
+}
+
+// writePrometheus writes the results in the Prometheus text exposition format for the node_exporter textfile collector.
+// Lead time percentiles become one gauge family in the configured unit, with the percentile as a quantile label.
+func writePrometheus(w io.Writer, results []OutputRepoStats, opts outputOptions) error {
+	var b strings.Builder
+	for _, counter := range prometheusCounters {
+		writePrometheusHeader(&b, counter.name, counter.help)
+		for _, result := range results {
+			fmt.Fprintf(&b, "%s{%s} %d\n", counter.name, prometheusLabels(result), counter.value(result))
+		}
+	}
+
+	if opts.calculateLeadTime {
+		unit := unitOrDefault(opts.unit)
+		name := "github_stats_lead_time_" + unit
+		writePrometheusHeader(&b, name, "Lead time from PR creation to the last review, in "+unit+".")
+		for _, result := range results {
+			for _, p := range opts.percentiles {
+				value, ok := result.LeadTimePercentiles[percentileKey(p, unit)]
+				if !ok {
+					continue
+				}
+				// Limit the precision so 99.9 becomes 0.999 rather than 0.9990000000000001.
+				quantile := strconv.FormatFloat(p/100, 'g', 12, 64)
+				fmt.Fprintf(&b, "%s{%s,quantile=\"%s\"} %s\n", name, prometheusLabels(result), quantile, strconv.FormatFloat(value, 'g', -1, 64))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writePrometheusHeader(b *strings.Builder, name, help string) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+// prometheusLabels renders the repo label, and the user label when the result belongs to a user.
+func prometheusLabels(result OutputRepoStats) string {
+	labels := fmt.Sprintf("repo=\"%s\"", escapePrometheusLabel(result.Name))
+	if result.User != "" {
+		labels += fmt.Sprintf(",user=\"%s\"", escapePrometheusLabel(result.User))
+	}
+	return labels
+}
+
+// escapePrometheusLabel escapes a label value as required by the text format: backslash, double quote and line feed.
+func escapePrometheusLabel(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value)
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWritePrometheus(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", User: "alice", Commits: 12, CreatedPRs: 3, ReviewedPRs: 8, LeadTimePercentiles: LeadTimePercentiles{"p50_hours": 1.5, "p90_hours": 26.25}},
+		{Name: `org/"odd"\repo`, User: "bob", Commits: 1},
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, writeResults(&buf, formatPrometheus, nil, results, outputOptions{calculateLeadTime: true, percentiles: []float64{50, 90}}))
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "prometheus.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestEscapePrometheusLabel(t *testing.T) {
+	assert.Equal(t, `a\\b\"c\nd`, escapePrometheusLabel("a\\b\"c\nd"))
+}
+
+func TestWritePrometheus_FractionalPercentile(t *testing.T) {
+	results := []OutputRepoStats{{Name: "org/repo-a", LeadTimePercentiles: LeadTimePercentiles{"p99.9_days": 2}}}
+
+	var buf bytes.Buffer
+	require.NoError(t, writePrometheus(&buf, results, outputOptions{calculateLeadTime: true, percentiles: []float64{99.9}, unit: unitDays}))
+	assert.Contains(t, buf.String(), "github_stats_lead_time_days{repo=\"org/repo-a\",quantile=\"0.999\"} 2\n")
+}
@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+)
+
+// activeRepos returns the sorted names of the repositories with any activity of their user, without duplicates.
+func activeRepos(results []*domain.RepoStats) []string {
+	repos := []string{}
+	for _, r := range results {
+		if r.HasActivity() {
+			repos = append(repos, r.Name)
+		}
+	}
+	slices.Sort(repos)
+	return slices.Compact(repos)
+}
+
+// writeRepoList writes one repository name per line, or a JSON array of them when asJSON is set.
+func writeRepoList(w io.Writer, repos []string, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(repos, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal repositories to JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+	for _, repo := range repos {
+		if _, err := fmt.Fprintln(w, repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestActiveRepos(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "org/repo-c", User: "alice", ReviewedPRs: 1},
+		{Name: "org/idle", User: "alice", OrgCommits: 50},
+		{Name: "org/repo-a", User: "alice", Commits: 2},
+		{Name: "org/repo-c", User: "bob", Commits: 1},
+		{Name: "org/empty", User: "bob"},
+	}
+	// Repositories with only zero counts of the user's own activity are left out, and each name is listed once.
+	assert.Equal(t, []string{"org/repo-a", "org/repo-c"}, activeRepos(results))
+	assert.Empty(t, activeRepos([]*domain.RepoStats{{Name: "org/empty"}}))
+}
+
+func TestWriteRepoList(t *testing.T) {
+	var plain bytes.Buffer
+	require.NoError(t, writeRepoList(&plain, []string{"org/repo-a", "org/repo-c"}, false))
+	assert.Equal(t, "org/repo-a\norg/repo-c\n", plain.String())
+
+	var asJSON bytes.Buffer
+	require.NoError(t, writeRepoList(&asJSON, []string{"org/repo-a"}, true))
+	assert.JSONEq(t, `["org/repo-a"]`, asJSON.String())
+
+	var empty bytes.Buffer
+	require.NoError(t, writeRepoList(&empty, activeRepos(nil), true))
+	assert.Equal(t, "[]\n", empty.String())
+}
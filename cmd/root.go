@@ -3,6 +3,7 @@
 package cmd
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -13,7 +14,37 @@ var rootCmd = &cobra.Command{
 	Short: "A CLI tool to aggregate GitHub user contributions.",
 	Long: `github-stats is a CLI tool that aggregates a user's contributions
 (commits, PRs created/reviewed) per repository within a GitHub organization.
-You can specify a date range to filter the results.`,
+You can specify a date range to filter the results.
+
+Every flag can also be set in $XDG_CONFIG_HOME/github-stats.yaml (or the file given with --config),
+using the flag names as keys, or with GHSTATS_* environment variables such as GHSTATS_ORG.
+Flags given on the command line take precedence.`,
+	PersistentPreRunE: initConfig,
+}
+
+var completionCmd = &cobra.Command{
+	Use:   "completion [bash|zsh|fish|powershell]",
+	Short: "Generates a shell completion script",
+	Long: `Writes the completion script for the given shell to stdout. For example, to load it in the current bash session:
+
+  source <(github-stats completion bash)`,
+	ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+	Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+	DisableFlagsInUseLine: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		switch args[0] {
+		case "bash":
+			return cmd.Root().GenBashCompletionV2(out, true)
+		case "zsh":
+			return cmd.Root().GenZshCompletion(out)
+		case "fish":
+			return cmd.Root().GenFishCompletion(out, true)
+		case "powershell":
+			return cmd.Root().GenPowerShellCompletionWithDesc(out)
+		}
+		return fmt.Errorf("unsupported shell %q", args[0])
+	},
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
@@ -26,6 +57,15 @@ func Execute() {
 }
 
 func init() {
+	// Our own completion command replaces the one Cobra would add implicitly.
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+	rootCmd.AddCommand(completionCmd)
+
+	rootCmd.PersistentFlags().String("config", "", "Config file (default $XDG_CONFIG_HOME/github-stats.yaml)")
 	// Add a persistent flag for verbose output, available to all commands.
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose/debug logging")
+	rootCmd.PersistentFlags().BoolP("quiet", "q", false, "Print nothing but the results; errors are still reported")
+	rootCmd.PersistentFlags().String("log-format", logFormatText, "Format of the --verbose log lines on stderr: text or json")
+	// Asking for both more and less output is a mistake, so it is rejected rather than resolved silently.
+	rootCmd.MarkFlagsMutuallyExclusive("verbose", "quiet")
 }
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// executeCommand runs rootCmd with args and returns what it wrote to stdout and stderr.
+// Cobra keeps flag values between runs, so every flag is reset afterwards.
+func executeCommand(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	var buf bytes.Buffer
+	rootCmd.SetOut(&buf)
+	rootCmd.SetErr(&buf)
+	rootCmd.SetArgs(args)
+	t.Cleanup(func() {
+		rootCmd.SetOut(nil)
+		rootCmd.SetErr(nil)
+		rootCmd.SetArgs(nil)
+		resetFlags(t, rootCmd)
+	})
+	err := rootCmd.Execute()
+	return buf.String(), err
+}
+
+// resetFlags restores the default value of every flag of cmd and its subcommands.
+func resetFlags(t *testing.T, cmd *cobra.Command) {
+	t.Helper()
+	for _, flags := range []*pflag.FlagSet{cmd.PersistentFlags(), cmd.Flags()} {
+		flags.VisitAll(func(flag *pflag.Flag) {
+			if !flag.Changed {
+				return
+			}
+			if slice, ok := flag.Value.(pflag.SliceValue); ok {
+				require.NoError(t, slice.Replace(nil))
+			} else {
+				require.NoError(t, flag.Value.Set(flag.DefValue))
+			}
+			flag.Changed = false
+		})
+	}
+	for _, child := range cmd.Commands() {
+		resetFlags(t, child)
+	}
+}
+
+func TestCompletionCommand(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		t.Run(shell, func(t *testing.T) {
+			out, err := executeCommand(t, "completion", shell)
+			require.NoError(t, err)
+			assert.Contains(t, out, "github-stats")
+		})
+	}
+}
+
+func TestCompletionCommand_UnsupportedShell(t *testing.T) {
+	_, err := executeCommand(t, "completion", "tcsh")
+	assert.Error(t, err)
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// outputSchema is the JSON Schema of the stats output.
+// It is maintained by hand so it can carry descriptions; TestOutputSchemaMatchesStructs fails when it drifts from OutputRepoStats.
+//
+//go:embed schema.json
+var outputSchema string
+
+var schemaCmd = &cobra.Command{
+	Use:   "schema",
+	Short: "Prints the JSON Schema of the stats output",
+	Long:  `Prints the JSON Schema describing the JSON written by the stats command, so downstream tools can validate it.`,
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), outputSchema)
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemaCmd)
+}
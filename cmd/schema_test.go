@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// schemaObject is the part of a JSON Schema object definition the drift test compares.
+type schemaObject struct {
+	Required   []string                   `json:"required"`
+	Properties map[string]json.RawMessage `json:"properties"`
+}
+
+// jsonFields returns the JSON names of the exported fields of v's type and the ones without omitempty, both sorted.
+func jsonFields(v any) (all, required []string) {
+	typ := reflect.TypeOf(v)
+	for i := 0; i < typ.NumField(); i++ {
+		tag, ok := typ.Field(i).Tag.Lookup("json")
+		if !ok || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		all = append(all, name)
+		if !strings.Contains(options, "omitempty") {
+			required = append(required, name)
+		}
+	}
+	sort.Strings(all)
+	sort.Strings(required)
+	return all, required
+}
+
+func TestOutputSchemaMatchesStructs(t *testing.T) {
+	var schema struct {
+		Defs map[string]schemaObject `json:"$defs"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(outputSchema), &schema))
+
+	testCases := []struct {
+		def    string
+		target any
+	}{
+		{def: "repoStats", target: OutputRepoStats{}},
+		{def: "share", target: ShareStats{}},
+		{def: "weightedLeadTime", target: WeightedLeadTime{}},
+		{def: "bucket", target: domain.BucketStats{}},
+		{def: "metadata", target: outputMetadata{}},
+		{def: "phaseRetries", target: outputPhaseRetries{}},
+		{def: "document", target: outputDocument{}},
+		{def: "summary", target: outputSummary{}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.def, func(t *testing.T) {
+			def, ok := schema.Defs[tc.def]
+			require.True(t, ok, "schema has no $defs/%s", tc.def)
+
+			properties := make([]string, 0, len(def.Properties))
+			for name := range def.Properties {
+				properties = append(properties, name)
+			}
+			sort.Strings(properties)
+			required := append([]string(nil), def.Required...)
+			sort.Strings(required)
+
+			wantAll, wantRequired := jsonFields(tc.target)
+			assert.Equal(t, wantAll, properties, "update cmd/schema.json to match the struct fields")
+			assert.Equal(t, wantRequired, required, "fields without omitempty must be required in cmd/schema.json")
+		})
+	}
+}
+
+func TestSchemaCommand(t *testing.T) {
+	out, err := executeCommand(t, "schema")
+	require.NoError(t, err)
+	assert.True(t, json.Valid([]byte(out)))
+	assert.Equal(t, outputSchema, out)
+}
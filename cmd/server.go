@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"cmp"
+
+	"github.com/naka-gawa/github-stats/internal/gateway"
+)
+
+// serverOptions selects the GitHub server: the --base-url flag value, else $GITHUB_BASE_URL, else the
+// GITHUB_API_URL and GITHUB_GRAPHQL_URL that GitHub Actions sets on Enterprise Server runners, else github.com.
+// server identifies the selected server to keep its cache entries apart, and is empty for github.com.
+func serverOptions(baseURL string, getenv func(string) string) (opts []gateway.Option, server string) {
+	baseURL = cmp.Or(baseURL, getenv("GITHUB_BASE_URL"))
+	if baseURL != "" {
+		return []gateway.Option{gateway.WithBaseURL(baseURL)}, baseURL
+	}
+	restURL, graphqlURL := gateway.ActionsAPIURLs(getenv)
+	if restURL == "" && graphqlURL == "" {
+		return nil, ""
+	}
+	return []gateway.Option{gateway.WithAPIURLs(restURL, graphqlURL)}, cmp.Or(restURL, graphqlURL)
+}
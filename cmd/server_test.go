@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServerOptions(t *testing.T) {
+	env := map[string]string{
+		"GITHUB_BASE_URL":    "https://env.example.com",
+		"GITHUB_API_URL":     "https://actions.example.com/api/v3",
+		"GITHUB_GRAPHQL_URL": "https://actions.example.com/api/graphql",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	opts, server := serverOptions("https://flag.example.com", getenv)
+	assert.Len(t, opts, 1)
+	assert.Equal(t, "https://flag.example.com", server, "the flag wins")
+
+	_, server = serverOptions("", getenv)
+	assert.Equal(t, "https://env.example.com", server, "GITHUB_BASE_URL wins over the Actions variables")
+
+	delete(env, "GITHUB_BASE_URL")
+	opts, server = serverOptions("", getenv)
+	assert.Len(t, opts, 1)
+	assert.Equal(t, "https://actions.example.com/api/v3", server)
+
+	env["GITHUB_API_URL"], env["GITHUB_GRAPHQL_URL"] = "https://api.github.com", "https://api.github.com/graphql"
+	opts, server = serverOptions("", getenv)
+	assert.Empty(t, opts, "github.com needs no options")
+	assert.Empty(t, server)
+}
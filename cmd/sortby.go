@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+)
+
+// sortByName is the default --sort-by value, keeping the aggregator's order by repository name.
+const sortByName = "name"
+
+// sortMetrics are the counts --sort-by can order the repositories by, highest first.
+var sortMetrics = map[string]func(*domain.RepoStats) int{
+	"commits":         func(r *domain.RepoStats) int { return r.Commits },
+	"created_prs":     func(r *domain.RepoStats) int { return r.CreatedPRs },
+	"merged_prs":      func(r *domain.RepoStats) int { return r.MergedPRs },
+	"reviewed_prs":    func(r *domain.RepoStats) int { return r.ReviewedPRs },
+	"commented_prs":   func(r *domain.RepoStats) int { return r.CommentedPRs },
+	"review_comments": func(r *domain.RepoStats) int { return r.ReviewComments },
+	"approvals_given": func(r *domain.RepoStats) int { return r.ApprovalsGiven },
+	"created_issues":  func(r *domain.RepoStats) int { return r.CreatedIssues },
+	"closed_issues":   func(r *domain.RepoStats) int { return r.ClosedIssues },
+}
+
+// sortByValues lists the valid --sort-by values, name first.
+func sortByValues() []string {
+	values := make([]string, 0, len(sortMetrics)+1)
+	for metric := range sortMetrics {
+		values = append(values, metric)
+	}
+	sort.Strings(values)
+	return append([]string{sortByName}, values...)
+}
+
+// validateSortBy returns an error when sortBy is not a supported --sort-by value.
+func validateSortBy(sortBy string) error {
+	if _, ok := sortMetrics[sortBy]; !ok && sortBy != sortByName {
+		return fmt.Errorf("unsupported sort metric %q (valid: %s)", sortBy, strings.Join(sortByValues(), ", "))
+	}
+	return nil
+}
+
+// sortResults returns the results ordered by the sortBy metric, highest first. Ties keep the incoming order,
+// which is by name, so the order is stable. With sortByName the results are returned as they are.
+func sortResults(results []*domain.RepoStats, sortBy string) []*domain.RepoStats {
+	metric, ok := sortMetrics[sortBy]
+	if !ok {
+		return results
+	}
+	sorted := append([]*domain.RepoStats(nil), results...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return metric(sorted[i]) > metric(sorted[j])
+	})
+	return sorted
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/montanaflynn/stats"
+	"github.com/naka-gawa/github-stats/internal/domain"
+
+	// The pure-Go SQLite driver keeps the binary free of cgo.
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema is the --sqlite table, holding one snapshot row per day, org, user and repository.
+const sqliteSchema = `CREATE TABLE IF NOT EXISTS repo_stats (
+	date TEXT NOT NULL,
+	org TEXT NOT NULL,
+	user TEXT NOT NULL,
+	repo TEXT NOT NULL,
+	commits INTEGER NOT NULL,
+	created_prs INTEGER NOT NULL,
+	reviewed_prs INTEGER NOT NULL,
+	merged_prs INTEGER NOT NULL,
+	lead_time_p50_seconds REAL,
+	PRIMARY KEY (date, org, user, repo)
+)`
+
+// sqliteUpsert replaces the counts of a snapshot row that already exists, so a second run on the same day
+// updates that day's snapshot instead of adding another one.
+const sqliteUpsert = `INSERT INTO repo_stats
+	(date, org, user, repo, commits, created_prs, reviewed_prs, merged_prs, lead_time_p50_seconds)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT (date, org, user, repo) DO UPDATE SET
+		commits = excluded.commits,
+		created_prs = excluded.created_prs,
+		reviewed_prs = excluded.reviewed_prs,
+		merged_prs = excluded.merged_prs,
+		lead_time_p50_seconds = excluded.lead_time_p50_seconds`
+
+// writeSQLite upserts results into the repo_stats table of the SQLite database at path as the snapshot
+// of date (YYYY-MM-DD) for org, creating the database and table if needed. The rows are written in one
+// transaction, so a failed run leaves the previous snapshot intact.
+func writeSQLite(ctx context.Context, path, date, org string, results []*domain.RepoStats) error {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer db.Close()
+	if _, err := db.ExecContext(ctx, sqliteSchema); err != nil {
+		return fmt.Errorf("failed to create the repo_stats table: %w", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // A no-op once committed.
+	for _, repoStat := range results {
+		var leadTime sql.NullFloat64
+		if len(repoStat.LeadTimeToLastReviewSeconds) > 0 {
+			leadTime.Float64, _ = stats.Percentile(repoStat.LeadTimeToLastReviewSeconds, 50)
+			leadTime.Valid = true
+		}
+		if _, err := tx.ExecContext(ctx, sqliteUpsert, date, org, repoStat.User, repoStat.Name,
+			repoStat.Commits, repoStat.CreatedPRs, repoStat.ReviewedPRs, repoStat.MergedPRs, leadTime); err != nil {
+			return fmt.Errorf("failed to write %s: %w", repoStat.Name, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type sqliteRow struct {
+	date, org, user, repo                       string
+	commits, createdPRs, reviewedPRs, mergedPRs int
+	leadTime                                    sql.NullFloat64
+}
+
+func readSQLiteRows(t *testing.T, path string) []sqliteRow {
+	t.Helper()
+	db, err := sql.Open("sqlite", path)
+	require.NoError(t, err)
+	defer db.Close()
+	rows, err := db.Query(`SELECT date, org, user, repo, commits, created_prs, reviewed_prs, merged_prs, lead_time_p50_seconds
+		FROM repo_stats ORDER BY date, repo`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var result []sqliteRow
+	for rows.Next() {
+		var r sqliteRow
+		require.NoError(t, rows.Scan(&r.date, &r.org, &r.user, &r.repo, &r.commits, &r.createdPRs, &r.reviewedPRs, &r.mergedPRs, &r.leadTime))
+		result = append(result, r)
+	}
+	require.NoError(t, rows.Err())
+	return result
+}
+
+func TestWriteSQLite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.db")
+	ctx := context.Background()
+
+	require.NoError(t, writeSQLite(ctx, path, "2024-05-01", "org", []*domain.RepoStats{
+		{Name: "org/repo-a", User: "alice", Commits: 2, CreatedPRs: 1, LeadTimeToLastReviewSeconds: []float64{100, 300}},
+		{Name: "org/repo-b", User: "alice", ReviewedPRs: 3},
+	}))
+	assert.Equal(t, []sqliteRow{
+		{date: "2024-05-01", org: "org", user: "alice", repo: "org/repo-a", commits: 2, createdPRs: 1, leadTime: sql.NullFloat64{Float64: 200, Valid: true}},
+		{date: "2024-05-01", org: "org", user: "alice", repo: "org/repo-b", reviewedPRs: 3},
+	}, readSQLiteRows(t, path))
+
+	// A re-run on the same day updates the day's rows, and the next day adds its own.
+	require.NoError(t, writeSQLite(ctx, path, "2024-05-01", "org", []*domain.RepoStats{
+		{Name: "org/repo-a", User: "alice", Commits: 5, CreatedPRs: 1, MergedPRs: 1},
+	}))
+	require.NoError(t, writeSQLite(ctx, path, "2024-05-02", "org", []*domain.RepoStats{
+		{Name: "org/repo-a", User: "alice", Commits: 1},
+	}))
+	assert.Equal(t, []sqliteRow{
+		{date: "2024-05-01", org: "org", user: "alice", repo: "org/repo-a", commits: 5, createdPRs: 1, mergedPRs: 1},
+		{date: "2024-05-01", org: "org", user: "alice", repo: "org/repo-b", reviewedPRs: 3},
+		{date: "2024-05-02", org: "org", user: "alice", repo: "org/repo-a", commits: 1},
+	}, readSQLiteRows(t, path))
+}
+
+func TestWriteSQLite_InvalidPath(t *testing.T) {
+	err := writeSQLite(context.Background(), filepath.Join(t.TempDir(), "missing", "stats.db"), "2024-05-01", "org", nil)
+	assert.Error(t, err)
+}
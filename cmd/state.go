@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// defaultSinceLastRunLookback is how far back --since-last-run starts when there is no recorded run yet.
+const defaultSinceLastRunLookback = 7 * 24 * time.Hour
+
+// lastRunState is the content of a --since-last-run state file.
+type lastRunState struct {
+	LastRun time.Time `json:"last_run"`
+}
+
+// lastRunStatePath returns the state file for a set of orgs, users and teams under dir.
+// Each combination gets its own file, so differently scoped cron jobs don't move each other's start date.
+func lastRunStatePath(dir string, orgs, users, teams []string) string {
+	key := strings.Join(orgs, ",") + "\x00" + strings.Join(users, ",") + "\x00" + strings.Join(teams, ",")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(dir, "state", "last-run-"+hex.EncodeToString(sum[:8])+".json")
+}
+
+// readLastRun returns the time recorded in the state file at path.
+// A missing file means this is the first run, so it returns now minus the default lookback.
+func readLastRun(path string, now time.Time) (time.Time, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return now.Add(-defaultSinceLastRunLookback), nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	var state lastRunState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return time.Time{}, fmt.Errorf("invalid state file %s: %w", path, err)
+	}
+	if state.LastRun.IsZero() {
+		return time.Time{}, fmt.Errorf("invalid state file %s: last_run is missing", path)
+	}
+	return state.LastRun.In(now.Location()), nil
+}
+
+// recordLastRun stores t as the last run when --since-last-run is on, i.e. path is set.
+// A failure only warns, since the results have already been written.
+func recordLastRun(path string, t time.Time) {
+	if path == "" {
+		return
+	}
+	if err := writeLastRun(path, t); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record the last run: %v\n", err)
+	}
+}
+
+// writeLastRun records t as the last successful run in the state file at path.
+func writeLastRun(path string, t time.Time) error {
+	data, err := json.Marshal(lastRunState{LastRun: t})
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first so an interrupted run never leaves a truncated state behind.
+	tmp, err := os.CreateTemp(dir, "state-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLastRun(t *testing.T) {
+	dir := t.TempDir()
+	path := lastRunStatePath(dir, []string{"org"}, []string{"alice"}, nil)
+	first := time.Date(2024, 5, 10, 9, 30, 0, 0, time.UTC)
+
+	from, err := readLastRun(path, first)
+	require.NoError(t, err)
+	assert.Equal(t, first.Add(-defaultSinceLastRunLookback), from, "the first run looks back the default period")
+
+	require.NoError(t, writeLastRun(path, first))
+	from, err = readLastRun(path, first.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, first.Equal(from), "the next run starts where the previous one began")
+
+	second := first.Add(24 * time.Hour)
+	require.NoError(t, writeLastRun(path, second))
+	from, err = readLastRun(path, second.Add(time.Hour))
+	require.NoError(t, err)
+	assert.True(t, second.Equal(from))
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "no temporary files are left behind")
+}
+
+func TestLastRunStatePath(t *testing.T) {
+	a := lastRunStatePath("dir", []string{"org"}, []string{"alice"}, nil)
+	assert.Equal(t, a, lastRunStatePath("dir", []string{"org"}, []string{"alice"}, nil))
+	assert.NotEqual(t, a, lastRunStatePath("dir", []string{"org"}, []string{"bob"}, nil))
+	assert.NotEqual(t, a, lastRunStatePath("dir", []string{"org"}, []string{"alice"}, []string{"org/team"}))
+}
+
+func TestReadLastRun_Invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "last-run.json")
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o600))
+	_, err := readLastRun(path, time.Now())
+	assert.Error(t, err)
+}
@@ -2,36 +2,90 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
+	"log/slog"
+	"math"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/montanaflynn/stats"
+	"github.com/naka-gawa/github-stats/internal/domain"
 	"github.com/naka-gawa/github-stats/internal/gateway"
 	"github.com/naka-gawa/github-stats/internal/usecase"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
-// LeadTimePercentiles defines the structure for percentile data.
-type LeadTimePercentiles struct {
-	P99 float64 `json:"p99_hours"`
-	P95 float64 `json:"p95_hours"`
-	P90 float64 `json:"p90_hours"`
-	P75 float64 `json:"p75_hours"`
-	P50 float64 `json:"p50_hours"` // Median
+// LeadTimePercentiles maps a percentile key such as "p50_hours" or "p99.9_hours", or "min_hours" and "max_hours", to its value in hours.
+type LeadTimePercentiles map[string]float64
+
+// PRSizePercentiles maps percentile keys such as "p90_lines" to PR sizes in added plus deleted lines.
+type PRSizePercentiles map[string]float64
+
+// prSizePercentiles are the percentiles of the PR size distribution reported with --churn.
+var prSizePercentiles = []float64{50, 90, 99}
+
+// defaultPercentiles are reported when --percentiles is not given.
+var defaultPercentiles = []string{"50", "75", "90", "95", "99"}
+
+// ShareStats describes the user's contributions relative to all authors in a repository.
+type ShareStats struct {
+	OrgCommits        int     `json:"org_commits"`
+	OrgCreatedPRs     int     `json:"org_created_prs"`
+	CommitsPercent    float64 `json:"commits_percent"`
+	CreatedPRsPercent float64 `json:"created_prs_percent"`
 }
 
 // OutputRepoStats defines the structure for the final JSON output.
 type OutputRepoStats struct {
-	Name                string               `json:"name"`
-	Commits             int                  `json:"commits"`
-	CreatedPRs          int                  `json:"created_prs"`
-	ReviewedPRs         int                  `json:"reviewed_prs"`
-	AnalyzedPRCount     int                  `json:"analyzed_pr_count,omitempty"`
-	LeadTimePercentiles *LeadTimePercentiles `json:"lead_time_percentiles_hours,omitempty"`
+	Name                         string                        `json:"name"`
+	User                         string                        `json:"user,omitempty"`
+	NodeID                       string                        `json:"node_id,omitempty"`
+	PrimaryLanguage              string                        `json:"primary_language,omitempty"`
+	Commits                      int                           `json:"commits"`
+	CreatedPRs                   int                           `json:"created_prs"`
+	DraftPRs                     *int                          `json:"draft_prs,omitempty"`
+	MergedPRs                    *int                          `json:"merged_prs,omitempty"`
+	MergeRate                    *float64                      `json:"merge_rate,omitempty"`
+	ReviewedPRs                  int                           `json:"reviewed_prs"`
+	CommentedPRs                 *int                          `json:"commented_prs,omitempty"`
+	ReviewComments               *int                          `json:"review_comments,omitempty"`
+	ApprovalsGiven               *int                          `json:"approvals_given,omitempty"`
+	AssignedPRs                  *int                          `json:"assigned_prs,omitempty"`
+	OpenPRs                      *int                          `json:"open_prs,omitempty"`
+	CreatedIssues                *int                          `json:"created_issues,omitempty"`
+	ClosedIssues                 *int                          `json:"closed_issues,omitempty"`
+	TotalAdditions               *int                          `json:"total_additions,omitempty"`
+	TotalDeletions               *int                          `json:"total_deletions,omitempty"`
+	MedianPRSizeLines            *float64                      `json:"median_pr_size_lines,omitempty"`
+	PRSizePercentiles            PRSizePercentiles             `json:"pr_size_percentiles_lines,omitempty"`
+	CommitAdditions              *int                          `json:"commit_additions,omitempty"`
+	CommitDeletions              *int                          `json:"commit_deletions,omitempty"`
+	AnalyzedPRCount              int                           `json:"analyzed_pr_count,omitempty"`
+	LeadTimePercentiles          LeadTimePercentiles           `json:"lead_time_percentiles_hours,omitempty"`
+	TimeToFirstReviewPercentiles LeadTimePercentiles           `json:"time_to_first_review_percentiles_hours,omitempty"`
+	MergeTimePercentiles         LeadTimePercentiles           `json:"merge_time_percentiles_hours,omitempty"`
+	ReviewerLatencyPercentiles   LeadTimePercentiles           `json:"reviewer_latency_percentiles_hours,omitempty"`
+	AuthorWaitPercentiles        LeadTimePercentiles           `json:"author_wait_percentiles_hours,omitempty"`
+	WeightedLeadTime             *WeightedLeadTime             `json:"weighted_lead_time_hours,omitempty"`
+	LeadTimeHistogram            LeadTimeHistogram             `json:"lead_time_histogram,omitempty"`
+	Share                        *ShareStats                   `json:"share,omitempty"`
+	Series                       map[string]domain.BucketStats `json:"series,omitempty"`
+	CommitHeatmap                *domain.Heatmap               `json:"commit_heatmap,omitempty"`
+
+	// unit is the --lead-time-unit the durations are expressed in; MarshalJSON renames the "_hours" keys to match.
+	unit string
 }
 
 var statsCmd = &cobra.Command{
@@ -39,112 +93,1317 @@ var statsCmd = &cobra.Command{
 	Short: "Aggregates GitHub user activity and outputs as JSON",
 	Long:  `Aggregates activity (commits, created/reviewed PRs) for a specified GitHub user and organization, and outputs the result in JSON format.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		ctx := context.Background()
 		verbose, _ := cmd.InheritedFlags().GetBool("verbose")
-		logger := log.New(io.Discard, "", log.LstdFlags)
-		if verbose {
-			logger.SetOutput(os.Stderr)
+		quiet, _ := cmd.InheritedFlags().GetBool("quiet")
+		logFormat, _ := cmd.InheritedFlags().GetString("log-format")
+		logger, err := newLogger(os.Stderr, verbose, logFormat)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
 		}
 
-		org, _ := cmd.Flags().GetString("org")
-		user, _ := cmd.Flags().GetString("user")
+		orgs, _ := cmd.Flags().GetStringSlice("org")
+		repo, _ := cmd.Flags().GetString("repo")
+		users, _ := cmd.Flags().GetStringSlice("user")
+		teams, _ := cmd.Flags().GetStringSlice("team")
 		fromStr, _ := cmd.Flags().GetString("from")
 		toStr, _ := cmd.Flags().GetString("to")
-		calculateLeadTime, _ := cmd.Flags().GetBool("lead-time")
-		token := os.Getenv("GITHUB_TOKEN")
-		if token == "" {
-			fmt.Fprintln(os.Stderr, "Error: GITHUB_TOKEN environment variable is not set.")
+		lastStr, _ := cmd.Flags().GetString("last")
+		period, _ := cmd.Flags().GetString("period")
+		sinceLastRun, _ := cmd.Flags().GetBool("since-last-run")
+		skipValidation, _ := cmd.Flags().GetBool("skip-validation")
+		failOnEmpty, _ := cmd.Flags().GetBool("fail-on-empty")
+		granularity, _ := cmd.Flags().GetString("granularity")
+		timezone, _ := cmd.Flags().GetString("timezone")
+		calculateLeadTime := leadTimeEnabled(cmd.Flags())
+		percentileStrs, _ := cmd.Flags().GetStringSlice("percentiles")
+		halfLifeStr, _ := cmd.Flags().GetString("lead-time-halflife")
+		histogram, _ := cmd.Flags().GetBool("histogram")
+		histogramBucketStrs, _ := cmd.Flags().GetStringSlice("histogram-buckets")
+		leadTimeUnit, _ := cmd.Flags().GetString("lead-time-unit")
+		handleReopens, _ := cmd.Flags().GetBool("handle-reopens")
+		commentStats, _ := cmd.Flags().GetBool("comment-stats")
+		churn, _ := cmd.Flags().GetBool("churn")
+		reviewerLatency, _ := cmd.Flags().GetBool("reviewer-latency")
+		authorWait, _ := cmd.Flags().GetBool("author-wait")
+		commitChurn, _ := cmd.Flags().GetBool("commit-churn")
+		reposOnly, _ := cmd.Flags().GetBool("repos-only")
+		withMetadata, _ := cmd.Flags().GetBool("with-metadata")
+		withSummary, _ := cmd.Flags().GetBool("with-summary")
+		anonymize, _ := cmd.Flags().GetBool("anonymize")
+		excludeBots, _ := cmd.Flags().GetBool("exclude-bots")
+		botLogins, _ := cmd.Flags().GetStringSlice("bot-logins")
+		reviewComments, _ := cmd.Flags().GetBool("review-comments")
+		approvals, _ := cmd.Flags().GetBool("approvals")
+		issues, _ := cmd.Flags().GetBool("issues")
+		assigned, _ := cmd.Flags().GetBool("assigned")
+		openPRs, _ := cmd.Flags().GetBool("open")
+		share, _ := cmd.Flags().GetBool("share")
+		withNodeIDs, _ := cmd.Flags().GetBool("with-node-ids")
+		languages, _ := cmd.Flags().GetBool("languages")
+		heatmap, _ := cmd.Flags().GetBool("heatmap")
+		includeRepos, _ := cmd.Flags().GetStringArray("include-repo")
+		excludeRepos, _ := cmd.Flags().GetStringArray("exclude-repo")
+		labels, _ := cmd.Flags().GetStringArray("label")
+		format, _ := cmd.Flags().GetString("format")
+		compact, _ := cmd.Flags().GetBool("compact")
+		strict, _ := cmd.Flags().GetBool("strict")
+		bestEffort, _ := cmd.Flags().GetBool("best-effort")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		explain, _ := cmd.Flags().GetBool("explain")
+		totals, _ := cmd.Flags().GetBool("totals")
+		limit, _ := cmd.Flags().GetInt("limit")
+		sortBy, _ := cmd.Flags().GetString("sort-by")
+		minCommits, _ := cmd.Flags().GetInt("min-commits")
+		minPRs, _ := cmd.Flags().GetInt("min-prs")
+		maxNameWidth, _ := cmd.Flags().GetInt("max-name-width")
+		colorMode, _ := cmd.Flags().GetString("color")
+		concurrency, _ := cmd.Flags().GetInt("concurrency")
+		showRateLimit, _ := cmd.Flags().GetBool("show-rate-limit")
+		cacheTTL, _ := cmd.Flags().GetDuration("cache-ttl")
+		noCache, _ := cmd.Flags().GetBool("no-cache")
+		pageSize, _ := cmd.Flags().GetInt("page-size")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+		watch, _ := cmd.Flags().GetDuration("watch")
+		tokenFile, _ := cmd.Flags().GetString("token-file")
+		maxRetries, _ := cmd.Flags().GetInt("max-retries")
+		retryBaseDelay, _ := cmd.Flags().GetDuration("retry-base-delay")
+		maxSleep, _ := cmd.Flags().GetDuration("max-sleep")
+		baseURL, _ := cmd.Flags().GetString("base-url")
+		proxy, _ := cmd.Flags().GetString("proxy")
+		debugHTTP, _ := cmd.Flags().GetBool("debug-http")
+		caCert, _ := cmd.Flags().GetString("ca-cert")
+		insecureSkipVerify, _ := cmd.Flags().GetBool("insecure-skip-verify")
+		serverOpts, server := serverOptions(baseURL, os.Getenv)
+		outputPath, _ := cmd.Flags().GetString("output")
+		outputDir, _ := cmd.Flags().GetString("output-dir")
+		sqlitePath, _ := cmd.Flags().GetString("sqlite")
+		noCommits, _ := cmd.Flags().GetBool("no-commits")
+		mergedCommitsOnly, _ := cmd.Flags().GetBool("merged-commits-only")
+		dedupeCommits, _ := cmd.Flags().GetBool("dedupe-commits")
+		commitsSource, _ := cmd.Flags().GetString("commits-source")
+		reviewStateStrs, _ := cmd.Flags().GetStringSlice("review-states")
+		noPRCounts, _ := cmd.Flags().GetBool("no-pr-counts")
+		drafts, _ := cmd.Flags().GetBool("drafts")
+		excludeDrafts, _ := cmd.Flags().GetBool("exclude-drafts")
+		mergedPRs, _ := cmd.Flags().GetBool("merged-prs")
+		noReviews, _ := cmd.Flags().GetBool("no-reviews")
+		// The timeout covers every request of the run, or of every refresh with --watch;
+		// cancelling the context aborts all in-flight fetches.
+		ctx := context.Background()
+		if timeout > 0 && watch == 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+
+		// scopes are what the searches are limited to: the organizations, or the --repo repository instead.
+		// The gateway turns an owner/name scope into a repo: qualifier.
+		var scopes []string
+		if repo != "" {
+			if err := validateRepoName(repo); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --repo: %v\n", err)
+				os.Exit(1)
+			}
+			orgs = nil
+			scopes = []string{repo}
+		} else {
+			orgs, err = normalizeNames("--org", orgs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			scopes = orgs
+		}
+		scope := strings.Join(scopes, ",")
+		// Team members are only known after the API call, so users are checked again once they are added.
+		if len(users) > 0 || len(teams) == 0 {
+			users, err = normalizeNames("--user", users)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		for _, team := range teams {
+			if _, _, err := usecase.ParseTeam(team); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if len(labels) > 0 {
+			labels, err = normalizeNames("--label", labels)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		for _, patterns := range [][]string{includeRepos, excludeRepos} {
+			if err := usecase.ValidateRepoPatterns(patterns); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if err := validateFormat(format); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if outputDir != "" && outputPath != "" {
+			fmt.Fprintln(os.Stderr, "Error: --output and --output-dir cannot be used together.")
+			os.Exit(1)
+		}
+		if outputDir != "" && format != formatJSON {
+			fmt.Fprintln(os.Stderr, "Error: --output-dir only supports the json format.")
+			os.Exit(1)
+		}
+		if withMetadata && (outputDir != "" || reposOnly || format != formatJSON) {
+			fmt.Fprintln(os.Stderr, "Error: --with-metadata only supports the json format and cannot be combined with --output-dir or --repos-only.")
+			os.Exit(1)
+		}
+		if withSummary && (outputDir != "" || reposOnly || format != formatJSON) {
+			fmt.Fprintln(os.Stderr, "Error: --with-summary only supports the json format and cannot be combined with --output-dir or --repos-only.")
+			os.Exit(1)
+		}
+		if reposOnly && (outputDir != "" || format != formatJSON) {
+			fmt.Fprintln(os.Stderr, "Error: --repos-only cannot be combined with --output-dir or a --format other than json.")
+			os.Exit(1)
+		}
+		if watch < 0 {
+			fmt.Fprintln(os.Stderr, "Error: --watch must not be negative.")
+			os.Exit(1)
+		}
+		if watch > 0 && (outputPath != "" || outputDir != "" || reposOnly || sinceLastRun || (format != formatTable && format != formatJSON)) {
+			fmt.Fprintln(os.Stderr, "Error: --watch only supports the table and json formats written to stdout, and cannot be combined with --output, --output-dir, --repos-only or --since-last-run.")
+			os.Exit(1)
+		}
+		if limit < 0 {
+			fmt.Fprintln(os.Stderr, "Error: --limit must not be negative.")
+			os.Exit(1)
+		}
+		if err := validateSortBy(sortBy); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if minCommits < 0 || minPRs < 0 {
+			fmt.Fprintln(os.Stderr, "Error: --min-commits and --min-prs must not be negative.")
+			os.Exit(1)
+		}
+		if err := validateColor(colorMode); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if maxNameWidth != 0 && maxNameWidth < 2 {
+			fmt.Fprintln(os.Stderr, "Error: --max-name-width must be at least 2, or 0 to never truncate.")
+			os.Exit(1)
+		}
+		if concurrency < 1 {
+			fmt.Fprintln(os.Stderr, "Error: --concurrency must be at least 1.")
+			os.Exit(1)
+		}
+		if format == formatScatterCSV && !calculateLeadTime {
+			fmt.Fprintln(os.Stderr, "Error: --format scatter-csv requires --lead-time.")
+			os.Exit(1)
+		}
+		if len(botLogins) > 0 && !excludeBots {
+			fmt.Fprintln(os.Stderr, "Error: --bot-logins requires --exclude-bots.")
+			os.Exit(1)
+		}
+		if share && mergedCommitsOnly {
+			// The all-author totals count every commit, so the share would compare different things.
+			fmt.Fprintln(os.Stderr, "Error: --share cannot be combined with --merged-commits-only.")
+			os.Exit(1)
+		}
+
+		percentiles, err := parsePercentiles(percentileStrs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := validateUnit(leadTimeUnit); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
+		var halfLife time.Duration
+		if halfLifeStr != "" {
+			var err error
+			halfLife, err = parseDayDuration(halfLifeStr)
+			if err != nil || halfLife <= 0 {
+				fmt.Fprintf(os.Stderr, "Invalid --lead-time-halflife value %q: must be a positive duration like 30d\n", halfLifeStr)
+				os.Exit(1)
+			}
+		}
+
+		var histogramBuckets []float64
+		if histogram {
+			histogramBuckets, err = parseHistogramBuckets(histogramBucketStrs)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
 		// Build date range query strings.
-		var commitDateRange, prDateRange string
-		if fromStr != "" || toStr != "" {
-			const githubDateLayout = "2006-01-02"
-			const inputDateLayout = "2006/01/02"
-			fromQuery, toQuery := "*", "*"
-			if fromStr != "" {
-				t, err := time.Parse(inputDateLayout, fromStr)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Invalid --from date format: %v\n", err)
-					os.Exit(1)
-				}
-				fromQuery = t.Format(githubDateLayout)
+		const inputDateLayout = "2006/01/02"
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --timezone %q: %v\n", timezone, err)
+			os.Exit(1)
+		}
+		var from, to time.Time
+		// runStart is recorded for --since-last-run once the run succeeds, so the next run picks up from here.
+		runStart := time.Now().In(loc)
+		var lastRunPath string
+		if sinceLastRun {
+			if period != "" || lastStr != "" || fromStr != "" || toStr != "" {
+				fmt.Fprintln(os.Stderr, "Error: --since-last-run cannot be combined with --period, --last, --from or --to.")
+				os.Exit(1)
 			}
-			if toStr != "" {
-				t, err := time.Parse(inputDateLayout, toStr)
-				if err != nil {
-					fmt.Fprintf(os.Stderr, "Invalid --to date format: %v\n", err)
-					os.Exit(1)
-				}
-				toQuery = t.Format(githubDateLayout)
+			stateDir, err := gateway.DefaultCacheDir()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to find the state directory: %v\n", err)
+				os.Exit(1)
+			}
+			lastRunPath = lastRunStatePath(stateDir, scopes, users, teams)
+			from, err = readLastRun(lastRunPath, runStart)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to read the last run: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if period != "" {
+			if lastStr != "" || fromStr != "" || toStr != "" {
+				fmt.Fprintln(os.Stderr, "Error: --period cannot be combined with --last, --from or --to.")
+				os.Exit(1)
+			}
+			var err error
+			from, to, err = parsePeriod(period, time.Now().In(loc))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if lastStr != "" {
+			if fromStr != "" || toStr != "" {
+				fmt.Fprintln(os.Stderr, "Error: --last cannot be combined with --from or --to.")
+				os.Exit(1)
+			}
+			to = time.Now().In(loc)
+			var err error
+			from, err = parseLast(lastStr, to)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if fromStr != "" {
+			var err error
+			from, err = time.ParseInLocation(inputDateLayout, fromStr, loc)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --from date format: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		if toStr != "" {
+			var err error
+			to, err = time.ParseInLocation(inputDateLayout, toStr, loc)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --to date format: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		commitDateRange, prDateRange := usecase.DateRangeQualifiers(from, to)
+		logger.Printf("Resolved date range: %s\n", describeDateRange(from, to))
+
+		var buckets []usecase.Bucket
+		if granularity != "" {
+			end := to
+			if end.IsZero() {
+				end = time.Now().In(loc)
+			}
+			buckets, err = usecase.SplitBuckets(from, end, granularity)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --granularity: %v\n", err)
+				os.Exit(1)
 			}
-			commitDateRange = fmt.Sprintf(" author-date:%s..%s", fromQuery, toQuery)
-			prDateRange = fmt.Sprintf(" created:%s..%s", fromQuery, toQuery)
 		}
 
-		githubGateway, err := gateway.NewGitHubGateway(token, logger)
+		var failures fetchFailureLog
+		aggregateOpts := usecase.Options{
+			CommitDateRange:   commitDateRange,
+			PRDateRange:       prDateRange,
+			SkipCommits:       noCommits,
+			SkipCreatedPRs:    noPRCounts,
+			Drafts:            drafts,
+			ExcludeDrafts:     excludeDrafts,
+			MergedPRs:         mergedPRs,
+			SkipReviewedPRs:   noReviews,
+			MergedCommitsOnly: mergedCommitsOnly,
+			CommentStats:      commentStats,
+			ReviewComments:    reviewComments,
+			Approvals:         approvals,
+			Assigned:          assigned,
+			OpenPRs:           openPRs,
+			Issues:            issues,
+			Churn:             churn,
+			ReviewerLatency:   reviewerLatency,
+			AuthorWait:        authorWait,
+			CommitChurn:       commitChurn,
+			CalculateLeadTime: calculateLeadTime,
+			HandleReopens:     handleReopens,
+			Share:             share,
+			WithNodeIDs:       withNodeIDs,
+			Languages:         languages,
+			Heatmap:           heatmap,
+			HeatmapLocation:   loc,
+			Labels:            labels,
+			IncludeRepos:      includeRepos,
+			ExcludeRepos:      excludeRepos,
+			Buckets:           buckets,
+			BestEffort:        bestEffort,
+			OnFetchError:      failures.add,
+		}
+		if dryRun {
+			writeDryRun(os.Stderr, scope, users, teams, aggregateOpts)
+			return
+		}
+
+		token, err := resolveToken(tokenFile, os.Getenv, execRunner{})
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to initialize GitHub gateway: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
-		aggregator := usecase.NewAggregator(githubGateway, logger)
 
-		domainResults, err := aggregator.Aggregate(ctx, org, user, commitDateRange, prDateRange, calculateLeadTime)
+		if maxRetries < 0 || retryBaseDelay < 0 {
+			fmt.Fprintln(os.Stderr, "Error: --max-retries and --retry-base-delay must not be negative.")
+			os.Exit(1)
+		}
+		if maxSleep < 0 {
+			fmt.Fprintln(os.Stderr, "Error: --max-sleep must not be negative.")
+			os.Exit(1)
+		}
+		var sleeps gateway.RateLimitSleeps
+		var gatewayCaveats gateway.Caveats
+		var retryStats gateway.RetryStats
+		gatewayOpts := []gateway.Option{
+			gateway.WithRetries(maxRetries, retryBaseDelay),
+			gateway.WithRetryStats(&retryStats),
+			gateway.WithMaxSleep(maxSleep),
+			gateway.WithRateLimitSleeps(&sleeps),
+			gateway.WithCaveats(&gatewayCaveats),
+		}
+		if cmd.Flags().Changed("page-size") {
+			if err := gateway.ValidatePageSize(pageSize); err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --page-size: %v\n", err)
+				os.Exit(1)
+			}
+			gatewayOpts = append(gatewayOpts, gateway.WithPageSizes(gateway.PageSizes{Counts: pageSize, PullRequests: pageSize}))
+		}
+		gatewayOpts = append(gatewayOpts, serverOpts...)
+		if proxy != "" {
+			proxyURL, err := gateway.ParseProxyURL(proxy)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --proxy: %v\n", err)
+				os.Exit(1)
+			}
+			gatewayOpts = append(gatewayOpts, gateway.WithProxy(proxyURL))
+		}
+		if debugHTTP {
+			gatewayOpts = append(gatewayOpts, gateway.WithHTTPDebug(os.Stderr))
+		}
+		if caCert != "" {
+			pool, err := gateway.LoadCACertPool(caCert)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Invalid --ca-cert: %v\n", err)
+				os.Exit(1)
+			}
+			gatewayOpts = append(gatewayOpts, gateway.WithRootCAs(pool))
+		}
+		if insecureSkipVerify {
+			// Printed even with --quiet: the token is sent to whoever answers.
+			fmt.Fprintln(os.Stderr, "WARNING: --insecure-skip-verify disables TLS certificate verification. "+
+				"Anyone intercepting the connection can read your GitHub token. Use --ca-cert instead where possible.")
+			gatewayOpts = append(gatewayOpts, gateway.WithInsecureSkipVerify())
+		}
+		if dedupeCommits {
+			gatewayOpts = append(gatewayOpts, gateway.WithCommitDedupe())
+		}
+		if err := gateway.ValidateCommitsSource(commitsSource); err != nil {
+			fmt.Fprintf(os.Stderr, "Invalid --commits-source: %v\n", err)
+			os.Exit(1)
+		}
+		gatewayOpts = append(gatewayOpts, gateway.WithCommitsSource(commitsSource))
+		reviewStates, err := gateway.ParseReviewStates(reviewStateStrs)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to aggregate stats: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Invalid --review-states: %v\n", err)
 			os.Exit(1)
 		}
+		gatewayOpts = append(gatewayOpts, gateway.WithReviewStates(reviewStates))
+		if excludeBots {
+			gatewayOpts = append(gatewayOpts, gateway.WithBotExclusion(botLogins))
+		}
+		if len(labels) > 0 {
+			gatewayOpts = append(gatewayOpts, gateway.WithLabels(labels))
+		}
+		var progress *spinner
+		if showProgress(verbose, quiet, os.Stdout, os.Stderr) {
+			progress = newSpinner(os.Stderr, 100*time.Millisecond)
+			gatewayOpts = append(gatewayOpts, gateway.WithProgress(progress))
+		}
+		githubGateway, err := gateway.NewGitHubGateway(token, logger, gatewayOpts...)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize GitHub gateway: %v\n", err)
+			os.Exit(1)
+		}
+		if !noCache && cacheTTL > 0 {
+			cacheDir, err := gateway.DefaultCacheDir()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to initialize cache: %v\n", err)
+				os.Exit(1)
+			}
+			if server != "" {
+				// Keep each server's results apart, since the same org and user names can exist on both.
+				cacheDir = filepath.Join(cacheDir, strings.TrimSuffix(repoFileName(strings.TrimRight(server, "/")), ".json"))
+			}
+			// The cache keys don't know how commits are counted, so keep the differently counted results apart.
+			if dedupeCommits {
+				cacheDir = filepath.Join(cacheDir, "dedupe-commits")
+			}
+			if commitsSource != gateway.CommitsSourceREST {
+				cacheDir = filepath.Join(cacheDir, "commits-"+commitsSource)
+			}
+			if !slices.Equal(reviewStates, gateway.DefaultReviewStates) {
+				cacheDir = filepath.Join(cacheDir, "review-states-"+strings.ToLower(strings.Join(reviewStates, "-")))
+			}
+			if excludeBots {
+				name := "exclude-bots"
+				if len(botLogins) > 0 {
+					name += "-" + strings.TrimSuffix(repoFileName(strings.ToLower(strings.Join(botLogins, "-"))), ".json")
+				}
+				cacheDir = filepath.Join(cacheDir, name)
+			}
+			if len(labels) > 0 {
+				cacheDir = filepath.Join(cacheDir, "labels-"+strings.TrimSuffix(repoFileName(strings.ToLower(strings.Join(labels, "-"))), ".json"))
+			}
+			githubGateway = gateway.NewCachingFetcher(githubGateway, cacheDir, cacheTTL, token, &gatewayCaveats, logger)
+		}
 
-		outputResults := make([]OutputRepoStats, 0, len(domainResults))
-		for _, repoStat := range domainResults {
-			outputStat := OutputRepoStats{
-				Name:        repoStat.Name,
-				Commits:     repoStat.Commits,
-				CreatedPRs:  repoStat.CreatedPRs,
-				ReviewedPRs: repoStat.ReviewedPRs,
+		// A misspelled name otherwise just gives empty results, so check the names before the expensive searches.
+		if !skipValidation {
+			if err := validateTargets(ctx, githubGateway, orgs, users); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
 			}
+		}
 
-			// Calculate percentiles if lead time data is available.
-			if calculateLeadTime && len(repoStat.LeadTimeToLastReviewSeconds) > 0 {
-				data := stats.Float64Data(repoStat.LeadTimeToLastReviewSeconds)
-				outputStat.AnalyzedPRCount = len(data)
+		// Without membership, private repositories are invisible and the numbers can silently come out too low.
+		caveats := caveatWriter(os.Stderr, quiet, strict)
+		for _, org := range orgs {
+			hasAccess, err := githubGateway.CheckOrgAccess(ctx, org)
+			if err != nil {
+				reportCaveat(caveats, strict, "could not verify access to organization %q: %v", org, err)
+			} else if !hasAccess {
+				reportCaveat(caveats, strict, "the token is not an active member of organization %q; contributions to private repositories are not visible and results may be incomplete", org)
+			}
+		}
 
-				p99, _ := stats.Percentile(data, 99)
-				p95, _ := stats.Percentile(data, 95)
-				p90, _ := stats.Percentile(data, 90)
-				p75, _ := stats.Percentile(data, 75)
-				p50, _ := stats.Percentile(data, 50)
+		aggregator := usecase.NewAggregator(githubGateway, logger, usecase.WithConcurrency(concurrency))
+		if len(teams) > 0 {
+			members, err := aggregator.TeamMembers(ctx, teams)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to resolve team members: %v\n", err)
+				os.Exit(1)
+			}
+			users, err = normalizeNames("--user", append(users, members...))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
 
-				outputStat.LeadTimePercentiles = &LeadTimePercentiles{
-					P99: p99 / 3600, // Convert seconds to hours
-					P95: p95 / 3600,
-					P90: p90 / 3600,
-					P75: p75 / 3600,
-					P50: p50 / 3600,
-				}
+		// aggregate runs the searches; --watch calls it again on every refresh.
+		aggregate := func(ctx context.Context) ([]*domain.RepoStats, error) {
+			if progress != nil {
+				progress.Start()
+			}
+			domainResults, err := aggregator.AggregateUsers(ctx, scope, users, aggregateOpts)
+			if progress != nil {
+				progress.Stop()
+			}
+			if err != nil {
+				return nil, err
+			}
+			if summary := failures.summary(); summary != "" {
+				reportCaveat(caveats, strict, "some fetches failed and the results are partial: %s", summary)
+			}
+			failures.reset()
+			for _, caveat := range gatewayCaveats.Drain() {
+				reportCaveat(caveats, strict, "%s", caveat)
+			}
+			if showRateLimit {
+				writeRateLimit(ctx, os.Stderr, githubGateway, time.Now())
 			}
-			outputResults = append(outputResults, outputStat)
+			if anonymize {
+				anonymizeRepoNames(domainResults)
+			}
+			return domainResults, nil
 		}
 
-		// Marshal the final results into a pretty-printed JSON string.
-		jsonData, err := json.MarshalIndent(outputResults, "", "  ")
+		// buildOutput converts the aggregated results into what is written, returning the shown repositories,
+		// their output rows including the TOTAL row, and the options to write them with.
+		buildOutput := func(domainResults []*domain.RepoStats) ([]*domain.RepoStats, []OutputRepoStats, outputOptions) {
+			outputOpts := outputOptions{
+				calculateLeadTime: calculateLeadTime,
+				percentiles:       percentiles,
+				halfLife:          halfLife,
+				histogramBuckets:  histogramBuckets,
+				commentStats:      commentStats,
+				churn:             churn,
+				reviewerLatency:   reviewerLatency,
+				commitChurn:       commitChurn,
+				reviewComments:    reviewComments,
+				approvals:         approvals,
+				drafts:            (drafts || excludeDrafts) && !noPRCounts,
+				mergedPRs:         mergedPRs,
+				maxNameWidth:      maxNameWidth,
+				issues:            issues,
+				share:             share,
+				assigned:          assigned,
+				compact:           compact,
+				openPRs:           openPRs,
+				authorWait:        authorWait,
+				unit:              leadTimeUnit,
+				now:               time.Now(),
+			}
+			if withMetadata {
+				outputOpts.metadata = newOutputMetadata(from, to, scopes, users, outputOpts.now)
+				outputOpts.metadata.Retries = newOutputRetries(retryStats.Drain())
+			}
+			if withSummary {
+				// Like the totals, the summary covers every repository, not just the ones shown.
+				outputOpts.summary = newOutputSummary(usecase.Summarize(domainResults), leadTimeUnit)
+			}
+			// The totals below still cover every repository, not just the ones shown.
+			shownResults := limitResults(sortResults(filterMinActivity(domainResults, minCommits, minPRs), sortBy), limit)
+			outputResults := buildOutputResults(shownResults, outputOpts)
+			if totals {
+				// The total row is appended after sorting so it always comes last.
+				outputResults = append(outputResults, buildOutputResults([]*domain.RepoStats{usecase.Totals(domainResults)}, outputOpts)...)
+			}
+			return shownResults, outputResults, outputOpts
+		}
+
+		if watch > 0 {
+			// Ctrl-C ends the loop, aborting a refresh in flight, instead of killing the process mid-write.
+			watchCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			redraw := format == formatTable && isTerminal(os.Stdout)
+			watchLoop(watchCtx, watch, time.After, func(ctx context.Context) {
+				if timeout > 0 {
+					var cancel context.CancelFunc
+					ctx, cancel = context.WithTimeout(ctx, timeout)
+					defer cancel()
+				}
+				domainResults, err := aggregate(ctx)
+				if err != nil {
+					// A failed refresh keeps the previous output on screen; the next one may succeed.
+					if watchCtx.Err() == nil {
+						fmt.Fprintln(os.Stderr, describeAggregateError(err, timeout))
+					}
+					return
+				}
+				if sqlitePath != "" {
+					if err := writeSQLite(ctx, sqlitePath, time.Now().In(loc).Format("2006-01-02"), scope, domainResults); err != nil {
+						fmt.Fprintf(os.Stderr, "Failed to write SQLite database: %v\n", err)
+					}
+				}
+				shownResults, outputResults, outputOpts := buildOutput(domainResults)
+				outputOpts.color = useColor(colorMode, os.Stdout, os.Getenv)
+				if redraw {
+					clearScreen(os.Stdout)
+				}
+				if err := writeResults(os.Stdout, format, shownResults, outputResults, outputOpts); err != nil {
+					fmt.Fprintf(os.Stderr, "Failed to write results: %v\n", err)
+				}
+			})
+			return
+		}
+
+		domainResults, err := aggregate(ctx)
+		if !quiet {
+			writeRateLimitSleeps(os.Stderr, &sleeps)
+		}
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to marshal results to JSON: %v\n", err)
+			fmt.Fprintln(os.Stderr, describeAggregateError(err, timeout))
 			os.Exit(1)
 		}
+		if sqlitePath != "" {
+			// The snapshot is taken before --limit, so the database always has every repository.
+			if err := writeSQLite(ctx, sqlitePath, runStart.Format("2006-01-02"), scope, domainResults); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write SQLite database: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		shownResults, outputResults, outputOpts := buildOutput(domainResults)
+
+		if outputDir != "" {
+			if err := writeOutputDir(outputDir, outputResults); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to write output directory: %v\n", err)
+				os.Exit(1)
+			}
+			if explain {
+				writeExplanationOrExit(os.Stderr, outputResults, scope, aggregateOpts)
+			}
+			recordLastRun(lastRunPath, runStart)
+			exitIfEmpty(failOnEmpty, domainResults)
+			return
+		}
 
-		fmt.Println(string(jsonData))
+		out, err := openOutput(outputPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to open output: %v\n", err)
+			os.Exit(1)
+		}
+		outputOpts.color = useColor(colorMode, out, os.Getenv)
+		if reposOnly {
+			// Without an explicit --format the list is plain text; --format json asks for a JSON array.
+			err = writeRepoList(out, activeRepos(shownResults), cmd.Flags().Changed("format"))
+		} else {
+			err = writeResults(out, format, shownResults, outputResults, outputOpts)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write results: %v\n", err)
+			os.Exit(1)
+		}
+		if err := out.Close(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to write output: %v\n", err)
+			os.Exit(1)
+		}
+		if explain {
+			writeExplanationOrExit(os.Stderr, outputResults, scope, aggregateOpts)
+		}
+		recordLastRun(lastRunPath, runStart)
+		exitIfEmpty(failOnEmpty, domainResults)
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(statsCmd)
-	statsCmd.PersistentFlags().StringP("org", "o", "", "Target GitHub organization name (required)")
-	statsCmd.PersistentFlags().StringP("user", "u", "", "Target GitHub user name (required)")
-	statsCmd.MarkPersistentFlagRequired("org")
-	statsCmd.MarkPersistentFlagRequired("user")
+	statsCmd.PersistentFlags().StringSliceP("org", "o", nil, "Target GitHub organization names, comma-separated or repeated (required unless --repo is given)")
+	statsCmd.PersistentFlags().StringSliceP("user", "u", nil, "Target GitHub user names, comma-separated or repeated (required unless --team is given)")
+	statsCmd.Flags().String("repo", "", "Limit the searches to this owner/name repository instead of organizations; --org is then ignored")
+	statsCmd.MarkFlagsOneRequired("org", "repo")
+	statsCmd.Flags().StringSlice("team", nil, "Also aggregate every member of these teams, given as org/team-slug; comma-separated or repeated")
+	statsCmd.MarkFlagsOneRequired("user", "team")
 	statsCmd.Flags().String("from", "", "Start date for stats (YYYY/MM/DD)")
 	statsCmd.Flags().String("to", "", "End date for stats (YYYY/MM/DD)")
-	statsCmd.Flags().Bool("lead-time", true, "Calculate and include PR review lead time percentiles (slower)")
+	statsCmd.Flags().String("last", "", "Aggregate the period ending now, e.g. 7d, 4w or 3mo (cannot be combined with --from/--to)")
+	statsCmd.Flags().String("granularity", "", "Also split the counts into a weekly or monthly time series (needs a start date)")
+	statsCmd.Flags().Bool("heatmap", false, "Include the user's commits per weekday and hour in --timezone as a 7x24 matrix, Sunday first")
+	statsCmd.Flags().Bool("fail-on-empty", false, fmt.Sprintf("Exit with status %d when no activity was found, e.g. to catch a misconfigured token in CI", exitCodeEmpty))
+	statsCmd.Flags().Bool("skip-validation", false, "Don't check that the organizations and users exist before aggregating")
+	statsCmd.Flags().Bool("since-last-run", false, "Start from the last successful --since-last-run of the same orgs, users and teams (7 days back on the first run)")
+	statsCmd.Flags().String("period", "", "Aggregate a named period ("+strings.Join(periodPresets, ", ")+"); weeks start on Monday")
+	statsCmd.Flags().String("timezone", "UTC", "IANA time zone the dates are interpreted in (e.g. Asia/Tokyo)")
+	statsCmd.Flags().Bool("lead-time", true, "Calculate and include PR review lead time percentiles; fetching the reviews of every created PR is the slowest part of a run")
+	statsCmd.Flags().Bool("no-lead-time", false, "Skip the lead time percentiles for a faster run; the same as --lead-time=false")
+	statsCmd.MarkFlagsMutuallyExclusive("lead-time", "no-lead-time")
+	statsCmd.Flags().String("color", colorAuto, "Bold the highest counts in --format table: auto (only on a terminal without NO_COLOR), always or never")
+	statsCmd.Flags().Int("max-name-width", defaultMaxNameWidth, "Truncate repository names longer than this in --format table (0 never truncates)")
+	statsCmd.Flags().Int("limit", 0, "Show only the first N repositories in the --sort-by order, e.g. the top N by commits (0 for no limit)")
+	statsCmd.Flags().String("sort-by", sortByName, fmt.Sprintf("Order the repositories by this `metric`, highest first, or by name (one of %s)", strings.Join(sortByValues(), ", ")))
+	statsCmd.Flags().Int("min-commits", 0, "Hide repositories with fewer commits, unless they pass --min-prs (0 for no threshold)")
+	statsCmd.Flags().Int("min-prs", 0, "Hide repositories with fewer created plus reviewed PRs, unless they pass --min-commits (0 for no threshold)")
+	statsCmd.Flags().Bool("totals", false, "Append a TOTAL row summing every repository")
+	statsCmd.Flags().Int("page-size", 0, "Results per page for every search query, 1-100 (default 100 for counts and 20 for per-PR queries)")
+	statsCmd.Flags().String("base-url", "", "GitHub Enterprise Server URL, e.g. https://github.example.com (default $GITHUB_BASE_URL, then the $GITHUB_API_URL set by GitHub Actions, or github.com)")
+	statsCmd.Flags().String("proxy", "", "Send all requests through this proxy `URL` (default $HTTPS_PROXY, $HTTP_PROXY and $NO_PROXY)")
+	statsCmd.Flags().Bool("debug-http", false, "Print every API request with its status and duration to stderr, with the token redacted")
+	statsCmd.Flags().String("ca-cert", "", "Also trust the PEM CA certificates in this `file`, e.g. the internal CA of a GitHub Enterprise Server")
+	statsCmd.Flags().Bool("insecure-skip-verify", false, "Do not verify TLS certificates (insecure; prefer --ca-cert)")
+	statsCmd.Flags().String("token-file", "", "Read the GitHub token from this `file` instead of GITHUB_TOKEN or \"gh auth token\"")
+	statsCmd.Flags().Int("concurrency", usecase.DefaultConcurrency, "Run at most this many API calls at once; lower it if GitHub reports secondary rate limits")
+	statsCmd.Flags().Int("max-retries", gateway.DefaultMaxRetries, "Retry requests failing with a server or network error this many times")
+	statsCmd.Flags().Duration("retry-base-delay", gateway.DefaultRetryBaseDelay, "Delay before the first retry; it doubles with every further retry")
+	statsCmd.Flags().Duration("watch", 0, "Refresh the report every `interval`, e.g. 5m, until interrupted; the table is redrawn in place on a terminal")
+	statsCmd.Flags().Duration("max-sleep", gateway.DefaultMaxSleep, "Wait out secondary rate limits up to this long; a request hitting a longer one fails instead (0 never waits)")
+	statsCmd.Flags().Duration("timeout", 0, "Abort if the run takes longer than this, e.g. 10m (0 for no limit)")
+	statsCmd.Flags().Duration("cache-ttl", 0, "Reuse cached API results younger than this, e.g. 15m (0, the default, disables the cache)")
+	statsCmd.Flags().Bool("no-cache", false, "Always query the API instead of using cached results")
+	statsCmd.Flags().Bool("strict", false, "Fail instead of warning when the results may be incomplete")
+	statsCmd.Flags().Bool("show-rate-limit", false, "Print the remaining API rate limit to stderr after the run")
+	statsCmd.Flags().Bool("dry-run", false, "Print the search queries to stderr instead of running them")
+	statsCmd.Flags().Bool("explain", false, "Print what each metric in the output counts, and the search query behind it, to stderr after the results")
+	statsCmd.Flags().Bool("best-effort", false, "Report the available data when some fetches fail; fail only if all of them do")
+	statsCmd.Flags().Bool("no-commits", false, "Skip fetching commit counts")
+	statsCmd.Flags().Bool("merged-commits-only", false, "Count only commits on the user's merged PRs, filtered by PR creation date (slower)")
+	statsCmd.Flags().String("commits-source", gateway.CommitsSourceREST, "Count commits with the REST commit search (rest) or the default branch histories of every org repository (graphql)")
+	statsCmd.Flags().Bool("dedupe-commits", false, "Count every commit SHA only once per repository, even when the search returns it repeatedly")
+	statsCmd.Flags().Bool("no-pr-counts", false, "Skip fetching created PR counts")
+	statsCmd.Flags().Bool("merged-prs", false, "Include the number of created PRs that have been merged and their merge rate (one more search)")
+	statsCmd.Flags().Bool("drafts", false, "Include the number of created PRs that are still drafts (one more search)")
+	statsCmd.Flags().Bool("exclude-drafts", false, "Leave draft PRs out of created_prs and report them as draft_prs instead (one more search)")
+	statsCmd.Flags().Bool("no-reviews", false, "Skip fetching reviewed PR counts")
+	statsCmd.Flags().String("format", formatJSON, "Output format ("+strings.Join(supportedFormats, ", ")+")")
+	statsCmd.Flags().Bool("compact", false, "Write --format json on a single line instead of pretty-printed, e.g. when piping to other tools; ignored by the other formats")
+	statsCmd.Flags().StringSlice("percentiles", defaultPercentiles, "Comma-separated lead time percentiles to report, each in (0,100] (e.g. 50,90,99.9)")
+	statsCmd.Flags().StringSlice("review-states", gateway.DefaultReviewStates, "Review states that count as a review for the lead times, e.g. APPROVED for the lead time to approval")
+	statsCmd.Flags().Bool("handle-reopens", false, "Measure lead time from a PR's latest reopen instead of its creation")
+	statsCmd.Flags().Bool("histogram", false, "Also report how many PRs fall into each lead time bucket")
+	statsCmd.Flags().StringSlice("histogram-buckets", defaultHistogramBuckets, "Comma-separated bucket edges in hours for --histogram, in increasing order")
+	statsCmd.Flags().String("lead-time-halflife", "", "Also report lead time weighted by recency with this half-life (e.g. 30d)")
+	statsCmd.Flags().String("lead-time-unit", unitHours, "Unit for lead time values and their JSON keys (seconds, minutes, hours, days)")
+	statsCmd.Flags().Bool("assigned", false, "Include the PRs assigned to the user, e.g. to count triage and ownership work")
+	statsCmd.Flags().Bool("open", false, "Include the user's PRs that are open now; the date range doesn't apply to them")
+	statsCmd.Flags().Bool("issues", false, "Include the issues the user opened and the closed issues assigned to the user")
+	statsCmd.Flags().Bool("review-comments", false, "Include the number of comments the user left in reviews (slower)")
+	statsCmd.Flags().Bool("approvals", false, "Include the number of PRs the user approved, not counting comment-only or changes-requested reviews (slower)")
+	statsCmd.Flags().Bool("exclude-bots", false, "Don't count reviews of PRs authored by bots: GitHub Apps, logins ending in [bot] and --bot-logins")
+	statsCmd.Flags().StringSlice("bot-logins", nil, "Additional logins treated as bots by --exclude-bots, comma-separated or repeated")
+	statsCmd.Flags().Bool("with-metadata", false, "Write a JSON object with the results under \"results\" and the resolved date range, orgs, users, generation time and retries under \"metadata\"")
+	statsCmd.Flags().Bool("with-summary", false, "Write a JSON object with the results under \"results\" and the repos touched, total commits and PRs, org-wide lead time P50/P90 and most active repo under \"summary\"")
+	statsCmd.Flags().Bool("repos-only", false, "Print only the sorted names of the repositories with any activity, one per line, or as a JSON array with --format json")
+	statsCmd.Flags().Bool("anonymize", false, "Replace repository names with the first 8 hex characters of their SHA-256, e.g. to share the results externally; colliding names get the full hash, which depends on the repositories in the run")
+	statsCmd.Flags().Bool("reviewer-latency", false, "Include percentiles of the time from a PR becoming ready for review to the user's first review of it (slower)")
+	statsCmd.Flags().Bool("author-wait", false, "Include percentiles of the time from the user opening a PR to its first review by someone else (slower)")
+	statsCmd.Flags().Bool("commit-churn", false, "Include the lines added and deleted by the user's commits, from each repository's weekly contributor statistics (one extra request per repository)")
+	statsCmd.Flags().Bool("churn", false, "Include the lines added and deleted by the user's PRs and the median and percentiles of their size (slower)")
+	statsCmd.Flags().Bool("comment-stats", false, "Include the number of PRs the user commented on")
+	statsCmd.Flags().StringP("output", "O", "", "Write the results to this file instead of stdout (\"-\" for stdout)")
+	statsCmd.Flags().String("sqlite", "", "Also upsert the results into the repo_stats table of this SQLite database `file`, one snapshot per day, org, user and repository")
+	statsCmd.Flags().String("output-dir", "", "Write each repository's JSON to <dir>/<owner>__<repo>.json instead of stdout")
+	statsCmd.Flags().StringArray("include-repo", nil, "Only report repositories matching this glob on owner/name (e.g. org/*); repeatable")
+	statsCmd.Flags().StringArray("label", nil, "Only count created, merged and reviewed PRs and lead times of PRs carrying this label; repeatable, a PR must carry every given label")
+	statsCmd.Flags().StringArray("exclude-repo", nil, "Leave out repositories matching this glob on owner/name; repeatable, wins over --include-repo")
+	statsCmd.Flags().Bool("share", false, "Include each repository's all-author totals and the user's share of them (slower)")
+	statsCmd.Flags().Bool("with-node-ids", false, "Include each repository's GraphQL node ID")
+	statsCmd.Flags().Bool("languages", false, "Include each repository's primary language (one extra query per 20 repositories)")
+}
+
+// normalizeNames trims the user or organization names given to flag and drops duplicates while keeping their order.
+// GitHub logins are case-insensitive, so names differing only in case are duplicates.
+func normalizeNames(flag string, names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	normalized := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("%s must not contain empty names", flag)
+		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		normalized = append(normalized, name)
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("at least one %s is required", flag)
+	}
+	return normalized, nil
+}
+
+// validateRepoName returns an error when repo is not of the form owner/name.
+func validateRepoName(repo string) error {
+	owner, name, ok := strings.Cut(repo, "/")
+	if !ok || owner == "" || name == "" || strings.Contains(name, "/") || strings.ContainsAny(repo, " \t,") {
+		return fmt.Errorf("invalid repository name %q, expected owner/name", repo)
+	}
+	return nil
+}
+
+// outputOptions controls which optional blocks buildOutputResults fills in.
+type outputOptions struct {
+	calculateLeadTime bool
+	percentiles       []float64
+	halfLife          time.Duration
+	// histogramBuckets are the --histogram-buckets edges in hours; nil leaves out the histogram.
+	histogramBuckets []float64
+	commentStats     bool
+	churn            bool
+	reviewComments   bool
+	approvals        bool
+	drafts           bool
+	mergedPRs        bool
+	issues           bool
+	share            bool
+	unit             string
+	now              time.Time
+	// maxNameWidth truncates repository names in --format table; 0 keeps them whole.
+	maxNameWidth int
+	// color highlights the highest counts in --format table with ANSI escape codes.
+	color bool
+	// reviewerLatency adds the --reviewer-latency percentiles.
+	reviewerLatency bool
+	// commitChurn adds the --commit-churn line counts.
+	commitChurn bool
+	// assigned adds the --assigned PR counts.
+	assigned bool
+	// compact writes --format json on a single line instead of pretty-printed.
+	compact bool
+	// openPRs adds the --open PR counts.
+	openPRs bool
+	// authorWait adds the --author-wait percentiles.
+	authorWait bool
+	// metadata wraps the JSON results in an object with this --with-metadata block; nil writes the bare array.
+	metadata *outputMetadata
+	// summary wraps the JSON results in an object with this --with-summary block, like metadata.
+	summary *outputSummary
+}
+
+// buildOutputResults converts the aggregated domain results into the output structure.
+func buildOutputResults(domainResults []*domain.RepoStats, opts outputOptions) []OutputRepoStats {
+	outputResults := make([]OutputRepoStats, 0, len(domainResults))
+	for _, repoStat := range domainResults {
+		outputStat := OutputRepoStats{
+			Name:            repoStat.Name,
+			User:            repoStat.User,
+			NodeID:          repoStat.NodeID,
+			PrimaryLanguage: repoStat.PrimaryLanguage,
+			Commits:         repoStat.Commits,
+			CreatedPRs:      repoStat.CreatedPRs,
+			ReviewedPRs:     repoStat.ReviewedPRs,
+			Series:          repoStat.Series,
+			CommitHeatmap:   repoStat.CommitHeatmap,
+			unit:            opts.unit,
+		}
+
+		// Calculate percentiles if lead time data is available.
+		if opts.calculateLeadTime && len(repoStat.LeadTimeToLastReviewSeconds) > 0 {
+			outputStat.AnalyzedPRCount = len(repoStat.LeadTimeToLastReviewSeconds)
+			outputStat.LeadTimePercentiles = calculateLeadTimePercentiles(repoStat.LeadTimeToLastReviewSeconds, opts.percentiles, opts.unit)
+			outputStat.TimeToFirstReviewPercentiles = calculateLeadTimePercentiles(repoStat.TimeToFirstReviewSeconds, opts.percentiles, opts.unit)
+			if opts.halfLife > 0 {
+				outputStat.WeightedLeadTime = calculateWeightedLeadTime(repoStat.PullRequests, opts.halfLife, opts.now, opts.unit)
+			}
+			if opts.histogramBuckets != nil {
+				outputStat.LeadTimeHistogram = calculateLeadTimeHistogram(repoStat.LeadTimeToLastReviewSeconds, opts.histogramBuckets)
+			}
+		}
+		if opts.calculateLeadTime && len(repoStat.MergeTimeSeconds) > 0 {
+			outputStat.MergeTimePercentiles = calculateLeadTimePercentiles(repoStat.MergeTimeSeconds, opts.percentiles, opts.unit)
+		}
+		if opts.commentStats {
+			outputStat.CommentedPRs = &repoStat.CommentedPRs
+		}
+		if opts.reviewComments {
+			outputStat.ReviewComments = &repoStat.ReviewComments
+		}
+		if opts.mergedPRs {
+			outputStat.MergedPRs = &repoStat.MergedPRs
+			mergeRate := calculateMergeRate(repoStat.MergedPRs, repoStat.CreatedPRs)
+			outputStat.MergeRate = &mergeRate
+		}
+		if opts.drafts {
+			outputStat.DraftPRs = &repoStat.DraftPRs
+		}
+		if opts.approvals {
+			outputStat.ApprovalsGiven = &repoStat.ApprovalsGiven
+		}
+		if opts.assigned {
+			outputStat.AssignedPRs = &repoStat.AssignedPRs
+		}
+		if opts.openPRs {
+			outputStat.OpenPRs = &repoStat.OpenPRs
+		}
+		if opts.issues {
+			outputStat.CreatedIssues = &repoStat.CreatedIssues
+			outputStat.ClosedIssues = &repoStat.ClosedIssues
+		}
+		if opts.commitChurn {
+			outputStat.CommitAdditions = &repoStat.CommitAdditions
+			outputStat.CommitDeletions = &repoStat.CommitDeletions
+		}
+		if opts.reviewerLatency && len(repoStat.ReviewerLatencySeconds) > 0 {
+			outputStat.ReviewerLatencyPercentiles = calculateLeadTimePercentiles(repoStat.ReviewerLatencySeconds, opts.percentiles, opts.unit)
+		}
+		if opts.authorWait && len(repoStat.AuthorWaitSeconds) > 0 {
+			outputStat.AuthorWaitPercentiles = calculateLeadTimePercentiles(repoStat.AuthorWaitSeconds, opts.percentiles, opts.unit)
+		}
+		if opts.churn {
+			outputStat.TotalAdditions = &repoStat.TotalAdditions
+			outputStat.TotalDeletions = &repoStat.TotalDeletions
+			outputStat.MedianPRSizeLines = &repoStat.MedianPRSizeLines
+			outputStat.PRSizePercentiles = calculatePRSizePercentiles(repoStat.PRSizeLines)
+		}
+
+		if opts.share {
+			outputStat.Share = &ShareStats{
+				OrgCommits:        repoStat.OrgCommits,
+				OrgCreatedPRs:     repoStat.OrgCreatedPRs,
+				CommitsPercent:    percentOf(repoStat.Commits, repoStat.OrgCommits),
+				CreatedPRsPercent: percentOf(repoStat.CreatedPRs, repoStat.OrgCreatedPRs),
+			}
+		}
+		outputResults = append(outputResults, outputStat)
+	}
+	return outputResults
+}
+
+// calculateLeadTimePercentiles converts lead times in seconds into the requested percentiles in unit (hours when empty),
+// plus the minimum and maximum that percentiles hide. It returns nil for no lead times, so the block is omitted.
+// stats.Percentile uses linear interpolation between the closest ranks (the NIST/Excel/NumPy default)
+// on a sorted copy of the data, so the result depends only on the values and not on their order.
+func calculateLeadTimePercentiles(seconds []float64, percentiles []float64, unit string) LeadTimePercentiles {
+	if len(seconds) == 0 {
+		return nil
+	}
+	unit = unitOrDefault(unit)
+	data := stats.Float64Data(seconds)
+	result := make(LeadTimePercentiles, len(percentiles)+2)
+	for _, p := range percentiles {
+		value, _ := stats.Percentile(data, p)
+		result[percentileKey(p, unit)] = value / unitSecondsPerUnit[unit]
+	}
+	minimum, _ := stats.Min(data)
+	maximum, _ := stats.Max(data)
+	result["min_"+unit] = minimum / unitSecondsPerUnit[unit]
+	result["max_"+unit] = maximum / unitSecondsPerUnit[unit]
+	return result
+}
+
+// calculatePRSizePercentiles returns the prSizePercentiles of the PR sizes in lines, or nil for no PRs.
+func calculatePRSizePercentiles(lines []float64) PRSizePercentiles {
+	if len(lines) == 0 {
+		return nil
+	}
+	data := stats.Float64Data(lines)
+	result := make(PRSizePercentiles, len(prSizePercentiles))
+	for _, p := range prSizePercentiles {
+		value, _ := stats.Percentile(data, p)
+		result["p"+strconv.FormatFloat(p, 'f', -1, 64)+"_lines"] = value
+	}
+	return result
+}
+
+// percentileKey returns the output key for a percentile, e.g. "p99.9_hours".
+func percentileKey(p float64, unit string) string {
+	return "p" + strconv.FormatFloat(p, 'f', -1, 64) + "_" + unitOrDefault(unit)
+}
+
+// parsePercentiles parses the values of --percentiles, each of which must be in (0, 100].
+func parsePercentiles(values []string) ([]float64, error) {
+	percentiles := make([]float64, 0, len(values))
+	for _, value := range values {
+		p, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || p <= 0 || p > 100 {
+			return nil, fmt.Errorf("invalid percentile %q: must be a number greater than 0 and at most 100", value)
+		}
+		percentiles = append(percentiles, p)
+	}
+	if len(percentiles) == 0 {
+		return nil, fmt.Errorf("--percentiles must not be empty")
+	}
+	return percentiles, nil
+}
+
+// reportCaveat reports a condition that may make the results incomplete.
+// It is a warning by default; with --strict the command fails instead, so unattended reports are never silently wrong.
+func reportCaveat(w io.Writer, strict bool, format string, args ...interface{}) {
+	if strict {
+		fmt.Fprintf(w, "Error: "+format+" (--strict)\n", args...)
+		os.Exit(1)
+	}
+	fmt.Fprintf(w, "Warning: "+format+"\n", args...)
+}
+
+// exitCodeEmpty is the exit status of --fail-on-empty, kept apart from the status 1 of errors.
+const exitCodeEmpty = 2
+
+// hasActivity reports whether any repository has a non-zero count of the user's own activity.
+func hasActivity(results []*domain.RepoStats) bool {
+	return slices.ContainsFunc(results, (*domain.RepoStats).HasActivity)
+}
+
+// describeEmpty explains why --fail-on-empty fails, or returns "" when there is activity.
+func describeEmpty(results []*domain.RepoStats) string {
+	if len(results) == 0 {
+		return "no repositories with activity were found (the queries succeeded but returned nothing)"
+	}
+	if !hasActivity(results) {
+		return "every repository found has zero counts (the queries succeeded)"
+	}
+	return ""
+}
+
+// exitIfEmpty exits with exitCodeEmpty under --fail-on-empty when the results have no activity.
+// It runs after the results are written, so they can still be inspected.
+func exitIfEmpty(failOnEmpty bool, results []*domain.RepoStats) {
+	if !failOnEmpty {
+		return
+	}
+	if reason := describeEmpty(results); reason != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s (--fail-on-empty)\n", reason)
+		os.Exit(exitCodeEmpty)
+	}
+}
+
+// writeDryRun prints the search queries a run would make, one per user and fetch.
+// Team members are only known after an API call, so teams are listed without resolving them.
+func writeDryRun(w io.Writer, org string, users, teams []string, opts usecase.Options) {
+	for _, user := range users {
+		for _, query := range usecase.PlannedQueries(org, user, opts) {
+			fmt.Fprintf(w, "%s (%s): %s\n", query.Name, user, query.Query)
+		}
+	}
+	for _, team := range teams {
+		fmt.Fprintf(w, "team %s: members are resolved when the command runs\n", team)
+	}
+}
+
+// fetchFailureLog records the fetches that failed in --best-effort mode.
+type fetchFailureLog struct {
+	mu       sync.Mutex
+	failures []string
+}
+
+// add is a usecase.Options.OnFetchError callback.
+func (l *fetchFailureLog) add(user, fetch string, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures = append(l.failures, fmt.Sprintf("%s for %s (%v)", fetch, user, err))
+}
+
+// reset forgets the failures, so the next --watch refresh only reports its own.
+func (l *fetchFailureLog) reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.failures = nil
+}
+
+// summary lists the failures in a stable order, or returns "" when there were none.
+func (l *fetchFailureLog) summary() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sorted := append([]string(nil), l.failures...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, "; ")
+}
+
+// Supported values for the --log-format flag.
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// newLogger returns the progress logger, which writes to w only in verbose mode.
+// In the json format every line is a JSON object with the level, time and message.
+func newLogger(w io.Writer, verbose bool, format string) (*log.Logger, error) {
+	if !verbose {
+		w = io.Discard
+	}
+	switch format {
+	case logFormatText:
+		return log.New(w, "", log.LstdFlags), nil
+	case logFormatJSON:
+		handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+			ReplaceAttr: func(groups []string, attr slog.Attr) slog.Attr {
+				if len(groups) == 0 && attr.Key == slog.MessageKey {
+					attr.Key = "message"
+				}
+				return attr
+			},
+		})
+		return slog.NewLogLogger(handler, slog.LevelInfo), nil
+	}
+	return nil, fmt.Errorf("unsupported log format %q (valid: %s, %s)", format, logFormatText, logFormatJSON)
+}
+
+// showProgress reports whether the spinner is shown: only for people watching a terminal,
+// and neither with --quiet nor with --verbose, whose logging already shows every step.
+func showProgress(verbose, quiet bool, stdout, stderr *os.File) bool {
+	return !verbose && !quiet && isTerminal(stdout) && isTerminal(stderr)
+}
+
+// caveatWriter returns where caveats are reported. --quiet drops the warnings,
+// but with --strict they are errors and are always shown.
+func caveatWriter(stderr io.Writer, quiet, strict bool) io.Writer {
+	if quiet && !strict {
+		return io.Discard
+	}
+	return stderr
+}
+
+// describeDateRange renders the concrete period that is queried, with "open" for an unbounded side.
+func describeDateRange(from, to time.Time) string {
+	if from.IsZero() && to.IsZero() {
+		return "all time"
+	}
+	bound := func(t time.Time) string {
+		if t.IsZero() {
+			return "open"
+		}
+		return t.Format("2006-01-02")
+	}
+	return fmt.Sprintf("%s to %s", bound(from), bound(to))
+}
+
+// parseLast returns the start of a --last period such as "7d", "4w" or "3mo" that ends at now.
+// Months are calendar months and follow time.AddDate's normalization.
+func parseLast(value string, now time.Time) (time.Time, error) {
+	invalid := fmt.Errorf("invalid --last value %q: must be a positive number followed by d, w or mo (e.g. 30d)", value)
+	var months, days int
+	n, suffix := value, ""
+	for _, candidate := range []string{"mo", "d", "w"} {
+		if trimmed, ok := strings.CutSuffix(value, candidate); ok {
+			n, suffix = trimmed, candidate
+			break
+		}
+	}
+	count, err := strconv.Atoi(n)
+	if err != nil || count <= 0 {
+		return time.Time{}, invalid
+	}
+	switch suffix {
+	case "d":
+		days = count
+	case "w":
+		days = 7 * count
+	case "mo":
+		months = count
+	default:
+		return time.Time{}, invalid
+	}
+	return now.AddDate(0, -months, -days), nil
+}
+
+// periodPresets lists the valid --period values.
+var periodPresets = []string{"today", "yesterday", "this-week", "last-week", "this-month", "last-month", "this-quarter", "this-year"}
+
+// parsePeriod expands a --period preset into the first and last day it covers, relative to now.
+// The current week, month, quarter and year end today; weeks start on Monday.
+func parsePeriod(name string, now time.Time) (from, to time.Time, err error) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	// time.Sunday is 0, so shift the weekday to make Monday the first day of the week.
+	monday := today.AddDate(0, 0, -(int(today.Weekday())+6)%7)
+	firstOfMonth := today.AddDate(0, 0, 1-today.Day())
+
+	switch name {
+	case "today":
+		return today, today, nil
+	case "yesterday":
+		yesterday := today.AddDate(0, 0, -1)
+		return yesterday, yesterday, nil
+	case "this-week":
+		return monday, today, nil
+	case "last-week":
+		return monday.AddDate(0, 0, -7), monday.AddDate(0, 0, -1), nil
+	case "this-month":
+		return firstOfMonth, today, nil
+	case "last-month":
+		return firstOfMonth.AddDate(0, -1, 0), firstOfMonth.AddDate(0, 0, -1), nil
+	case "this-quarter":
+		quarterStartMonth := time.Month((int(today.Month())-1)/3*3 + 1)
+		return time.Date(today.Year(), quarterStartMonth, 1, 0, 0, 0, 0, today.Location()), today, nil
+	case "this-year":
+		return time.Date(today.Year(), time.January, 1, 0, 0, 0, 0, today.Location()), today, nil
+	}
+	return time.Time{}, time.Time{}, fmt.Errorf("unknown --period %q (valid: %s)", name, strings.Join(periodPresets, ", "))
+}
+
+// describeAggregateError returns the message for a failed aggregation, calling out an expired --timeout.
+func describeAggregateError(err error, timeout time.Duration) string {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Sprintf("Error: operation timed out after %s", timeout)
+	}
+	return fmt.Sprintf("Failed to aggregate stats: %v", err)
+}
+
+// validateTargets returns an error naming the first organization or user that doesn't exist.
+func validateTargets(ctx context.Context, fetcher gateway.Fetcher, orgs, users []string) error {
+	for _, org := range orgs {
+		exists, err := fetcher.OrgExists(ctx, org)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("organization '%s' not found", org)
+		}
+	}
+	for _, user := range users {
+		exists, err := fetcher.UserExists(ctx, user)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("user '%s' not found", user)
+		}
+	}
+	return nil
+}
+
+// writeRateLimit prints the remaining quota of every rate limit resource to w.
+// The run already succeeded, so a failure to read the status is only reported, not fatal.
+func writeRateLimit(ctx context.Context, w io.Writer, fetcher gateway.Fetcher, now time.Time) {
+	limits, err := fetcher.FetchRateLimit(ctx)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: %v\n", err)
+		return
+	}
+	for _, limit := range limits {
+		fmt.Fprintf(w, "Rate limit %s: %d/%d remaining, resets at %s (in %s)\n",
+			limit.Resource, limit.Remaining, limit.Limit, limit.Reset.Format(time.RFC3339), max(limit.Reset.Sub(now), 0).Round(time.Second))
+	}
+}
+
+// writeRateLimitSleeps reports how long the run paused for secondary rate limits, if it did.
+func writeRateLimitSleeps(w io.Writer, sleeps *gateway.RateLimitSleeps) {
+	count, total := sleeps.Summary()
+	if count == 0 {
+		return
+	}
+	pauses := "pauses"
+	if count == 1 {
+		pauses = "pause"
+	}
+	fmt.Fprintf(w, "Waited %s for secondary rate limits in %d %s.\n", total, count, pauses)
+}
+
+// limitResults returns the first limit results, or all of them when limit is 0.
+// It must be applied after sortResults so the kept results are the top ones.
+func limitResults(results []*domain.RepoStats, limit int) []*domain.RepoStats {
+	if limit <= 0 || limit >= len(results) {
+		return results
+	}
+	return results[:limit]
+}
+
+// leadTimeEnabled reports whether the lead times are calculated: --lead-time is on by default,
+// and --no-lead-time turns it off.
+func leadTimeEnabled(flags *pflag.FlagSet) bool {
+	leadTime, _ := flags.GetBool("lead-time")
+	noLeadTime, _ := flags.GetBool("no-lead-time")
+	return leadTime && !noLeadTime
+}
+
+// filterMinActivity keeps the repositories passing at least one of the thresholds: minCommits commits,
+// or minPRs created plus reviewed PRs. A zero threshold is unset and passes nothing, and with both unset
+// every repository is kept.
+func filterMinActivity(results []*domain.RepoStats, minCommits, minPRs int) []*domain.RepoStats {
+	if minCommits == 0 && minPRs == 0 {
+		return results
+	}
+	kept := make([]*domain.RepoStats, 0, len(results))
+	for _, r := range results {
+		if (minCommits > 0 && r.Commits >= minCommits) || (minPRs > 0 && r.CreatedPRs+r.ReviewedPRs >= minPRs) {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
+
+// calculateMergeRate returns the share of created PRs that got merged, between 0 and 1, or 0 when none were created.
+// It is rounded to two decimals: a health signal like this doesn't need more, and it keeps the output readable.
+func calculateMergeRate(merged, created int) float64 {
+	if created == 0 {
+		return 0
+	}
+	return math.Round(float64(merged)/float64(created)*100) / 100
+}
+
+// percentOf returns part as a percentage of total, or 0 when total is 0.
+func percentOf(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(part) / float64(total) * 100
 }
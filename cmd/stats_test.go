@@ -0,0 +1,482 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/naka-gawa/github-stats/internal/gateway"
+	"github.com/naka-gawa/github-stats/internal/usecase"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateLeadTimePercentiles_OrderIndependent(t *testing.T) {
+	// Many identical values exercise the interpolation between equal ranks.
+	ordered := []float64{3600, 3600, 3600, 7200, 7200, 10800, 36000, 36000, 86400}
+	shuffled := []float64{36000, 3600, 86400, 7200, 3600, 36000, 10800, 3600, 7200}
+
+	percentiles := []float64{50, 75, 90, 95, 99}
+	assert.Equal(t, calculateLeadTimePercentiles(ordered, percentiles, ""), calculateLeadTimePercentiles(shuffled, percentiles, ""))
+	assert.Equal(t, 2.0, calculateLeadTimePercentiles(ordered, percentiles, "")["p50_hours"])
+	// The input must not be reordered in place.
+	assert.Equal(t, []float64{36000, 3600, 86400, 7200, 3600, 36000, 10800, 3600, 7200}, shuffled)
+}
+
+func TestDescribeDateRange(t *testing.T) {
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, "all time", describeDateRange(time.Time{}, time.Time{}))
+	assert.Equal(t, "2025-04-01 to 2025-06-30", describeDateRange(from, to))
+	assert.Equal(t, "2025-04-01 to open", describeDateRange(from, time.Time{}))
+	assert.Equal(t, "open to 2025-06-30", describeDateRange(time.Time{}, to))
+}
+
+func TestNormalizeNames(t *testing.T) {
+	users, err := normalizeNames("--user", []string{" alice", "bob ", "Alice"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, users)
+
+	_, err = normalizeNames("--org", []string{"acme", ""})
+	assert.ErrorContains(t, err, "--org must not contain empty names")
+
+	_, err = normalizeNames("--user", nil)
+	assert.ErrorContains(t, err, "at least one --user is required")
+}
+
+func TestValidateRepoName(t *testing.T) {
+	assert.NoError(t, validateRepoName("acme/api"))
+	for _, repo := range []string{"acme", "acme/", "/api", "acme/api/extra", "acme/api,acme/web", "acme/ api"} {
+		assert.Error(t, validateRepoName(repo), repo)
+	}
+}
+
+func TestCalculateLeadTimePercentiles_Configured(t *testing.T) {
+	seconds := make([]float64, 0, 1001)
+	for i := 0; i <= 1000; i++ {
+		seconds = append(seconds, float64(i)*3600)
+	}
+
+	result := calculateLeadTimePercentiles(seconds, []float64{50, 99.9}, "")
+	assert.Len(t, result, 4)
+	assert.InDelta(t, 500, result["p50_hours"], 1e-9)
+	assert.InDelta(t, 999, result["p99.9_hours"], 1e-9)
+}
+
+func TestCalculatePRSizePercentiles(t *testing.T) {
+	assert.Nil(t, calculatePRSizePercentiles(nil))
+
+	// Mostly small PRs with the occasional huge one, which the median alone hides.
+	lines := make([]float64, 0, 100)
+	for i := 0; i < 88; i++ {
+		lines = append(lines, 20)
+	}
+	for i := 0; i < 12; i++ {
+		lines = append(lines, 2000)
+	}
+	result := calculatePRSizePercentiles(lines)
+	assert.Equal(t, PRSizePercentiles{"p50_lines": 20, "p90_lines": 2000, "p99_lines": 2000}, result)
+
+	output := buildOutputResults([]*domain.RepoStats{{Name: "org/repo", PRSizeLines: lines}}, outputOptions{churn: true})
+	assert.Equal(t, result, output[0].PRSizePercentiles)
+}
+
+func TestCalculateLeadTimePercentiles_MinMax(t *testing.T) {
+	// 30 minutes, 2 hours, 1 day and a pathological 10 days.
+	seconds := []float64{7200, 1800, 864000, 86400}
+
+	result := calculateLeadTimePercentiles(seconds, []float64{50}, "")
+	assert.InDelta(t, 0.5, result["min_hours"], 1e-9)
+	assert.InDelta(t, 240, result["max_hours"], 1e-9)
+	assert.Nil(t, calculateLeadTimePercentiles(nil, []float64{50}, ""))
+}
+
+func TestCalculateMergeRate(t *testing.T) {
+	assert.Equal(t, 0.67, calculateMergeRate(2, 3))
+	assert.Equal(t, 1.0, calculateMergeRate(4, 4))
+	// No created PRs gives 0 rather than NaN, which JSON can't encode.
+	assert.Equal(t, 0.0, calculateMergeRate(0, 0))
+}
+
+func TestBuildOutputResults_MergeRate(t *testing.T) {
+	results := buildOutputResults([]*domain.RepoStats{
+		{Name: "org/repo-a", CreatedPRs: 8, MergedPRs: 7},
+		{Name: "org/repo-b"},
+	}, outputOptions{mergedPRs: true})
+
+	data, err := json.Marshal(results)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"name": "org/repo-a", "commits": 0, "created_prs": 8, "merged_prs": 7, "merge_rate": 0.88, "reviewed_prs": 0},
+		{"name": "org/repo-b", "commits": 0, "created_prs": 0, "merged_prs": 0, "merge_rate": 0, "reviewed_prs": 0}
+	]`, string(data))
+}
+
+func TestParsePercentiles(t *testing.T) {
+	percentiles, err := parsePercentiles([]string{"50", " 90", "99.9", "100"})
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{50, 90, 99.9, 100}, percentiles)
+
+	for _, invalid := range []string{"0", "-5", "100.1", "p90", ""} {
+		_, err := parsePercentiles([]string{invalid})
+		assert.Error(t, err, invalid)
+	}
+	_, err = parsePercentiles(nil)
+	assert.Error(t, err)
+}
+
+func TestLimitResults(t *testing.T) {
+	// Results arrive sorted by name from the aggregator, with metrics in no particular order.
+	byName := []*domain.RepoStats{
+		{Name: "org/a", Commits: 1, ReviewedPRs: 5},
+		{Name: "org/b", Commits: 4, ReviewedPRs: 0},
+		{Name: "org/c", Commits: 2, ReviewedPRs: 5},
+		{Name: "org/d", Commits: 4, ReviewedPRs: 1},
+	}
+	testCases := []struct {
+		sortBy   string
+		limit    int
+		expected []string
+	}{
+		{sortBy: sortByName, limit: 2, expected: []string{"org/a", "org/b"}},
+		// Truncation happens after sorting, so the top repositories are kept; ties stay in name order.
+		{sortBy: "commits", limit: 2, expected: []string{"org/b", "org/d"}},
+		{sortBy: "commits", limit: 3, expected: []string{"org/b", "org/d", "org/c"}},
+		{sortBy: "reviewed_prs", limit: 3, expected: []string{"org/a", "org/c", "org/d"}},
+		{sortBy: "commits", limit: 0, expected: []string{"org/b", "org/d", "org/c", "org/a"}},
+		{sortBy: "commits", limit: 10, expected: []string{"org/b", "org/d", "org/c", "org/a"}},
+	}
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("%s limit %d", tc.sortBy, tc.limit), func(t *testing.T) {
+			shown := limitResults(sortResults(byName, tc.sortBy), tc.limit)
+			names := make([]string, len(shown))
+			for i, r := range shown {
+				names[i] = r.Name
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+
+	assert.Equal(t, "org/a", byName[0].Name, "sorting doesn't reorder the full results")
+	// The totals are computed from every repository, not just the shown ones.
+	assert.Equal(t, 11, usecase.Totals(byName).Commits)
+}
+
+func TestValidateSortBy(t *testing.T) {
+	for _, valid := range []string{"name", "commits", "created_prs", "reviewed_prs", "merged_prs"} {
+		assert.NoError(t, validateSortBy(valid), valid)
+	}
+	for _, invalid := range []string{"", "Commits", "lead_time"} {
+		assert.Error(t, validateSortBy(invalid), invalid)
+	}
+}
+
+func TestLeadTimeEnabled(t *testing.T) {
+	testCases := []struct {
+		args     []string
+		expected bool
+	}{
+		{args: nil, expected: true},
+		{args: []string{"--lead-time"}, expected: true},
+		{args: []string{"--lead-time=false"}, expected: false},
+		{args: []string{"--no-lead-time"}, expected: false},
+	}
+	for _, tc := range testCases {
+		t.Run(strings.Join(tc.args, " "), func(t *testing.T) {
+			t.Cleanup(func() { resetFlags(t, statsCmd) })
+			require.NoError(t, statsCmd.ParseFlags(tc.args))
+			assert.Equal(t, tc.expected, leadTimeEnabled(statsCmd.Flags()))
+		})
+	}
+}
+
+func TestStatsCommand_LeadTimeFlagsExclusive(t *testing.T) {
+	_, err := executeCommand(t, "stats", "--org", "org", "--user", "user", "--lead-time", "--no-lead-time", "--dry-run")
+	assert.ErrorContains(t, err, "none of the others can be")
+}
+
+func TestFilterMinActivity(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "org/drive-by", Commits: 1},
+		{Name: "org/commits-only", Commits: 5},
+		{Name: "org/reviews-only", ReviewedPRs: 2, CreatedPRs: 1},
+		{Name: "org/both", Commits: 3, CreatedPRs: 3},
+	}
+
+	// A repository passing either threshold is kept.
+	shown := filterMinActivity(results, 3, 3)
+	assert.Equal(t, []*domain.RepoStats{results[1], results[2], results[3]}, shown)
+
+	// An unset threshold keeps nothing on its own.
+	assert.Equal(t, []*domain.RepoStats{results[1], results[3]}, filterMinActivity(results, 3, 0))
+	assert.Equal(t, []*domain.RepoStats{results[2], results[3]}, filterMinActivity(results, 0, 3))
+	assert.Equal(t, results, filterMinActivity(results, 0, 0))
+
+	// The totals are computed from every repository, not just the shown ones.
+	assert.Equal(t, 9, usecase.Totals(results).Commits)
+}
+
+func TestParseLast(t *testing.T) {
+	now := time.Date(2025, 6, 30, 15, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		value    string
+		expected time.Time
+	}{
+		{value: "7d", expected: time.Date(2025, 6, 23, 15, 0, 0, 0, time.UTC)},
+		{value: "4w", expected: time.Date(2025, 6, 2, 15, 0, 0, 0, time.UTC)},
+		{value: "3mo", expected: time.Date(2025, 3, 30, 15, 0, 0, 0, time.UTC)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			from, err := parseLast(tc.value, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, from)
+		})
+	}
+
+	for _, value := range []string{"3y", "d", "0d", "-2w", "1.5d", ""} {
+		_, err := parseLast(value, now)
+		assert.ErrorContains(t, err, "invalid --last value", value)
+	}
+}
+
+func TestParsePeriod(t *testing.T) {
+	// Wednesday, May 14, 2025.
+	now := time.Date(2025, 5, 14, 15, 30, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2025, month, d, 0, 0, 0, 0, time.UTC) }
+	testCases := []struct {
+		period   string
+		from, to time.Time
+	}{
+		{period: "today", from: day(5, 14), to: day(5, 14)},
+		{period: "yesterday", from: day(5, 13), to: day(5, 13)},
+		{period: "this-week", from: day(5, 12), to: day(5, 14)},
+		{period: "last-week", from: day(5, 5), to: day(5, 11)},
+		{period: "this-month", from: day(5, 1), to: day(5, 14)},
+		{period: "last-month", from: day(4, 1), to: day(4, 30)},
+		{period: "this-quarter", from: day(4, 1), to: day(5, 14)},
+		{period: "this-year", from: day(1, 1), to: day(5, 14)},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.period, func(t *testing.T) {
+			from, to, err := parsePeriod(tc.period, now)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.from, from)
+			assert.Equal(t, tc.to, to)
+		})
+	}
+
+	_, _, err := parsePeriod("next-week", now)
+	assert.ErrorContains(t, err, `unknown --period "next-week" (valid: today, yesterday, this-week`)
+}
+
+func TestParsePeriod_WeekStartsOnMonday(t *testing.T) {
+	// On a Sunday the current week started six days earlier.
+	sunday := time.Date(2025, 5, 18, 9, 0, 0, 0, time.UTC)
+	from, _, err := parsePeriod("this-week", sunday)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 5, 12, 0, 0, 0, 0, time.UTC), from)
+
+	// On a Monday it starts today.
+	from, _, err = parsePeriod("this-week", sunday.AddDate(0, 0, 1))
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 5, 19, 0, 0, 0, 0, time.UTC), from)
+}
+
+func TestDescribeAggregateError(t *testing.T) {
+	timedOut := fmt.Errorf("failed to aggregate stats for user alice: %w", context.DeadlineExceeded)
+	assert.Equal(t, "Error: operation timed out after 30s", describeAggregateError(timedOut, 30*time.Second))
+	assert.Equal(t, "Failed to aggregate stats: boom", describeAggregateError(errors.New("boom"), 0))
+}
+
+func TestQuietWritesNothingToStderr(t *testing.T) {
+	var stderr bytes.Buffer
+
+	logger, err := newLogger(&stderr, false, logFormatText)
+	require.NoError(t, err)
+	logger.Println("Fetching commits...")
+	reportCaveat(caveatWriter(&stderr, true, false), false, "results may be incomplete")
+
+	assert.Empty(t, stderr.String())
+}
+
+func TestNewLogger_JSON(t *testing.T) {
+	var stderr bytes.Buffer
+	logger, err := newLogger(&stderr, true, logFormatJSON)
+	require.NoError(t, err)
+	logger.Println("Fetching commits...")
+	logger.Printf("Resolved date range: %s\n", "all time")
+
+	lines := strings.Split(strings.TrimSuffix(stderr.String(), "\n"), "\n")
+	require.Len(t, lines, 2)
+	for _, line := range lines {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record), line)
+		assert.Equal(t, "INFO", record["level"])
+		assert.NotEmpty(t, record["time"])
+	}
+	assert.Contains(t, lines[0], `"message":"Fetching commits..."`)
+	assert.Contains(t, lines[1], `"message":"Resolved date range: all time"`)
+
+	_, err = newLogger(&stderr, true, "xml")
+	assert.Error(t, err)
+}
+
+func TestDescribeEmpty(t *testing.T) {
+	assert.Contains(t, describeEmpty(nil), "no repositories with activity were found")
+	assert.Contains(t, describeEmpty([]*domain.RepoStats{{Name: "org/repo", OrgCommits: 40}}), "every repository found has zero counts")
+	assert.Empty(t, describeEmpty([]*domain.RepoStats{{Name: "org/repo"}, {Name: "org/other", ReviewedPRs: 1}}))
+	assert.Empty(t, describeEmpty([]*domain.RepoStats{{Name: "org/repo", Commits: 3}}))
+}
+
+func TestCaveatWriter(t *testing.T) {
+	var stderr bytes.Buffer
+
+	reportCaveat(caveatWriter(&stderr, false, false), false, "results may be incomplete")
+	assert.Equal(t, "Warning: results may be incomplete\n", stderr.String())
+	assert.Equal(t, &stderr, caveatWriter(&stderr, true, true), "--strict errors are shown even with --quiet")
+}
+
+func TestVerboseAndQuietAreMutuallyExclusive(t *testing.T) {
+	_, err := executeCommand(t, "stats", "--org", "o", "--user", "u", "--verbose", "--quiet")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[quiet verbose] were all set")
+}
+
+func TestFetchFailureLog(t *testing.T) {
+	var failures fetchFailureLog
+	assert.Empty(t, failures.summary())
+
+	failures.add("bob", "lead times", errors.New("timeout"))
+	failures.add("alice", "reviewed PRs", errors.New("502"))
+	assert.Equal(t, "lead times for bob (timeout); reviewed PRs for alice (502)", failures.summary())
+}
+
+func TestWriteRateLimit(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v3/rate_limit", r.URL.Path)
+		fmt.Fprint(w, `{"resources": {
+			"core": {"limit": 5000, "remaining": 4990, "reset": 1760529600},
+			"search": {"limit": 30, "remaining": 12, "reset": 1760526060}
+		}}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	fetcher, err := gateway.NewGitHubGateway("token", log.New(io.Discard, "", 0), gateway.WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	var stderr bytes.Buffer
+	writeRateLimit(context.Background(), &stderr, fetcher, time.Unix(1760526000, 0))
+
+	core := time.Unix(1760529600, 0).Format(time.RFC3339)
+	search := time.Unix(1760526060, 0).Format(time.RFC3339)
+	assert.Equal(t, "Rate limit core: 4990/5000 remaining, resets at "+core+" (in 1h0m0s)\n"+
+		"Rate limit search: 12/30 remaining, resets at "+search+" (in 1m0s)\n", stderr.String())
+}
+
+func TestWriteRateLimitSleeps(t *testing.T) {
+	var retryAfter atomic.Int32
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		// Every other request hits a one-second secondary rate limit.
+		if retryAfter.Add(1)%2 == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit."}`)
+			return
+		}
+		fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+	var sleeps gateway.RateLimitSleeps
+	fetcher, err := gateway.NewGitHubGateway("token", log.New(io.Discard, "", 0), gateway.WithBaseURL(server.URL), gateway.WithRateLimitSleeps(&sleeps))
+	require.NoError(t, err)
+
+	var stderr bytes.Buffer
+	writeRateLimitSleeps(&stderr, &sleeps)
+	assert.Empty(t, stderr.String(), "nothing is reported without pauses")
+
+	for range 2 {
+		_, err = fetcher.FetchCommits(context.Background(), "org", "user", "")
+		require.NoError(t, err)
+	}
+	writeRateLimitSleeps(&stderr, &sleeps)
+	assert.Equal(t, "Waited 2s for secondary rate limits in 2 pauses.\n", stderr.String())
+}
+
+func TestWriteRateLimit_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	fetcher, err := gateway.NewGitHubGateway("token", log.New(io.Discard, "", 0), gateway.WithBaseURL(server.URL), gateway.WithRetries(0, 0))
+	require.NoError(t, err)
+
+	var stderr bytes.Buffer
+	writeRateLimit(context.Background(), &stderr, fetcher, time.Now())
+	assert.Contains(t, stderr.String(), "Warning: failed to fetch the rate limit status")
+}
+
+func TestValidateTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/orgs/acme", "/api/v3/users/alice":
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	fetcher, err := gateway.NewGitHubGateway("token", log.New(io.Discard, "", 0), gateway.WithBaseURL(server.URL), gateway.WithRetries(0, 0))
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	assert.NoError(t, validateTargets(ctx, fetcher, []string{"acme"}, []string{"alice"}))
+	assert.EqualError(t, validateTargets(ctx, fetcher, []string{"acme", "acne"}, []string{"alice"}), "organization 'acne' not found")
+	assert.EqualError(t, validateTargets(ctx, fetcher, []string{"acme"}, []string{"alice", "alcie"}), "user 'alcie' not found")
+}
+
+func TestUserOrTeamIsRequired(t *testing.T) {
+	_, err := executeCommand(t, "stats", "--org", "o")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "[user team] is required")
+}
+
+func TestWriteDryRun(t *testing.T) {
+	commitDateRange, prDateRange := usecase.DateRangeQualifiers(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC))
+
+	var buf bytes.Buffer
+	writeDryRun(&buf, "acme", []string{"alice"}, []string{"acme/platform"}, usecase.Options{
+		CommitDateRange:   commitDateRange,
+		PRDateRange:       prDateRange,
+		CalculateLeadTime: true,
+	})
+
+	assert.Equal(t, `commits (alice): org:acme author:alice author-date:2025-04-01..2025-04-30
+created PRs (alice): org:acme author:alice is:pr created:2025-04-01..2025-04-30
+reviewed PRs (alice): org:acme reviewed-by:alice is:pr created:2025-04-01..2025-04-30
+lead times (alice): org:acme author:alice is:pr is:closed created:2025-04-01..2025-04-30
+team acme/platform: members are resolved when the command runs
+`, buf.String())
+}
+
+func TestDryRunNeedsNoToken(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("PATH", "") // No gh CLI to fall back to either.
+
+	out, err := executeCommand(t, "stats", "--org", "acme", "--user", "alice", "--dry-run", "--no-reviews")
+	require.NoError(t, err)
+	assert.Empty(t, out, "the queries go to stderr, not to the command output")
+}
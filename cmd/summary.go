@@ -0,0 +1,35 @@
+package cmd
+
+import "github.com/naka-gawa/github-stats/internal/usecase"
+
+// outputSummary is the --with-summary block of cross-repository aggregates.
+type outputSummary struct {
+	ReposTouched int `json:"repos_touched"`
+	Commits      int `json:"commits"`
+	CreatedPRs   int `json:"created_prs"`
+	ReviewedPRs  int `json:"reviewed_prs"`
+	AnalyzedPRs  int `json:"analyzed_prs"`
+	// LeadTimePercentiles holds "p50_hours" and "p90_hours" over every analyzed PR, in the --lead-time-unit.
+	LeadTimePercentiles LeadTimePercentiles `json:"lead_time_percentiles,omitempty"`
+	MostActiveRepo      string              `json:"most_active_repo,omitempty"`
+}
+
+// newOutputSummary converts summary for output, expressing the lead times in unit.
+func newOutputSummary(summary usecase.Summary, unit string) *outputSummary {
+	out := &outputSummary{
+		ReposTouched:   summary.ReposTouched,
+		Commits:        summary.Commits,
+		CreatedPRs:     summary.CreatedPRs,
+		ReviewedPRs:    summary.ReviewedPRs,
+		AnalyzedPRs:    summary.AnalyzedPRs,
+		MostActiveRepo: summary.MostActiveRepo,
+	}
+	if summary.AnalyzedPRs > 0 {
+		unit = unitOrDefault(unit)
+		out.LeadTimePercentiles = LeadTimePercentiles{
+			percentileKey(50, unit): summary.LeadTimeP50Seconds / unitSecondsPerUnit[unit],
+			percentileKey(90, unit): summary.LeadTimeP90Seconds / unitSecondsPerUnit[unit],
+		}
+	}
+	return out
+}
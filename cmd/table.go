@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultMaxNameWidth is the --max-name-width used when the flag is not given.
+const defaultMaxNameWidth = 40
+
+// tableColumn is one column of a text table. Numeric columns are right-aligned,
+// and the largest value of a highlighted column is shown in bold when colors are on.
+type tableColumn struct {
+	header    string
+	numeric   bool
+	highlight bool
+}
+
+// writeTable writes the results as a plain text table aligned for terminals, with the columns of the Markdown table.
+// Repository names longer than maxNameWidth runes are truncated with an ellipsis; 0 keeps them whole.
+// With color, the highest commit and PR counts are bolded.
+func writeTable(w io.Writer, results []OutputRepoStats, withLeadTime bool, unit string, maxNameWidth int, color bool) error {
+	unit = unitOrDefault(unit)
+	withUser := hasMultipleUsers(results)
+	var columns []tableColumn
+	if withUser {
+		columns = append(columns, tableColumn{header: "USER"})
+	}
+	columns = append(columns,
+		tableColumn{header: "REPOSITORY"},
+		tableColumn{header: "COMMITS", numeric: true, highlight: true},
+		tableColumn{header: "CREATED PRS", numeric: true, highlight: true},
+		tableColumn{header: "REVIEWED PRS", numeric: true, highlight: true},
+	)
+	if withLeadTime {
+		abbr := unitAbbreviations[unit]
+		columns = append(columns,
+			tableColumn{header: fmt.Sprintf("P50 (%s)", abbr), numeric: true},
+			tableColumn{header: fmt.Sprintf("P90 (%s)", abbr), numeric: true},
+		)
+	}
+
+	rows := make([][]string, 0, len(results))
+	for _, result := range results {
+		var row []string
+		if withUser {
+			row = append(row, result.User)
+		}
+		row = append(row,
+			truncateName(result.Name, maxNameWidth),
+			strconv.Itoa(result.Commits),
+			strconv.Itoa(result.CreatedPRs),
+			strconv.Itoa(result.ReviewedPRs),
+		)
+		if withLeadTime {
+			for _, p := range []float64{50, 90} {
+				value := "-"
+				if v, ok := result.LeadTimePercentiles[percentileKey(p, unit)]; ok {
+					value = strconv.FormatFloat(v, 'f', 2, 64)
+				}
+				row = append(row, value)
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(columns))
+	for i, column := range columns {
+		widths[i] = utf8.RuneCountInString(column.header)
+	}
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	var highest []string
+	if color {
+		highest = highestCells(columns, rows)
+	}
+
+	var b strings.Builder
+	headers := make([]string, len(columns))
+	for i, column := range columns {
+		headers[i] = column.header
+	}
+	writeTableRow(&b, columns, widths, headers, nil)
+	for _, row := range rows {
+		writeTableRow(&b, columns, widths, row, highest)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeTableRow pads every cell to its column width, separating the columns with two spaces.
+// Cells equal to their column's entry in highest are bolded; the padding is computed before, so they stay aligned.
+// Trailing spaces are trimmed so a left-aligned last column doesn't leave any.
+func writeTableRow(b *strings.Builder, columns []tableColumn, widths []int, cells []string, highest []string) {
+	var line strings.Builder
+	for i, cell := range cells {
+		if i > 0 {
+			line.WriteString("  ")
+		}
+		padding := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
+		if highest != nil && highest[i] != "" && cell == highest[i] {
+			cell = ansiBold + cell + ansiReset
+		}
+		if columns[i].numeric {
+			line.WriteString(padding + cell)
+		} else {
+			line.WriteString(cell + padding)
+		}
+	}
+	b.WriteString(strings.TrimRight(line.String(), " ") + "\n")
+}
+
+// highestCells returns the largest value of every highlighted column, as rendered, or "" for the other columns.
+// Columns whose largest value is 0 get "" too, since bolding every zero would highlight nothing.
+func highestCells(columns []tableColumn, rows [][]string) []string {
+	highest := make([]string, len(columns))
+	for i, column := range columns {
+		if !column.highlight {
+			continue
+		}
+		best := 0
+		for _, row := range rows {
+			if n, err := strconv.Atoi(row[i]); err == nil && n > best {
+				best = n
+			}
+		}
+		if best > 0 {
+			highest[i] = strconv.Itoa(best)
+		}
+	}
+	return highest
+}
+
+// truncateName shortens name to at most width runes, replacing the end with an ellipsis.
+func truncateName(name string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(name) <= width {
+		return name
+	}
+	runes := []rune(name)
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteTable(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", User: "alice", Commits: 12, CreatedPRs: 3, ReviewedPRs: 8, LeadTimePercentiles: LeadTimePercentiles{"p50_hours": 1.5, "p90_hours": 26.25}},
+		{Name: "org/a-repository-with-a-very-long-name", User: "bob", Commits: 1140},
+	}
+	var buf bytes.Buffer
+	require.NoError(t, writeTable(&buf, results, true, "", 24, false))
+
+	expected, err := os.ReadFile(filepath.Join("testdata", "table.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, string(expected), buf.String())
+}
+
+func TestWriteTable_WithoutLeadTime(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeTable(&buf, []OutputRepoStats{{Name: "org/repo-a", Commits: 3}}, false, "", 0, false))
+	assert.Equal(t, "REPOSITORY  COMMITS  CREATED PRS  REVIEWED PRS\n"+
+		"org/repo-a        3            0             0\n", buf.String())
+}
+
+func TestTruncateName(t *testing.T) {
+	assert.Equal(t, "org/repo", truncateName("org/repo", 8))
+	assert.Equal(t, "org/re…", truncateName("org/repo", 7))
+	assert.Equal(t, "org/日本…", truncateName("org/日本語リポジトリ", 7), "widths count runes, not bytes")
+	assert.Equal(t, "org/repo", truncateName("org/repo", 0))
+}
+
+func TestWriteTable_Color(t *testing.T) {
+	results := []OutputRepoStats{
+		{Name: "org/repo-a", Commits: 12, CreatedPRs: 3},
+		{Name: "org/repo-b", Commits: 5, CreatedPRs: 3},
+	}
+
+	var plain bytes.Buffer
+	require.NoError(t, writeTable(&plain, results, false, "", 0, false))
+	assert.NotContains(t, plain.String(), "\x1b[")
+
+	var colored bytes.Buffer
+	require.NoError(t, writeTable(&colored, results, false, "", 0, true))
+	assert.Equal(t, "REPOSITORY  COMMITS  CREATED PRS  REVIEWED PRS\n"+
+		"org/repo-a       \x1b[1m12\x1b[0m            \x1b[1m3\x1b[0m             0\n"+
+		"org/repo-b        5            \x1b[1m3\x1b[0m             0\n", colored.String(),
+		"ties are all bolded, all-zero columns are not, and padding ignores the escape codes")
+}
+
+func TestUseColor(t *testing.T) {
+	noEnv := func(string) string { return "" }
+	var buf bytes.Buffer
+	assert.True(t, useColor(colorAlways, &buf, noEnv))
+	assert.False(t, useColor(colorNever, &buf, noEnv))
+	assert.False(t, useColor(colorAuto, &buf, noEnv), "a non-terminal writer is never colored in auto mode")
+
+	f, err := os.CreateTemp(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+	assert.False(t, useColor(colorAuto, f, noEnv), "a regular file is not a terminal")
+
+	assert.NoError(t, validateColor(colorAuto))
+	assert.Error(t, validateColor("sometimes"))
+}
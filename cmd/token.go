@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// commandRunner runs an external command and returns its standard output.
+// It exists so the gh CLI fallback can be replaced in tests.
+type commandRunner interface {
+	Output(name string, args ...string) ([]byte, error)
+}
+
+type execRunner struct{}
+
+func (execRunner) Output(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// resolveToken returns the GitHub token from, in order of precedence, the --token-file file,
+// the GITHUB_TOKEN environment variable, and the output of `gh auth token`.
+func resolveToken(tokenFile string, getenv func(string) string, runner commandRunner) (string, error) {
+	if tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token file: %w", err)
+		}
+		token := strings.TrimSpace(string(data))
+		if token == "" {
+			return "", fmt.Errorf("token file %s is empty", tokenFile)
+		}
+		return token, nil
+	}
+	if token := strings.TrimSpace(getenv("GITHUB_TOKEN")); token != "" {
+		return token, nil
+	}
+	if out, err := runner.Output("gh", "auth", "token"); err == nil {
+		if token := strings.TrimSpace(string(out)); token != "" {
+			return token, nil
+		}
+	}
+	return "", errors.New("no GitHub token found: set GITHUB_TOKEN, pass --token-file, or log in with `gh auth login`")
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRunner returns a fixed output for every command and records the invocations.
+type stubRunner struct {
+	out   string
+	err   error
+	calls [][]string
+}
+
+func (r *stubRunner) Output(name string, args ...string) ([]byte, error) {
+	r.calls = append(r.calls, append([]string{name}, args...))
+	return []byte(r.out), r.err
+}
+
+func TestResolveToken(t *testing.T) {
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("  file-token\n"), 0o600))
+	env := func(value string) func(string) string {
+		return func(key string) string {
+			if key == "GITHUB_TOKEN" {
+				return value
+			}
+			return ""
+		}
+	}
+
+	t.Run("file wins over env and gh", func(t *testing.T) {
+		runner := &stubRunner{out: "gh-token\n"}
+		token, err := resolveToken(tokenFile, env("env-token"), runner)
+		require.NoError(t, err)
+		assert.Equal(t, "file-token", token)
+		assert.Empty(t, runner.calls)
+	})
+
+	t.Run("env wins over gh", func(t *testing.T) {
+		runner := &stubRunner{out: "gh-token\n"}
+		token, err := resolveToken("", env("env-token"), runner)
+		require.NoError(t, err)
+		assert.Equal(t, "env-token", token)
+		assert.Empty(t, runner.calls)
+	})
+
+	t.Run("gh fallback", func(t *testing.T) {
+		runner := &stubRunner{out: "gh-token\n"}
+		token, err := resolveToken("", env(""), runner)
+		require.NoError(t, err)
+		assert.Equal(t, "gh-token", token)
+		assert.Equal(t, [][]string{{"gh", "auth", "token"}}, runner.calls)
+	})
+
+	t.Run("nothing available", func(t *testing.T) {
+		_, err := resolveToken("", env(""), &stubRunner{err: errors.New("gh: not found")})
+		assert.ErrorContains(t, err, "no GitHub token found")
+	})
+
+	t.Run("missing or empty file", func(t *testing.T) {
+		_, err := resolveToken(filepath.Join(t.TempDir(), "missing"), env("env-token"), &stubRunner{})
+		assert.ErrorContains(t, err, "failed to read token file")
+
+		empty := filepath.Join(t.TempDir(), "empty")
+		require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+		_, err = resolveToken(empty, env("env-token"), &stubRunner{})
+		assert.ErrorContains(t, err, "is empty")
+	})
+}
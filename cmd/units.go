@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Supported values for the --lead-time-unit flag.
+const (
+	unitSeconds = "seconds"
+	unitMinutes = "minutes"
+	unitHours   = "hours"
+	unitDays    = "days"
+)
+
+// unitSecondsPerUnit is the divisor that converts seconds into each unit.
+var unitSecondsPerUnit = map[string]float64{
+	unitSeconds: 1,
+	unitMinutes: 60,
+	unitHours:   3600,
+	unitDays:    86400,
+}
+
+// unitAbbreviations are used in table headers.
+var unitAbbreviations = map[string]string{
+	unitSeconds: "s",
+	unitMinutes: "min",
+	unitHours:   "h",
+	unitDays:    "d",
+}
+
+// validateUnit returns an error when unit is not a supported lead time unit.
+func validateUnit(unit string) error {
+	if _, ok := unitSecondsPerUnit[unit]; !ok {
+		return fmt.Errorf("unsupported lead time unit %q (valid: %s, %s, %s, %s)", unit, unitSeconds, unitMinutes, unitHours, unitDays)
+	}
+	return nil
+}
+
+// unitOrDefault treats an unset unit as hours, the historical output unit.
+func unitOrDefault(unit string) string {
+	if unit == "" {
+		return unitHours
+	}
+	return unit
+}
+
+// MarshalJSON renames the "_hours" suffix of the duration blocks to the configured unit,
+// e.g. "lead_time_percentiles_hours" becomes "lead_time_percentiles_days", keeping the field order.
+func (o OutputRepoStats) MarshalJSON() ([]byte, error) {
+	type plain OutputRepoStats
+	data, err := json.Marshal(plain(o))
+	if err != nil || unitOrDefault(o.unit) == unitHours {
+		return data, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if _, err := dec.Token(); err != nil { // Opening brace.
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i := 0; dec.More(); i++ {
+		keyToken, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return nil, err
+		}
+		key := keyToken.(string)
+		if trimmed, ok := strings.CutSuffix(key, "_"+unitHours); ok {
+			key = trimmed + "_" + o.unit
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		keyJSON, _ := json.Marshal(key)
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateLeadTimePercentiles_Units(t *testing.T) {
+	// 2 days, 1.5 days and 1 day.
+	seconds := []float64{172800, 129600, 86400}
+
+	days := calculateLeadTimePercentiles(seconds, []float64{50}, unitDays)
+	assert.Equal(t, LeadTimePercentiles{"p50_days": 1.5, "min_days": 1, "max_days": 2}, days)
+
+	minutes := calculateLeadTimePercentiles(seconds, []float64{50}, unitMinutes)
+	assert.Equal(t, LeadTimePercentiles{"p50_minutes": 2160, "min_minutes": 1440, "max_minutes": 2880}, minutes)
+}
+
+func TestOutputRepoStats_MarshalJSONUnit(t *testing.T) {
+	results := buildOutputResults([]*domain.RepoStats{{
+		Name:                        "org/repo-a",
+		LeadTimeToLastReviewSeconds: []float64{86400},
+		TimeToFirstReviewSeconds:    []float64{43200},
+	}}, outputOptions{calculateLeadTime: true, percentiles: []float64{50}, unit: unitDays})
+
+	data, err := json.Marshal(results[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"name": "org/repo-a",
+		"commits": 0,
+		"created_prs": 0,
+		"reviewed_prs": 0,
+		"analyzed_pr_count": 1,
+		"lead_time_percentiles_days": {"p50_days": 1, "min_days": 1, "max_days": 1},
+		"time_to_first_review_percentiles_days": {"p50_days": 0.5, "min_days": 0.5, "max_days": 0.5}
+	}`, string(data))
+	// Field order is kept.
+	assert.Less(t, strings.Index(string(data), `"name"`), strings.Index(string(data), `"lead_time_percentiles_days"`))
+}
+
+func TestValidateUnit(t *testing.T) {
+	for unit := range unitSecondsPerUnit {
+		assert.NoError(t, validateUnit(unit))
+	}
+	assert.ErrorContains(t, validateUnit("weeks"), `unsupported lead time unit "weeks"`)
+}
@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Build metadata, injected at build time with
+// -ldflags "-X github.com/naka-gawa/github-stats/cmd.version=v1.2.3 -X ...cmd.commit=... -X ...cmd.date=...".
+// They stay empty with go run or go install and are then reported as "dev".
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// versionString describes the build, e.g. "v1.2.3 (commit 0123abc, built 2025-01-31T12:00:00Z)".
+func versionString() string {
+	return fmt.Sprintf("%s (commit %s, built %s)", orDev(version), orDev(commit), orDev(date))
+}
+
+// orDev reports an unset build variable as "dev".
+func orDev(value string) string {
+	if value == "" {
+		return "dev"
+	}
+	return value
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "Prints the version, git commit and build date",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, err := fmt.Fprintf(cmd.OutOrStdout(), "github-stats version %s\n", versionString())
+		return err
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(versionCmd)
+	// Setting Version also enables the --version flag, which prints the same line as the subcommand.
+	rootCmd.Version = versionString()
+}
@@ -0,0 +1,24 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionString(t *testing.T) {
+	assert.Equal(t, "dev (commit dev, built dev)", versionString())
+
+	version, commit, date = "v1.2.3", "0123abc", "2025-01-31T12:00:00Z"
+	defer func() { version, commit, date = "", "", "" }()
+	assert.Equal(t, "v1.2.3 (commit 0123abc, built 2025-01-31T12:00:00Z)", versionString())
+}
+
+func TestVersionCommand(t *testing.T) {
+	for _, args := range [][]string{{"version"}, {"--version"}} {
+		out, err := executeCommand(t, args...)
+		require.NoError(t, err)
+		assert.Equal(t, "github-stats version dev (commit dev, built dev)\n", out, "args %v", args)
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+)
+
+// watchLoop calls refresh right away and then every interval until ctx is done.
+// after waits for the interval; it is time.After except in tests.
+func watchLoop(ctx context.Context, interval time.Duration, after func(time.Duration) <-chan time.Time, refresh func(ctx context.Context)) {
+	for {
+		refresh(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-after(interval):
+		}
+	}
+}
+
+// clearScreen moves the cursor home and clears the terminal, so every --watch refresh of the table
+// replaces the previous one.
+func clearScreen(w io.Writer) {
+	fmt.Fprint(w, "\x1b[H\x1b[2J")
+}
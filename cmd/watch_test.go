@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var waits []time.Duration
+	after := func(d time.Duration) <-chan time.Time {
+		waits = append(waits, d)
+		ch := make(chan time.Time, 1)
+		ch <- time.Time{}
+		return ch
+	}
+	refreshes := 0
+	watchLoop(ctx, time.Minute, after, func(ctx context.Context) {
+		refreshes++
+		if refreshes == 3 {
+			cancel()
+		}
+	})
+
+	assert.Equal(t, 3, refreshes)
+	assert.Equal(t, []time.Duration{time.Minute, time.Minute}, waits, "the loop stops without waiting once cancelled")
+}
+
+func TestWatchLoop_CancelWhileWaiting(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	after := func(time.Duration) <-chan time.Time {
+		cancel()
+		return make(chan time.Time) // Never fires.
+	}
+	refreshes := 0
+	watchLoop(ctx, time.Minute, after, func(context.Context) { refreshes++ })
+	assert.Equal(t, 1, refreshes)
+}
+
+func TestClearScreen(t *testing.T) {
+	var buf bytes.Buffer
+	clearScreen(&buf)
+	assert.Equal(t, "\x1b[H\x1b[2J", buf.String())
+}
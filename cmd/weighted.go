@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+)
+
+// WeightedLeadTime holds lead time statistics in which recent PRs count more than old ones.
+type WeightedLeadTime struct {
+	HalfLifeDays float64 `json:"half_life_days"`
+	Mean         float64 `json:"mean"`
+	P50          float64 `json:"p50"`
+	P90          float64 `json:"p90"`
+}
+
+// parseDayDuration parses durations such as "30d" or "2w" in addition to everything time.ParseDuration accepts.
+func parseDayDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			value, err := strconv.ParseFloat(n, 64)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(value * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
+}
+
+// calculateWeightedLeadTime computes exponentially weighted lead time statistics in unit (hours when empty).
+// Each PR's weight halves every halfLife since its creation, measured from now.
+// It returns nil when there are no pull requests.
+func calculateWeightedLeadTime(prs []domain.PRLeadTime, halfLife time.Duration, now time.Time, unit string) *WeightedLeadTime {
+	if len(prs) == 0 {
+		return nil
+	}
+	values := make([]float64, len(prs))
+	weights := make([]float64, len(prs))
+	for i, pr := range prs {
+		age := now.Sub(pr.CreatedAt)
+		if age < 0 {
+			age = 0
+		}
+		values[i] = pr.LeadTimeSeconds / unitSecondsPerUnit[unitOrDefault(unit)]
+		weights[i] = math.Pow(0.5, age.Hours()/halfLife.Hours())
+	}
+	return &WeightedLeadTime{
+		HalfLifeDays: halfLife.Hours() / 24,
+		Mean:         weightedMean(values, weights),
+		P50:          weightedPercentile(values, weights, 50),
+		P90:          weightedPercentile(values, weights, 90),
+	}
+}
+
+// weightedMean returns the weighted arithmetic mean of values.
+func weightedMean(values, weights []float64) float64 {
+	var sum, totalWeight float64
+	for i, v := range values {
+		sum += v * weights[i]
+		totalWeight += weights[i]
+	}
+	if totalWeight == 0 {
+		return 0
+	}
+	return sum / totalWeight
+}
+
+// weightedPercentile uses the weighted nearest-rank method:
+// it returns the smallest value whose cumulative weight reaches percent of the total weight.
+// montanaflynn/stats has no weighted variant, and nearest-rank keeps the result an observed value.
+func weightedPercentile(values, weights []float64, percent float64) float64 {
+	idx := make([]int, len(values))
+	var totalWeight float64
+	for i := range values {
+		idx[i] = i
+		totalWeight += weights[i]
+	}
+	sort.SliceStable(idx, func(a, b int) bool { return values[idx[a]] < values[idx[b]] })
+
+	threshold := percent / 100 * totalWeight
+	var cumulative float64
+	for _, i := range idx {
+		cumulative += weights[i]
+		if cumulative >= threshold {
+			return values[i]
+		}
+	}
+	return values[idx[len(idx)-1]]
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDayDuration(t *testing.T) {
+	testCases := []struct {
+		input       string
+		expected    time.Duration
+		expectError bool
+	}{
+		{input: "30d", expected: 30 * 24 * time.Hour},
+		{input: "2w", expected: 14 * 24 * time.Hour},
+		{input: "1.5d", expected: 36 * time.Hour},
+		{input: "12h", expected: 12 * time.Hour},
+		{input: "xd", expectError: true},
+		{input: "soon", expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			d, err := parseDayDuration(tc.input)
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expected, d)
+			}
+		})
+	}
+}
+
+func TestCalculateWeightedLeadTime(t *testing.T) {
+	now := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	halfLife := 30 * 24 * time.Hour
+	prs := []domain.PRLeadTime{
+		// One half-life old: weight 0.5.
+		{CreatedAt: now.Add(-halfLife), LeadTimeSeconds: 100 * 3600},
+		// Brand new: weight 1.
+		{CreatedAt: now, LeadTimeSeconds: 10 * 3600},
+	}
+
+	result := calculateWeightedLeadTime(prs, halfLife, now, "")
+	require.NotNil(t, result)
+	assert.Equal(t, 30.0, result.HalfLifeDays)
+	// (100*0.5 + 10*1) / 1.5
+	assert.InDelta(t, 40.0, result.Mean, 1e-9)
+	// The recent PR alone carries two thirds of the weight.
+	assert.Equal(t, 10.0, result.P50)
+	assert.Equal(t, 100.0, result.P90)
+
+	assert.Nil(t, calculateWeightedLeadTime(nil, halfLife, now, ""))
+}
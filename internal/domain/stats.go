@@ -1,12 +1,81 @@
 // Package domain contains the core data structures and domain logic for the application.
 package domain
 
+import "time"
+
 // RepoStats holds the activity counts for a single repository.
 // It is the core domain entity of this application.
 type RepoStats struct {
-	Name                        string    `json:"name"`
-	Commits                     int       `json:"commits"`
-	CreatedPRs                  int       `json:"created_prs"`
-	ReviewedPRs                 int       `json:"reviewed_prs"`
-	LeadTimeToLastReviewSeconds []float64 `json:"-"`
+	Name                        string       `json:"name"`
+	User                        string       `json:"user,omitempty"`
+	NodeID                      string       `json:"node_id,omitempty"`
+	PrimaryLanguage             string       `json:"primary_language,omitempty"`
+	Commits                     int          `json:"commits"`
+	CreatedPRs                  int          `json:"created_prs"`
+	DraftPRs                    int          `json:"draft_prs"`
+	MergedPRs                   int          `json:"merged_prs"`
+	ReviewedPRs                 int          `json:"reviewed_prs"`
+	CommentedPRs                int          `json:"commented_prs"`
+	ReviewComments              int          `json:"review_comments"`
+	ApprovalsGiven              int          `json:"approvals_given"`
+	AssignedPRs                 int          `json:"assigned_prs"`
+	OpenPRs                     int          `json:"open_prs"`
+	CreatedIssues               int          `json:"created_issues"`
+	ClosedIssues                int          `json:"closed_issues"`
+	OrgCommits                  int          `json:"org_commits"`
+	OrgCreatedPRs               int          `json:"org_created_prs"`
+	TotalAdditions              int          `json:"total_additions"`
+	TotalDeletions              int          `json:"total_deletions"`
+	CommitAdditions             int          `json:"commit_additions"`
+	CommitDeletions             int          `json:"commit_deletions"`
+	MedianPRSizeLines           float64      `json:"median_pr_size_lines"`
+	PRSizeLines                 []float64    `json:"-"`
+	LeadTimeToLastReviewSeconds []float64    `json:"-"`
+	TimeToFirstReviewSeconds    []float64    `json:"-"`
+	MergeTimeSeconds            []float64    `json:"-"`
+	ReviewerLatencySeconds      []float64    `json:"-"`
+	AuthorWaitSeconds           []float64    `json:"-"`
+	PullRequests                []PRLeadTime `json:"-"`
+	// Series holds the counts per time series bucket, keyed by the bucket's start date.
+	Series map[string]BucketStats `json:"series,omitempty"`
+	// CommitHeatmap counts the commits per weekday and hour of their author date. It is nil unless requested.
+	CommitHeatmap *Heatmap `json:"commit_heatmap,omitempty"`
+}
+
+// HasActivity reports whether the repository has a non-zero count of the user's own activity.
+// The org-wide totals of --share don't count, since they aren't the user's.
+func (r *RepoStats) HasActivity() bool {
+	counts := []int{r.Commits, r.CreatedPRs, r.DraftPRs, r.MergedPRs, r.ReviewedPRs, r.CommentedPRs,
+		r.ReviewComments, r.ApprovalsGiven, r.AssignedPRs, r.OpenPRs, r.CreatedIssues, r.ClosedIssues}
+	for _, count := range counts {
+		if count > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Heatmap counts activity per weekday, starting with Sunday like time.Weekday, and hour of the day.
+type Heatmap [7][24]int
+
+// Add counts t in the cell of its weekday and hour in loc.
+func (h *Heatmap) Add(t time.Time, loc *time.Location) {
+	t = t.In(loc)
+	h[t.Weekday()][t.Hour()]++
+}
+
+// BucketStats holds the activity counts of a repository in one time series bucket.
+type BucketStats struct {
+	Commits     int `json:"commits"`
+	CreatedPRs  int `json:"created_prs"`
+	ReviewedPRs int `json:"reviewed_prs"`
+}
+
+// PRLeadTime holds the lead time details of a single analyzed pull request.
+type PRLeadTime struct {
+	Number          int
+	CreatedAt       time.Time
+	LeadTimeSeconds float64
+	Additions       int
+	Deletions       int
 }
@@ -0,0 +1,235 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// CachingFetcher is a Fetcher decorator that stores the search results on disk,
+// so repeated runs for the same query don't spend rate limit.
+// Methods that are not cached, such as CheckOrgAccess, go straight to the wrapped Fetcher.
+type CachingFetcher struct {
+	Fetcher
+	dir    string
+	ttl    time.Duration
+	logger *log.Logger
+	now    func() time.Time
+	// tokenFingerprint is part of every key, so results fetched with one token are never served to another.
+	tokenFingerprint string
+	// caveats are those of the wrapped gateway; results fetched while a caveat was added are not stored.
+	caveats *Caveats
+}
+
+// NewCachingFetcher wraps fetcher with a cache in dir whose entries expire after ttl.
+// token is the one fetcher authenticates with; only a fingerprint of it is kept.
+// caveats, which may be nil, are the ones fetcher was set up WithCaveats: a result known to be incomplete
+// is not cached, so a later run raises its caveats again instead of silently reusing it.
+func NewCachingFetcher(fetcher Fetcher, dir string, ttl time.Duration, token string, caveats *Caveats, logger *log.Logger) *CachingFetcher {
+	return &CachingFetcher{
+		Fetcher:          fetcher,
+		dir:              dir,
+		ttl:              ttl,
+		logger:           logger,
+		now:              time.Now,
+		tokenFingerprint: TokenFingerprint(token),
+		caveats:          caveats,
+	}
+}
+
+// TokenFingerprint returns a short hash identifying token without revealing it.
+func TokenFingerprint(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:8])
+}
+
+// DefaultCacheDir returns the github-stats directory in the user's cache directory.
+func DefaultCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the user cache directory: %w", err)
+	}
+	return filepath.Join(dir, "github-stats"), nil
+}
+
+func (c *CachingFetcher) FetchCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchCommits", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchCommits(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchCommitTimes(ctx context.Context, org, user, dateRange string) (map[string][]time.Time, error) {
+	return cached(c, []string{"FetchCommitTimes", org, user, dateRange}, func() (map[string][]time.Time, error) {
+		return c.Fetcher.FetchCommitTimes(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchCreatedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchCreatedPRs", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchCreatedPRs(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchReviewedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchReviewedPRs", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchReviewedPRs(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchMergedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchMergedPRs", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchMergedPRs(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchDraftPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchDraftPRs", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchDraftPRs(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchCommentedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchCommentedPRs", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchCommentedPRs(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchPRLeadTimes(ctx context.Context, org, user, dateRange string) (map[string][]PRLeadTimeData, error) {
+	return cached(c, []string{"FetchPRLeadTimes", org, user, dateRange}, func() (map[string][]PRLeadTimeData, error) {
+		return c.Fetcher.FetchPRLeadTimes(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchMergedPRCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchMergedPRCommits", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchMergedPRCommits(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchCreatedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchCreatedIssues", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchCreatedIssues(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchClosedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchClosedIssues", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchClosedIssues(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchReviewComments(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchReviewComments", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchReviewComments(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchAssignedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchAssignedPRs", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchAssignedPRs(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchOpenPRs(ctx context.Context, org, user string) (map[string]int, error) {
+	return cached(c, []string{"FetchOpenPRs", org, user}, func() (map[string]int, error) {
+		return c.Fetcher.FetchOpenPRs(ctx, org, user)
+	})
+}
+
+func (c *CachingFetcher) FetchApprovals(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	return cached(c, []string{"FetchApprovals", org, user, dateRange}, func() (map[string]int, error) {
+		return c.Fetcher.FetchApprovals(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchReviewerLatencies(ctx context.Context, org, user, dateRange string) (map[string][]ReviewerLatencyData, error) {
+	return cached(c, []string{"FetchReviewerLatencies", org, user, dateRange}, func() (map[string][]ReviewerLatencyData, error) {
+		return c.Fetcher.FetchReviewerLatencies(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchAuthorWaits(ctx context.Context, org, user, dateRange string) (map[string][]AuthorWaitData, error) {
+	return cached(c, []string{"FetchAuthorWaits", org, user, dateRange}, func() (map[string][]AuthorWaitData, error) {
+		return c.Fetcher.FetchAuthorWaits(ctx, org, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchCommitChurn(ctx context.Context, repos []string, user, dateRange string) (map[string]CommitChurn, error) {
+	key := append([]string{"FetchCommitChurn", user, dateRange}, repos...)
+	return cached(c, key, func() (map[string]CommitChurn, error) {
+		return c.Fetcher.FetchCommitChurn(ctx, repos, user, dateRange)
+	})
+}
+
+func (c *CachingFetcher) FetchPRSizes(ctx context.Context, org, user, dateRange string) (map[string][]PRSize, error) {
+	return cached(c, []string{"FetchPRSizes", org, user, dateRange}, func() (map[string][]PRSize, error) {
+		return c.Fetcher.FetchPRSizes(ctx, org, user, dateRange)
+	})
+}
+
+// cached returns the cache entry for key if it is younger than the TTL, and otherwise calls fetch and stores its result.
+// The cache is best effort: unreadable entries are refetched and failed writes are only logged.
+func cached[T any](c *CachingFetcher, key []string, fetch func() (T, error)) (T, error) {
+	path := c.path(key)
+	if info, err := os.Stat(path); err == nil && c.now().Sub(info.ModTime()) < c.ttl {
+		var value T
+		data, err := os.ReadFile(path)
+		if err == nil && json.Unmarshal(data, &value) == nil {
+			c.logger.Printf("Cache hit for %s\n", key[0])
+			return value, nil
+		}
+	}
+
+	// Caveats of concurrent fetches count too, which at worst leaves a complete result uncached.
+	caveatsBefore := c.caveats.count()
+	value, err := fetch()
+	if err != nil {
+		return value, err
+	}
+	if c.caveats.count() != caveatsBefore {
+		c.logger.Printf("Not caching %s, whose results may be incomplete\n", key[0])
+		return value, nil
+	}
+	if err := c.store(path, value); err != nil {
+		c.logger.Printf("Failed to write cache entry for %s: %v\n", key[0], err)
+	}
+	return value, nil
+}
+
+// path returns the cache file for key. The parts are hashed so any org, user or date range makes a safe file name.
+func (c *CachingFetcher) path(key []string) string {
+	sum := sha256.Sum256([]byte(c.tokenFingerprint + "\x00" + strings.Join(key, "\x00")))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+func (c *CachingFetcher) store(path string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, 0o700); err != nil {
+		return err
+	}
+	// Write to a temporary file first so a concurrent reader never sees a partial entry.
+	tmp, err := os.CreateTemp(c.dir, "entry-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,124 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingFetcher records how often each method reached the underlying fetcher.
+type countingFetcher struct {
+	Fetcher
+	calls map[string]int
+}
+
+func (f *countingFetcher) FetchCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	f.calls["FetchCommits"]++
+	return map[string]int{"org/repo-a": 3}, nil
+}
+
+func (f *countingFetcher) FetchPRLeadTimes(ctx context.Context, org, user, dateRange string) (map[string][]PRLeadTimeData, error) {
+	f.calls["FetchPRLeadTimes"]++
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	return map[string][]PRLeadTimeData{
+		"org/repo-a": {{Number: 7, CreatedAt: createdAt, LastReviewedAt: createdAt.Add(time.Hour), Additions: 10}},
+	}, nil
+}
+
+func TestCachingFetcher_SecondCallHitsCache(t *testing.T) {
+	underlying := &countingFetcher{calls: map[string]int{}}
+	cache := NewCachingFetcher(underlying, t.TempDir(), time.Hour, "token", nil, log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	first, err := cache.FetchCommits(ctx, "org", "user", " created:2025-01-01..*")
+	require.NoError(t, err)
+	second, err := cache.FetchCommits(ctx, "org", "user", " created:2025-01-01..*")
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.Equal(t, 1, underlying.calls["FetchCommits"])
+
+	firstLeadTimes, err := cache.FetchPRLeadTimes(ctx, "org", "user", "")
+	require.NoError(t, err)
+	secondLeadTimes, err := cache.FetchPRLeadTimes(ctx, "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, firstLeadTimes, secondLeadTimes)
+	assert.Equal(t, 1, underlying.calls["FetchPRLeadTimes"])
+}
+
+func TestCachingFetcher_KeyAndExpiry(t *testing.T) {
+	underlying := &countingFetcher{calls: map[string]int{}}
+	cache := NewCachingFetcher(underlying, t.TempDir(), time.Hour, "token", nil, log.New(io.Discard, "", 0))
+	ctx := context.Background()
+
+	_, err := cache.FetchCommits(ctx, "org", "user", "")
+	require.NoError(t, err)
+	// A different user is a different cache entry.
+	_, err = cache.FetchCommits(ctx, "org", "other-user", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, underlying.calls["FetchCommits"])
+
+	// Once the TTL has passed, the entry falls through to the underlying fetcher.
+	cache.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = cache.FetchCommits(ctx, "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, 3, underlying.calls["FetchCommits"])
+}
+
+func TestCachingFetcher_KeyIncludesToken(t *testing.T) {
+	underlying := &countingFetcher{calls: map[string]int{}}
+	dir := t.TempDir()
+	logger := log.New(io.Discard, "", 0)
+	ctx := context.Background()
+
+	_, err := NewCachingFetcher(underlying, dir, time.Hour, "token-a", nil, logger).FetchCommits(ctx, "org", "user", "")
+	require.NoError(t, err)
+	// Another token may see different repositories, so it doesn't get the first token's entry.
+	_, err = NewCachingFetcher(underlying, dir, time.Hour, "token-b", nil, logger).FetchCommits(ctx, "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, underlying.calls["FetchCommits"])
+
+	_, err = NewCachingFetcher(underlying, dir, time.Hour, "token-a", nil, logger).FetchCommits(ctx, "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, underlying.calls["FetchCommits"])
+}
+
+func TestCachingFetcher_IncompleteResultsAreNotCached(t *testing.T) {
+	testCases := []struct {
+		name             string
+		issueCount       int
+		expectedRequests int
+		expectedCaveats  int
+	}{
+		{name: "capped search is fetched again on every run", issueCount: 1500, expectedRequests: 2, expectedCaveats: 1},
+		{name: "complete search is cached", issueCount: 1, expectedRequests: 1, expectedCaveats: 0},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			gateway, server := setupTestGateway(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				fmt.Fprintf(w, `{"data":{"search":{"issueCount":%d,"edges":[{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}}]}}}`, tc.issueCount)
+			}))
+			defer server.Close()
+			var caveats Caveats
+			WithCaveats(&caveats)(gateway)
+			dir := t.TempDir()
+
+			// Each run reports its own caveats, as --strict does before failing.
+			for range 2 {
+				cache := NewCachingFetcher(gateway, dir, time.Hour, "token", &caveats, log.New(io.Discard, "", 0))
+				_, err := cache.FetchCreatedPRs(context.Background(), "org", "user", "")
+				require.NoError(t, err)
+				assert.Len(t, caveats.Drain(), tc.expectedCaveats)
+			}
+			assert.Equal(t, tc.expectedRequests, requests)
+		})
+	}
+}
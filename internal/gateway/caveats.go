@@ -0,0 +1,129 @@
+package gateway
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Caveats collects conditions that leave the fetched data incomplete without failing the fetch,
+// e.g. so the command can warn about them, or fail under --strict. It is safe for concurrent use.
+type Caveats struct {
+	mu      sync.Mutex
+	caveats map[string]bool
+	// added counts every add, repeated messages included, so a caller can tell whether anything was added meanwhile.
+	added int
+}
+
+// add records a caveat; the same message is kept once, however often it occurs.
+func (c *Caveats) add(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.caveats == nil {
+		c.caveats = make(map[string]bool)
+	}
+	c.caveats[fmt.Sprintf(format, args...)] = true
+	c.added++
+}
+
+// count returns how many caveats were added so far, or 0 for nil Caveats.
+func (c *Caveats) count() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.added
+}
+
+// Drain returns the recorded caveats in a stable order and forgets them, so a later run only reports its own.
+func (c *Caveats) Drain() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	drained := make([]string, 0, len(c.caveats))
+	for caveat := range c.caveats {
+		drained = append(drained, caveat)
+	}
+	sort.Strings(drained)
+	c.caveats = nil
+	return drained
+}
+
+// WithCaveats records the conditions that make the results incomplete in caveats.
+func WithCaveats(caveats *Caveats) Option {
+	return func(g *GitHubGateway) {
+		g.caveats = caveats
+	}
+}
+
+// caveat records a caveat when the gateway was set up WithCaveats.
+func (g *GitHubGateway) caveat(format string, args ...interface{}) {
+	if g.caveats != nil {
+		g.caveats.add(format, args...)
+	}
+}
+
+// searchResultCap is the most results GitHub returns for a search, however many pages are requested.
+const searchResultCap = 1000
+
+// checkSearchCap records a caveat when the search for query matched more results than GitHub returns,
+// or when GitHub reports the results as incomplete because the search timed out.
+func (g *GitHubGateway) checkSearchCap(query string, total int, incomplete bool) {
+	if total > searchResultCap {
+		g.caveat("the search %q matched %d results, but GitHub returns at most %d; narrow the date range to count the rest", query, total, searchResultCap)
+	}
+	if incomplete {
+		g.caveat("the search %q timed out and GitHub returned incomplete results", query)
+	}
+}
+
+// requiredScopes are the OAuth scopes a classic token needs for complete results, with the scopes that imply each one.
+var requiredScopes = []struct {
+	scope   string
+	implied []string
+}{
+	{scope: "repo", implied: []string{"repo"}},
+	{scope: "read:org", implied: []string{"read:org", "write:org", "admin:org"}},
+}
+
+// missingScopes returns the required scopes not granted by the X-OAuth-Scopes header value granted.
+func missingScopes(granted string) []string {
+	have := make(map[string]bool)
+	for _, scope := range strings.Split(granted, ",") {
+		have[strings.TrimSpace(scope)] = true
+	}
+	var missing []string
+	for _, required := range requiredScopes {
+		if !slices.ContainsFunc(required.implied, func(scope string) bool { return have[scope] }) {
+			missing = append(missing, required.scope)
+		}
+	}
+	return missing
+}
+
+// scopeCheckTransport records a caveat when the token lacks a required scope. Only classic tokens
+// list their scopes in the X-OAuth-Scopes header; fine-grained tokens and apps are not checked.
+type scopeCheckTransport struct {
+	base    http.RoundTripper
+	caveats *Caveats
+	once    sync.Once
+}
+
+func (t *scopeCheckTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if granted, ok := resp.Header["X-Oauth-Scopes"]; ok {
+		t.once.Do(func() {
+			if missing := missingScopes(strings.Join(granted, ",")); len(missing) > 0 {
+				t.caveats.add("the token lacks the %s scope(s); private repositories and organization memberships may not be visible and results may be incomplete",
+					strings.Join(missing, ", "))
+			}
+		})
+	}
+	return resp, nil
+}
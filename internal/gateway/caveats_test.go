@@ -0,0 +1,135 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCaveats(t *testing.T) {
+	var caveats Caveats
+	assert.Empty(t, caveats.Drain())
+
+	caveats.add("search for %q was cut off", "b")
+	caveats.add("search for %q was cut off", "a")
+	caveats.add("search for %q was cut off", "b")
+	// Repeated caveats are reported once, in a stable order.
+	assert.Equal(t, []string{`search for "a" was cut off`, `search for "b" was cut off`}, caveats.Drain())
+	// Draining forgets them.
+	assert.Empty(t, caveats.Drain())
+}
+
+func TestMissingScopes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		granted  string
+		expected []string
+	}{
+		{name: "all scopes", granted: "repo, read:org", expected: nil},
+		{name: "broader org scope", granted: "admin:org, repo, workflow", expected: nil},
+		{name: "public repositories only", granted: "public_repo, read:org", expected: []string{"repo"}},
+		{name: "no scopes", granted: "", expected: []string{"repo", "read:org"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, missingScopes(tc.granted))
+		})
+	}
+}
+
+func TestNewGitHubGateway_ScopeCaveat(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   func(h http.Header)
+		expected []string
+	}{
+		{
+			name:     "classic token without repo",
+			header:   func(h http.Header) { h.Set("X-OAuth-Scopes", "public_repo, read:org") },
+			expected: []string{"the token lacks the repo scope(s); private repositories and organization memberships may not be visible and results may be incomplete"},
+		},
+		{
+			name:     "classic token with every scope",
+			header:   func(h http.Header) { h.Set("X-OAuth-Scopes", "repo, read:org") },
+			expected: []string{},
+		},
+		{
+			name:     "fine-grained token",
+			header:   func(h http.Header) {},
+			expected: []string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tc.header(w.Header())
+				fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+			}))
+			defer server.Close()
+			var caveats Caveats
+			gateway, err := NewGitHubGateway("token", log.New(io.Discard, "", 0), WithBaseURL(server.URL), WithCaveats(&caveats))
+			require.NoError(t, err)
+
+			_, err = gateway.FetchCommits(context.Background(), "org", "user", "")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, caveats.Drain())
+		})
+	}
+}
+
+func TestGitHubGateway_SearchCapCaveats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		response string
+		fetch    func(g *GitHubGateway) error
+		expected []string
+	}{
+		{
+			name:     "GraphQL search over the cap",
+			response: `{"data":{"search":{"issueCount":1500,"edges":[{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}}]}}}`,
+			fetch: func(g *GitHubGateway) error {
+				_, err := g.FetchCreatedPRs(context.Background(), "org", "user", "")
+				return err
+			},
+			expected: []string{`the search "org:org author:user is:pr" matched 1500 results, but GitHub returns at most 1000; narrow the date range to count the rest`},
+		},
+		{
+			name:     "GraphQL search within the cap",
+			response: `{"data":{"search":{"issueCount":1000,"edges":[]}}}`,
+			fetch: func(g *GitHubGateway) error {
+				_, err := g.FetchCreatedPRs(context.Background(), "org", "user", "")
+				return err
+			},
+			expected: []string{},
+		},
+		{
+			name:     "REST search with incomplete results",
+			response: `{"total_count": 1, "incomplete_results": true, "items": [{"sha": "abc", "repository": {"full_name": "org/repo-a"}}]}`,
+			fetch: func(g *GitHubGateway) error {
+				_, err := g.FetchCommits(context.Background(), "org", "user", "")
+				return err
+			},
+			expected: []string{`the search "org:org author:user" timed out and GitHub returned incomplete results`},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway, server := setupTestGateway(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.response)
+			}))
+			defer server.Close()
+			var caveats Caveats
+			WithCaveats(&caveats)(gateway)
+
+			require.NoError(t, tc.fetch(gateway))
+			assert.Equal(t, tc.expected, caveats.Drain())
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v62/github"
+)
+
+// CommitChurn is the number of lines a user added and deleted with their commits to a repository.
+type CommitChurn struct {
+	Additions int
+	Deletions int
+}
+
+// GitHub computes the contributor statistics in the background and answers 202 Accepted until they are ready.
+// FetchCommitChurn asks again up to defaultStatsPollAttempts times, defaultStatsPollDelay apart.
+const (
+	defaultStatsPollAttempts = 5
+	defaultStatsPollDelay    = 2 * time.Second
+)
+
+// FetchCommitChurn sums the weekly additions and deletions of the user in each repository's contributor statistics.
+// The statistics only cover the default branch, exclude merge commits and are kept per week,
+// so every week that overlaps dateRange counts in full.
+func (g *GitHubGateway) FetchCommitChurn(ctx context.Context, repos []string, user, dateRange string) (map[string]CommitChurn, error) {
+	ctx = g.step(ctx, "commit churn", "Fetching commit churn from contributor statistics...")
+	since, until, err := parseCommitDateRange(dateRange)
+	if err != nil {
+		return nil, err
+	}
+	churn := make(map[string]CommitChurn, len(repos))
+	for _, repo := range repos {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid repository name %q, expected owner/name", repo)
+		}
+		contributors, err := g.fetchContributorStats(ctx, owner, name)
+		if err != nil {
+			return nil, err
+		}
+		var from, to time.Time
+		if since != nil {
+			from = since.Time
+		}
+		if until != nil {
+			to = until.Time
+		}
+		churn[repo] = commitChurnOf(contributors, user, from, to)
+	}
+	g.logger.Println("Completed fetching commit churn.")
+	return churn, nil
+}
+
+// fetchContributorStats fetches the contributor statistics of a repository, waiting while GitHub computes them.
+func (g *GitHubGateway) fetchContributorStats(ctx context.Context, owner, name string) ([]*github.ContributorStats, error) {
+	attempts, delay := g.statsPollAttempts, g.statsPollDelay
+	if attempts <= 0 {
+		attempts = defaultStatsPollAttempts
+	}
+	if delay <= 0 {
+		delay = defaultStatsPollDelay
+	}
+	for attempt := 1; ; attempt++ {
+		contributors, _, err := g.restClient.Repositories.ListContributorsStats(ctx, owner, name)
+		var accepted *github.AcceptedError
+		if !errors.As(err, &accepted) {
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch contributor statistics of %s/%s: %w", owner, name, err)
+			}
+			return contributors, nil
+		}
+		if attempt >= attempts {
+			return nil, fmt.Errorf("GitHub is still computing the contributor statistics of %s/%s; try again later", owner, name)
+		}
+		g.logger.Printf("  GitHub is computing the contributor statistics of %s/%s, asking again...\n", owner, name)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// commitChurnOf sums the user's additions and deletions in the weeks that overlap [from, to].
+// A zero from or to leaves that side open.
+func commitChurnOf(contributors []*github.ContributorStats, user string, from, to time.Time) CommitChurn {
+	var churn CommitChurn
+	for _, contributor := range contributors {
+		if !strings.EqualFold(contributor.GetAuthor().GetLogin(), user) {
+			continue
+		}
+		for _, week := range contributor.Weeks {
+			start := week.GetWeek().Time
+			if (!from.IsZero() && !start.AddDate(0, 0, 7).After(from)) || (!to.IsZero() && start.After(to)) {
+				continue
+			}
+			churn.Additions += week.GetAdditions()
+			churn.Deletions += week.GetDeletions()
+		}
+	}
+	return churn
+}
@@ -0,0 +1,78 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// contributorStatsBody has alice's commits in the weeks starting 2024-05-05, 2024-05-12 and 2024-05-19, and bob's in one.
+var contributorStatsBody = fmt.Sprintf(`[
+	{"author":{"login":"Alice"},"total":6,"weeks":[
+		{"w":%d,"a":10,"d":2,"c":1},
+		{"w":%d,"a":100,"d":20,"c":3},
+		{"w":%d,"a":1000,"d":200,"c":2}
+	]},
+	{"author":{"login":"bob"},"total":1,"weeks":[{"w":%d,"a":7,"d":7,"c":1}]}
+]`,
+	time.Date(2024, 5, 5, 0, 0, 0, 0, time.UTC).Unix(),
+	time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC).Unix(),
+	time.Date(2024, 5, 19, 0, 0, 0, 0, time.UTC).Unix(),
+	time.Date(2024, 5, 12, 0, 0, 0, 0, time.UTC).Unix())
+
+func TestGitHubGateway_FetchCommitChurn(t *testing.T) {
+	testCases := []struct {
+		name      string
+		accepted  int
+		dateRange string
+		expected  CommitChurn
+	}{
+		{name: "stats ready", expected: CommitChurn{Additions: 1110, Deletions: 222}},
+		{name: "stats computed after two 202 responses", accepted: 2, expected: CommitChurn{Additions: 1110, Deletions: 222}},
+		// The week of 2024-05-12 overlaps the range; the weeks before and after don't.
+		{name: "date range", dateRange: " author-date:2024-05-14..2024-05-18", expected: CommitChurn{Additions: 100, Deletions: 20}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/org/repo-a/stats/contributors", r.URL.Path)
+				requests++
+				if requests <= tc.accepted {
+					w.WriteHeader(http.StatusAccepted)
+					fmt.Fprint(w, `{}`)
+					return
+				}
+				fmt.Fprint(w, contributorStatsBody)
+			}
+			gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+			defer server.Close()
+			gateway.statsPollDelay = time.Millisecond
+
+			churn, err := gateway.FetchCommitChurn(context.Background(), []string{"org/repo-a"}, "alice", tc.dateRange)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]CommitChurn{"org/repo-a": tc.expected}, churn)
+			assert.Equal(t, tc.accepted+1, requests)
+		})
+	}
+}
+
+func TestGitHubGateway_FetchCommitChurn_StillComputing(t *testing.T) {
+	requests := 0
+	gateway, server := setupTestGateway(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	gateway.statsPollAttempts = 3
+	gateway.statsPollDelay = time.Millisecond
+
+	_, err := gateway.FetchCommitChurn(context.Background(), []string{"org/repo-a"}, "alice", "")
+	assert.ErrorContains(t, err, "still computing the contributor statistics of org/repo-a")
+	assert.Equal(t, 3, requests)
+}
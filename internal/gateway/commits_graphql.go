@@ -0,0 +1,193 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shurcooL/githubv4"
+)
+
+// Supported sources of the commit counts.
+const (
+	// CommitsSourceREST counts commits with the REST commit search. It is the default.
+	CommitsSourceREST = "rest"
+	// CommitsSourceGraphQL counts commits on the default branch of every repository of the organizations with GraphQL.
+	// It avoids the search API's 30 requests per minute limit and its 1,000 result cap, but has to enumerate
+	// every repository of the organizations, so it costs more requests for large organizations with few active repositories.
+	CommitsSourceGraphQL = "graphql"
+)
+
+// ValidateCommitsSource returns an error when source is not a supported commits source.
+func ValidateCommitsSource(source string) error {
+	if source != CommitsSourceREST && source != CommitsSourceGraphQL {
+		return fmt.Errorf("unsupported commits source %q (valid: %s, %s)", source, CommitsSourceREST, CommitsSourceGraphQL)
+	}
+	return nil
+}
+
+// WithCommitsSource selects how FetchCommits counts commits; see CommitsSourceREST and CommitsSourceGraphQL.
+func WithCommitsSource(source string) Option {
+	return func(g *GitHubGateway) {
+		g.commitsSource = source
+	}
+}
+
+// The date formats DateRangeQualifiers emits in search qualifiers.
+const (
+	searchDateLayout     = "2006-01-02"
+	searchDateTimeLayout = "2006-01-02T15:04:05-07:00"
+)
+
+// userIDQuery resolves a login to the node ID the commit history filter needs.
+type userIDQuery struct {
+	User struct {
+		ID githubv4.ID
+	} `graphql:"user(login: $login)"`
+}
+
+// orgCommitHistoryQuery counts the user's commits on the default branch of each repository of an organization.
+type orgCommitHistoryQuery struct {
+	Organization struct {
+		Repositories struct {
+			Nodes []struct {
+				NameWithOwner    string
+				DefaultBranchRef *struct {
+					Target struct {
+						Commit struct {
+							History struct {
+								TotalCount int
+							} `graphql:"history(author: $author, since: $since, until: $until)"`
+						} `graphql:"... on Commit"`
+					}
+				}
+			}
+			PageInfo struct {
+				EndCursor   githubv4.String
+				HasNextPage bool
+			}
+		} `graphql:"repositories(first: $first, after: $cursor)"`
+	} `graphql:"organization(login: $org)"`
+}
+
+// repoCommitHistoryQuery counts the user's commits on the default branch of a single repository.
+type repoCommitHistoryQuery struct {
+	Repository struct {
+		NameWithOwner    string
+		DefaultBranchRef *struct {
+			Target struct {
+				Commit struct {
+					History struct {
+						TotalCount int
+					} `graphql:"history(author: $author, since: $since, until: $until)"`
+				} `graphql:"... on Commit"`
+			}
+		}
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// fetchCommitsGraphQL counts the user's commits per repository from the default branch histories.
+func (g *GitHubGateway) fetchCommitsGraphQL(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "commits", "[1/4] Fetching commit data using GraphQL API...")
+	since, until, err := parseCommitDateRange(dateRange)
+	if err != nil {
+		return nil, err
+	}
+	var userQuery userIDQuery
+	if err := g.graphqlClient.Query(ctx, &userQuery, map[string]interface{}{"login": githubv4.String(user)}); err != nil {
+		return nil, fmt.Errorf("failed to resolve user %s: %w", user, err)
+	}
+	author := githubv4.CommitAuthor{ID: &userQuery.User.ID}
+
+	commitCounts := make(map[string]int)
+	for _, o := range strings.Split(org, ",") {
+		if o = strings.TrimSpace(o); o == "" {
+			continue
+		}
+		if owner, name, ok := strings.Cut(o, "/"); ok {
+			var q repoCommitHistoryQuery
+			variables := map[string]interface{}{
+				"owner":  githubv4.String(owner),
+				"name":   githubv4.String(name),
+				"author": author,
+				"since":  since,
+				"until":  until,
+			}
+			if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+				return nil, fmt.Errorf("failed to execute GraphQL query for commit history of repository %s: %w", o, err)
+			}
+			if ref := q.Repository.DefaultBranchRef; ref != nil && ref.Target.Commit.History.TotalCount > 0 {
+				commitCounts[q.Repository.NameWithOwner] = ref.Target.Commit.History.TotalCount
+			}
+			continue
+		}
+		variables := map[string]interface{}{
+			"org":    githubv4.String(o),
+			"author": author,
+			"since":  since,
+			"until":  until,
+			"first":  githubv4.Int(g.countPageSize()),
+			"cursor": (*githubv4.String)(nil),
+		}
+		for {
+			var q orgCommitHistoryQuery
+			if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+				return nil, fmt.Errorf("failed to execute GraphQL query for commit history of organization %s: %w", o, err)
+			}
+			for _, repo := range q.Organization.Repositories.Nodes {
+				// Empty repositories have no default branch.
+				if repo.DefaultBranchRef == nil {
+					continue
+				}
+				if count := repo.DefaultBranchRef.Target.Commit.History.TotalCount; count > 0 {
+					commitCounts[repo.NameWithOwner] = count
+				}
+			}
+			if !q.Organization.Repositories.PageInfo.HasNextPage {
+				break
+			}
+			variables["cursor"] = githubv4.NewString(q.Organization.Repositories.PageInfo.EndCursor)
+			g.logger.Println("  Fetching next page of repositories...")
+		}
+	}
+	g.logger.Println("Completed fetching commit data.")
+	return commitCounts, nil
+}
+
+// parseCommitDateRange turns a commit search qualifier such as " author-date:2025-01-01..2025-01-31"
+// back into the since and until of the history connection; an open side is nil.
+// A bare date is a whole UTC day, so the end of the range is the last second of that day.
+func parseCommitDateRange(dateRange string) (since, until *githubv4.GitTimestamp, err error) {
+	if dateRange == "" {
+		return nil, nil, nil
+	}
+	bounds, ok := strings.CutPrefix(strings.TrimSpace(dateRange), "author-date:")
+	fromStr, toStr, ok2 := strings.Cut(bounds, "..")
+	if !ok || !ok2 {
+		return nil, nil, fmt.Errorf("unsupported commit date range %q", dateRange)
+	}
+	parse := func(value string, endOfDay bool) (*githubv4.GitTimestamp, error) {
+		if value == "*" {
+			return nil, nil
+		}
+		if t, err := time.Parse(searchDateTimeLayout, value); err == nil {
+			return &githubv4.GitTimestamp{Time: t}, nil
+		}
+		t, err := time.Parse(searchDateLayout, value)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported date %q in commit date range: %w", value, err)
+		}
+		if endOfDay {
+			t = t.AddDate(0, 0, 1).Add(-time.Second)
+		}
+		return &githubv4.GitTimestamp{Time: t}, nil
+	}
+	if since, err = parse(fromStr, false); err != nil {
+		return nil, nil, err
+	}
+	if until, err = parse(toStr, true); err != nil {
+		return nil, nil, err
+	}
+	return since, until, nil
+}
@@ -0,0 +1,154 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubGateway_FetchCommits_GraphQL(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Login  string            `json:"login"`
+				Org    string            `json:"org"`
+				Author map[string]string `json:"author"`
+				Since  *string           `json:"since"`
+				Until  *string           `json:"until"`
+				Cursor *string           `json:"cursor"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if strings.Contains(req.Query, "user(login: $login)") {
+			assert.Equal(t, "alice", req.Variables.Login)
+			fmt.Fprint(w, `{"data":{"user":{"id":"U_alice"}}}`)
+			return
+		}
+		assert.Contains(t, req.Query, "history(author: $author, since: $since, until: $until)")
+		assert.Equal(t, "acme", req.Variables.Org)
+		assert.Equal(t, map[string]string{"id": "U_alice"}, req.Variables.Author)
+		require.NotNil(t, req.Variables.Since)
+		require.NotNil(t, req.Variables.Until)
+		assert.Equal(t, "2025-01-01T00:00:00Z", *req.Variables.Since)
+		assert.Equal(t, "2025-01-31T23:59:59Z", *req.Variables.Until)
+		if req.Variables.Cursor == nil {
+			fmt.Fprint(w, `{"data":{"organization":{"repositories":{
+				"nodes":[
+					{"nameWithOwner":"acme/api","defaultBranchRef":{"target":{"history":{"totalCount":7}}}},
+					{"nameWithOwner":"acme/empty","defaultBranchRef":null},
+					{"nameWithOwner":"acme/untouched","defaultBranchRef":{"target":{"history":{"totalCount":0}}}}
+				],
+				"pageInfo":{"endCursor":"c1","hasNextPage":true}}}}}`)
+			return
+		}
+		assert.Equal(t, "c1", *req.Variables.Cursor)
+		fmt.Fprint(w, `{"data":{"organization":{"repositories":{
+			"nodes":[{"nameWithOwner":"acme/web","defaultBranchRef":{"target":{"history":{"totalCount":2}}}}],
+			"pageInfo":{"endCursor":"c2","hasNextPage":false}}}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+	WithCommitsSource(CommitsSourceGraphQL)(gateway)
+
+	counts, err := gateway.FetchCommits(context.Background(), "acme", "alice", " author-date:2025-01-01..2025-01-31")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"acme/api": 7, "acme/web": 2}, counts)
+}
+
+func TestGitHubGateway_FetchCommits_GraphQLRepo(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Owner string `json:"owner"`
+				Name  string `json:"name"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		if strings.Contains(req.Query, "user(login: $login)") {
+			fmt.Fprint(w, `{"data":{"user":{"id":"U_alice"}}}`)
+			return
+		}
+		// A repository scope reads that repository's history instead of listing an organization's repositories.
+		assert.Contains(t, req.Query, "repository(owner: $owner, name: $name)")
+		assert.NotContains(t, req.Query, "organization(")
+		assert.Equal(t, "acme", req.Variables.Owner)
+		assert.Equal(t, "api", req.Variables.Name)
+		fmt.Fprint(w, `{"data":{"repository":{"nameWithOwner":"acme/api","defaultBranchRef":{"target":{"history":{"totalCount":4}}}}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+	WithCommitsSource(CommitsSourceGraphQL)(gateway)
+
+	counts, err := gateway.FetchCommits(context.Background(), "acme/api", "alice", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"acme/api": 4}, counts)
+}
+
+func TestParseCommitDateRange(t *testing.T) {
+	tokyo := time.FixedZone("JST", 9*60*60)
+	testCases := []struct {
+		name      string
+		dateRange string
+		since     *time.Time
+		until     *time.Time
+	}{
+		{name: "no range"},
+		{
+			name:      "bare dates",
+			dateRange: " author-date:2025-01-01..2025-01-31",
+			since:     ptrTime(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)),
+			until:     ptrTime(time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)),
+		},
+		{
+			name:      "open start",
+			dateRange: " author-date:*..2025-01-31",
+			until:     ptrTime(time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC)),
+		},
+		{
+			name:      "timestamps",
+			dateRange: " author-date:2025-01-01T00:00:00+09:00..2025-01-31T23:59:59+09:00",
+			since:     ptrTime(time.Date(2025, 1, 1, 0, 0, 0, 0, tokyo)),
+			until:     ptrTime(time.Date(2025, 1, 31, 23, 59, 59, 0, tokyo)),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			since, until, err := parseCommitDateRange(tc.dateRange)
+			require.NoError(t, err)
+			if tc.since == nil {
+				assert.Nil(t, since)
+			} else {
+				require.NotNil(t, since)
+				assert.True(t, tc.since.Equal(since.Time), "since %v", since.Time)
+			}
+			if tc.until == nil {
+				assert.Nil(t, until)
+			} else {
+				require.NotNil(t, until)
+				assert.True(t, tc.until.Equal(until.Time), "until %v", until.Time)
+			}
+		})
+	}
+
+	_, _, err := parseCommitDateRange(" created:2025-01-01..2025-01-31")
+	assert.ErrorContains(t, err, "unsupported commit date range")
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func TestValidateCommitsSource(t *testing.T) {
+	assert.NoError(t, ValidateCommitsSource(CommitsSourceREST))
+	assert.NoError(t, ValidateCommitsSource(CommitsSourceGraphQL))
+	assert.ErrorContains(t, ValidateCommitsSource("git"), "unsupported commits source")
+}
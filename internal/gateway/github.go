@@ -3,10 +3,17 @@
 package gateway
 
 import (
+	"cmp"
 	"context"
+	"crypto/x509"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/google/go-github/v62/github"
@@ -18,17 +25,107 @@ import (
 
 // PRLeadTimeData holds the necessary timestamps for calculating lead time for a single PR.
 type PRLeadTimeData struct {
-	CreatedAt      time.Time
-	LastReviewedAt time.Time
+	Number    int
+	CreatedAt time.Time
+	// FirstReviewedAt and LastReviewedAt are zero when the PR was merged without any review.
+	FirstReviewedAt time.Time
+	LastReviewedAt  time.Time
+	// MergedAt is zero when the PR was closed without being merged.
+	MergedAt time.Time
+	// LastReopenedAt is zero when the PR was never reopened.
+	LastReopenedAt time.Time
+	Additions      int
+	Deletions      int
+}
+
+// PRSize holds the number of changed lines of a single PR.
+type PRSize struct {
+	Number    int
+	Additions int
+	Deletions int
+}
+
+// ReviewerLatencyData holds when a PR reviewed by the user became ready for review and when the user first reviewed it.
+type ReviewerLatencyData struct {
+	Number int
+	// ReadyAt is the latest time the PR was marked ready for review, or its creation time if it never was a draft.
+	ReadyAt       time.Time
+	FirstReviewAt time.Time
+}
+
+// AuthorWaitData holds when a PR created by the user was opened and first reviewed by someone else.
+type AuthorWaitData struct {
+	Number        int
+	CreatedAt     time.Time
+	FirstReviewAt time.Time
+}
+
+// RateLimit is the quota of one GitHub API resource, such as "core" or "search".
+type RateLimit struct {
+	Resource  string
+	Limit     int
+	Remaining int
+	Reset     time.Time
 }
 
 // Fetcher defines the behavior of a gateway for fetching information from GitHub.
+// The org argument of the fetch methods accepts a comma-separated list of organizations;
+// an "owner/name" entry limits the searches to that repository instead.
 type Fetcher interface {
 	FetchCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchCommitTimes returns the author date of every commit the user authored, per repository.
+	FetchCommitTimes(ctx context.Context, org, user, dateRange string) (map[string][]time.Time, error)
 	FetchCreatedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error)
 	FetchReviewedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchMergedPRs counts the pull requests created by the user that have been merged.
+	FetchMergedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchDraftPRs counts the pull requests created by the user that are still drafts.
+	FetchDraftPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	FetchCommentedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error)
 	// New method to fetch lead time data for pull requests.
 	FetchPRLeadTimes(ctx context.Context, org, user, dateRange string) (map[string][]PRLeadTimeData, error)
+	// FetchMergedPRCommits counts the user's commits that belong to merged pull requests authored by the user.
+	FetchMergedPRCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchCreatedIssues counts the issues opened by the user, and FetchClosedIssues the closed issues assigned to the user.
+	FetchCreatedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	FetchClosedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchAssignedPRs counts the pull requests assigned to the user, whoever authored them.
+	FetchAssignedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchOpenPRs counts the user's pull requests that are open now. It is a snapshot, so it takes no date range.
+	FetchOpenPRs(ctx context.Context, org, user string) (map[string]int, error)
+	// FetchReviewComments counts the inline comments of the reviews the user submitted.
+	FetchReviewComments(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchApprovals counts the pull requests the user approved in at least one review.
+	FetchApprovals(ctx context.Context, org, user, dateRange string) (map[string]int, error)
+	// FetchReviewerLatencies returns when each PR reviewed by the user became ready and when the user first reviewed it.
+	// PRs without a submitted review by the user, e.g. with only a pending one, are left out.
+	FetchReviewerLatencies(ctx context.Context, org, user, dateRange string) (map[string][]ReviewerLatencyData, error)
+	// FetchAuthorWaits returns when each PR created by the user was opened and first reviewed by someone else.
+	// PRs without such a review yet are left out.
+	FetchAuthorWaits(ctx context.Context, org, user, dateRange string) (map[string][]AuthorWaitData, error)
+	// FetchPRSizes returns the additions and deletions of every PR created by the user.
+	FetchPRSizes(ctx context.Context, org, user, dateRange string) (map[string][]PRSize, error)
+	// FetchRepoCommitTotals and FetchRepoPRTotals count activity by all authors in the given repositories.
+	FetchRepoCommitTotals(ctx context.Context, repos []string, dateRange string) (map[string]int, error)
+	// FetchCommitChurn returns the lines the user added and deleted with commits to each "owner/name" repository.
+	FetchCommitChurn(ctx context.Context, repos []string, user, dateRange string) (map[string]CommitChurn, error)
+	// FetchRepoPRTotals leaves out drafts when excludeDrafts is set, matching created PRs counted without them.
+	FetchRepoPRTotals(ctx context.Context, repos []string, dateRange string, excludeDrafts bool) (map[string]int, error)
+	// CheckOrgAccess reports whether the token belongs to an active member of the organization,
+	// which is required to see contributions to its private repositories.
+	CheckOrgAccess(ctx context.Context, org string) (bool, error)
+	// OrgExists and UserExists report whether the organization or user account exists.
+	OrgExists(ctx context.Context, org string) (bool, error)
+	UserExists(ctx context.Context, user string) (bool, error)
+	// FetchRepoNodeIDs resolves the GraphQL node ID of each "owner/name" repository.
+	FetchRepoNodeIDs(ctx context.Context, repos []string) (map[string]string, error)
+	// FetchRepoLanguages returns the primary language of each "owner/name" repository.
+	// Repositories without a detected language are left out.
+	FetchRepoLanguages(ctx context.Context, repos []string) (map[string]string, error)
+	// FetchTeamMembers returns the logins of the members of the team with the given slug in org.
+	FetchTeamMembers(ctx context.Context, org, teamSlug string) ([]string, error)
+	// FetchRateLimit returns the remaining quota of the core, search and GraphQL APIs.
+	FetchRateLimit(ctx context.Context) ([]RateLimit, error)
 }
 
 // GitHubGateway is the concrete implementation of the Fetcher interface.
@@ -36,12 +133,306 @@ type GitHubGateway struct {
 	restClient    *github.Client
 	graphqlClient *githubv4.Client
 	logger        *log.Logger
+	pageSizes     PageSizes
+	baseURL       string
+	// restURL and graphqlURL are explicit endpoints from WithAPIURLs; baseURL takes precedence over them.
+	restURL    string
+	graphqlURL string
+	// maxRetries and retryBaseDelay are only used when building the HTTP client in NewGitHubGateway.
+	maxRetries     int
+	retryBaseDelay time.Duration
+	progress       Progress
+	dedupeCommits  bool
+	commitsSource  string
+	// reviewStates are the review states FetchPRLeadTimes counts; nil means DefaultReviewStates.
+	reviewStates []string
+	// excludeBots leaves bot-authored PRs out of FetchReviewedPRs; botLogins are extra logins treated as bots.
+	excludeBots bool
+	botLogins   []string
+	// labels limit the created, merged and reviewed PR searches and the lead time search to pull requests carrying all of them.
+	labels []string
+	// statsPollAttempts and statsPollDelay control how FetchCommitChurn waits for GitHub to compute
+	// contributor statistics; zero means defaultStatsPollAttempts and defaultStatsPollDelay.
+	statsPollAttempts int
+	statsPollDelay    time.Duration
+	// proxyURL overrides the proxy from the environment; it is only used when building the HTTP client.
+	proxyURL *url.URL
+	// rootCAs and insecureSkipVerify configure the TLS checks of the HTTP client built in NewGitHubGateway.
+	rootCAs            *x509.CertPool
+	insecureSkipVerify bool
+	// httpDebug receives a line per HTTP request when set by WithHTTPDebug.
+	httpDebug io.Writer
+	// maxSleep is the single sleep limit of the secondary rate limit waiter.
+	maxSleep time.Duration
+	// sleeps records the secondary rate limit pauses when set by WithRateLimitSleeps.
+	sleeps *RateLimitSleeps
+	// caveats records what leaves the results incomplete when set by WithCaveats.
+	caveats *Caveats
+	// retryStats counts the retries per phase when set by WithRetryStats.
+	retryStats *RetryStats
+}
+
+// Progress is notified whenever the gateway starts a fetch step, e.g. to drive a progress indicator.
+// Steps of concurrent fetches may be reported from several goroutines at once.
+type Progress interface {
+	Step(description string)
+}
+
+// WithProgress reports every fetch step to progress in addition to the logger.
+func WithProgress(progress Progress) Option {
+	return func(g *GitHubGateway) {
+		g.progress = progress
+	}
+}
+
+// step logs the start of a fetch step and reports it to the progress listener, if any.
+// The returned context tags the step's requests with phase, under which their retries are counted.
+func (g *GitHubGateway) step(ctx context.Context, phase, description string) context.Context {
+	g.logger.Println(description)
+	if g.progress != nil {
+		g.progress.Step(description)
+	}
+	return withPhase(ctx, phase)
+}
+
+// PageSizes sets how many results each kind of search requests per page.
+// Smaller pages are slower but less likely to time out on flaky networks. A zero field keeps the default.
+type PageSizes struct {
+	// Counts is used by the commit search and the pull request count queries. It defaults to 100.
+	Counts int
+	// PullRequests is used by the lead time and merged PR commit queries, which fetch nested reviews
+	// and commits for every result. It defaults to 20.
+	PullRequests int
+}
+
+const (
+	defaultCountPageSize       = 100
+	defaultPullRequestPageSize = 20
+	// MaxPageSize is the largest page the GitHub search APIs return.
+	MaxPageSize = 100
+)
+
+// Option configures a GitHubGateway.
+type Option func(*GitHubGateway)
+
+// WithPageSizes overrides the page sizes of the search queries.
+func WithPageSizes(sizes PageSizes) Option {
+	return func(g *GitHubGateway) {
+		g.pageSizes = sizes
+	}
+}
+
+// WithCommitDedupe makes FetchCommits count every commit SHA only once per repository,
+// even when the search API returns it several times.
+func WithCommitDedupe() Option {
+	return func(g *GitHubGateway) {
+		g.dedupeCommits = true
+	}
+}
+
+// WithBotExclusion makes FetchReviewedPRs skip pull requests authored by bots, such as dependabot or renovate.
+// Besides GitHub Apps and logins ending in "[bot]", the given logins count as bots, ignoring case.
+func WithBotExclusion(logins []string) Option {
+	return func(g *GitHubGateway) {
+		g.excludeBots = true
+		g.botLogins = logins
+	}
+}
+
+// isBot reports whether the author of a pull request is a bot.
+func (g *GitHubGateway) isBot(typename, login string) bool {
+	if typename == "Bot" || strings.HasSuffix(login, "[bot]") {
+		return true
+	}
+	for _, bot := range g.botLogins {
+		if strings.EqualFold(login, bot) {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultReviewStates are the review states that count as a review for the lead times unless WithReviewStates says otherwise.
+var DefaultReviewStates = []string{"APPROVED", "CHANGES_REQUESTED", "COMMENTED"}
+
+// reviewStates are the values of GitHub's PullRequestReviewState enum.
+// PENDING is left out: a pending review has not been submitted, so it has no time to count as reviewed at.
+var reviewStates = []string{"APPROVED", "CHANGES_REQUESTED", "COMMENTED", "DISMISSED"}
+
+// ParseReviewStates validates review state names against GitHub's PullRequestReviewState enum, ignoring case.
+// It returns them upper-cased, sorted and without duplicates, so equal sets compare equal.
+func ParseReviewStates(states []string) ([]string, error) {
+	var parsed []string
+	for _, state := range states {
+		name := strings.ToUpper(strings.TrimSpace(state))
+		if !slices.Contains(reviewStates, name) {
+			return nil, fmt.Errorf("unknown review state %q (valid: %s)", state, strings.Join(reviewStates, ", "))
+		}
+		parsed = append(parsed, name)
+	}
+	if len(parsed) == 0 {
+		return nil, fmt.Errorf("at least one review state is required")
+	}
+	slices.Sort(parsed)
+	return slices.Compact(parsed), nil
+}
+
+// WithReviewStates makes FetchPRLeadTimes count only reviews in the given states, as returned by ParseReviewStates.
+// For example, only APPROVED measures the lead time to approval.
+func WithReviewStates(states []string) Option {
+	return func(g *GitHubGateway) {
+		g.reviewStates = states
+	}
+}
+
+// WithLabels limits the created, merged and reviewed PR searches, the lead time search, and the fetches built on them, to pull requests
+// carrying every one of the given labels.
+func WithLabels(labels []string) Option {
+	return func(g *GitHubGateway) {
+		g.labels = labels
+	}
+}
+
+// WithBaseURL points the gateway at a GitHub Enterprise Server instead of github.com.
+// baseURL may be the server root, its REST endpoint (.../api/v3) or its GraphQL endpoint (.../api/graphql);
+// the other endpoint is derived following the GHES conventions.
+func WithBaseURL(baseURL string) Option {
+	return func(g *GitHubGateway) {
+		g.baseURL = baseURL
+	}
+}
+
+// WithAPIURLs points the gateway at the given REST and GraphQL endpoints, such as GitHub Actions provides in
+// GITHUB_API_URL and GITHUB_GRAPHQL_URL. Either may be empty to derive it from the other; WithBaseURL wins over both.
+func WithAPIURLs(restURL, graphqlURL string) Option {
+	return func(g *GitHubGateway) {
+		g.restURL = restURL
+		g.graphqlURL = graphqlURL
+	}
+}
+
+// publicAPIHost is the host of the github.com REST and GraphQL APIs, which the clients use by default.
+const publicAPIHost = "api.github.com"
+
+// ActionsAPIURLs returns the REST and GraphQL endpoints GitHub Actions sets in GITHUB_API_URL and GITHUB_GRAPHQL_URL.
+// On github.com they are the public API, which needs no configuration, so both are returned empty then.
+func ActionsAPIURLs(getenv func(string) string) (restURL, graphqlURL string) {
+	restURL, graphqlURL = getenv("GITHUB_API_URL"), getenv("GITHUB_GRAPHQL_URL")
+	for _, endpoint := range []string{restURL, graphqlURL} {
+		if u, err := url.Parse(endpoint); err == nil && strings.EqualFold(u.Host, publicAPIHost) {
+			return "", ""
+		}
+	}
+	return restURL, graphqlURL
+}
+
+// enterpriseURLs derives the REST and GraphQL endpoints of a GitHub Enterprise Server from baseURL.
+func enterpriseURLs(baseURL string) (restURL, graphqlURL string, err error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", "", fmt.Errorf("invalid base URL %q: must be an absolute URL such as https://github.example.com", baseURL)
+	}
+	root := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/api/v3"), "/api/graphql")
+	u.Path = root + "/api/v3/"
+	restURL = u.String()
+	u.Path = root + "/api/graphql"
+	graphqlURL = u.String()
+	return restURL, graphqlURL, nil
+}
+
+// ValidatePageSize returns an error when size is not a valid page size.
+func ValidatePageSize(size int) error {
+	if size < 1 || size > MaxPageSize {
+		return fmt.Errorf("page size must be between 1 and %d, got %d", MaxPageSize, size)
+	}
+	return nil
+}
+
+func (g *GitHubGateway) countPageSize() int {
+	if g.pageSizes.Counts > 0 {
+		return g.pageSizes.Counts
+	}
+	return defaultCountPageSize
+}
+
+func (g *GitHubGateway) pullRequestPageSize() int {
+	if g.pageSizes.PullRequests > 0 {
+		return g.pageSizes.PullRequests
+	}
+	return defaultPullRequestPageSize
 }
 
 // searchIssuesQuery is for the simple PR count queries.
 type searchIssuesQuery struct {
 	Search struct {
-		PageInfo struct {
+		IssueCount int
+		PageInfo   struct {
+			HasNextPage bool
+			EndCursor   githubv4.String
+		}
+		Edges []struct {
+			Node struct {
+				Typename    string                  `graphql:"__typename"`
+				PullRequest searchIssuesPullRequest `graphql:"... on PullRequest"`
+				Issue       struct {
+					Repository struct {
+						NameWithOwner string
+					}
+				} `graphql:"... on Issue"`
+			}
+		}
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"`
+}
+
+// searchIssuesPullRequest is the part of a pull request searchIssuesQuery reads.
+type searchIssuesPullRequest struct {
+	Repository struct {
+		NameWithOwner string
+	}
+	IsDraft bool
+	// Author is null for deleted accounts. GraphQL reports GitHub Apps as Bot actors without the "[bot]" login suffix.
+	Author struct {
+		Typename string `graphql:"__typename"`
+		Login    string
+	}
+}
+
+// searchIssueCountQuery only asks for the total number of matches, which is all we need for org-wide totals.
+type searchIssueCountQuery struct {
+	Search struct {
+		IssueCount int
+	} `graphql:"search(query: $query, type: ISSUE)"`
+}
+
+// repositoryIDQuery looks up a single repository by owner and name.
+type repositoryIDQuery struct {
+	Repository struct {
+		ID githubv4.ID
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// repoLanguagesQuery looks up the primary language of the repositories matched by a repository search.
+type repoLanguagesQuery struct {
+	Search struct {
+		Nodes []struct {
+			Repository struct {
+				NameWithOwner   string
+				PrimaryLanguage *struct {
+					Name string
+				}
+			} `graphql:"... on Repository"`
+		}
+	} `graphql:"search(query: $query, type: REPOSITORY, first: $first)"`
+}
+
+// repoLanguagesBatchSize is how many repositories FetchRepoLanguages looks up per search.
+const repoLanguagesBatchSize = 20
+
+// prLeadTimeQuery defines the structure for the more complex GraphQL query to fetch lead times.
+type prLeadTimeQuery struct {
+	Search struct {
+		IssueCount int
+		PageInfo   struct {
 			HasNextPage bool
 			EndCursor   githubv4.String
 		}
@@ -52,16 +443,61 @@ type searchIssuesQuery struct {
 					Repository struct {
 						NameWithOwner string
 					}
+					Number    int
+					CreatedAt githubv4.DateTime
+					MergedAt  *githubv4.DateTime
+					Additions int
+					Deletions int
+					Reviews   struct {
+						Nodes []struct {
+							// SubmittedAt is null for a pending review.
+							SubmittedAt *githubv4.DateTime
+						}
+					} `graphql:"reviews(first: 100, states: $states)"`
+					TimelineItems struct {
+						Nodes []struct {
+							ReopenedEvent struct {
+								CreatedAt githubv4.DateTime
+							} `graphql:"... on ReopenedEvent"`
+						}
+					} `graphql:"timelineItems(last: 1, itemTypes: [REOPENED_EVENT])"`
 				} `graphql:"... on PullRequest"`
 			}
 		}
-	} `graphql:"search(query: $query, type: ISSUE, first: 100, after: $cursor)"`
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"` // Uses the smaller pull request page size
 }
 
-// prLeadTimeQuery defines the structure for the more complex GraphQL query to fetch lead times.
-type prLeadTimeQuery struct {
+// reviewStatesQuery fetches the state of the user's reviews of each pull request.
+type reviewStatesQuery struct {
+	Search struct {
+		IssueCount int
+		PageInfo   struct {
+			HasNextPage bool
+			EndCursor   githubv4.String
+		}
+		Edges []struct {
+			Node struct {
+				Typename    string `graphql:"__typename"`
+				PullRequest struct {
+					Repository struct {
+						NameWithOwner string
+					}
+					Reviews struct {
+						Nodes []struct {
+							State githubv4.PullRequestReviewState
+						}
+					} `graphql:"reviews(first: 100, author: $user)"`
+				} `graphql:"... on PullRequest"`
+			}
+		}
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"`
+}
+
+// reviewerLatencyQuery fetches the ready-for-review time of each pull request and the submission times of the user's reviews.
+type reviewerLatencyQuery struct {
 	Search struct {
-		PageInfo struct {
+		IssueCount int
+		PageInfo   struct {
 			HasNextPage bool
 			EndCursor   githubv4.String
 		}
@@ -72,21 +508,174 @@ type prLeadTimeQuery struct {
 					Repository struct {
 						NameWithOwner string
 					}
+					Number    int
 					CreatedAt githubv4.DateTime
 					Reviews   struct {
 						Nodes []struct {
-							SubmittedAt githubv4.DateTime
+							// SubmittedAt is null for a pending review.
+							SubmittedAt *githubv4.DateTime
+						}
+					} `graphql:"reviews(first: 100, author: $user)"`
+					TimelineItems struct {
+						Nodes []struct {
+							ReadyForReviewEvent struct {
+								CreatedAt githubv4.DateTime
+							} `graphql:"... on ReadyForReviewEvent"`
+						}
+					} `graphql:"timelineItems(last: 1, itemTypes: [READY_FOR_REVIEW_EVENT])"`
+				} `graphql:"... on PullRequest"`
+			}
+		}
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"`
+}
+
+// authorWaitQuery fetches the submitted reviews of each pull request the user created, with their authors.
+type authorWaitQuery struct {
+	Search struct {
+		IssueCount int
+		PageInfo   struct {
+			HasNextPage bool
+			EndCursor   githubv4.String
+		}
+		Edges []struct {
+			Node struct {
+				Typename    string `graphql:"__typename"`
+				PullRequest struct {
+					Repository struct {
+						NameWithOwner string
+					}
+					Number    int
+					CreatedAt githubv4.DateTime
+					Reviews   struct {
+						Nodes []struct {
+							// Author is null for a deleted account.
+							Author *struct {
+								Login string
+							}
+							// SubmittedAt is null for a pending review.
+							SubmittedAt *githubv4.DateTime
+						}
+					} `graphql:"reviews(first: 100)"`
+				} `graphql:"... on PullRequest"`
+			}
+		}
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"`
+}
+
+// reviewCommentsQuery fetches the user's reviews of each pull request with their number of comments.
+type reviewCommentsQuery struct {
+	Search struct {
+		IssueCount int
+		PageInfo   struct {
+			HasNextPage bool
+			EndCursor   githubv4.String
+		}
+		Edges []struct {
+			Node struct {
+				Typename    string `graphql:"__typename"`
+				PullRequest struct {
+					Repository struct {
+						NameWithOwner string
+					}
+					Reviews struct {
+						Nodes []struct {
+							Comments struct {
+								TotalCount int
+							}
+						}
+					} `graphql:"reviews(first: 100, author: $user)"`
+				} `graphql:"... on PullRequest"`
+			}
+		}
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"`
+}
+
+// prSizeQuery fetches the size of each pull request.
+type prSizeQuery struct {
+	Search struct {
+		IssueCount int
+		PageInfo   struct {
+			HasNextPage bool
+			EndCursor   githubv4.String
+		}
+		Edges []struct {
+			Node struct {
+				Typename    string `graphql:"__typename"`
+				PullRequest struct {
+					Repository struct {
+						NameWithOwner string
+					}
+					Number    int
+					Additions int
+					Deletions int
+				} `graphql:"... on PullRequest"`
+			}
+		}
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"`
+}
+
+// mergedPRCommitsQuery fetches the commits of merged pull requests together with their authors.
+type mergedPRCommitsQuery struct {
+	Search struct {
+		IssueCount int
+		PageInfo   struct {
+			HasNextPage bool
+			EndCursor   githubv4.String
+		}
+		Edges []struct {
+			Node struct {
+				Typename    string `graphql:"__typename"`
+				PullRequest struct {
+					Repository struct {
+						NameWithOwner string
+					}
+					Commits struct {
+						Nodes []struct {
+							Commit struct {
+								Oid    string
+								Author struct {
+									User struct {
+										Login string
+									}
+								}
+							}
 						}
-					} `graphql:"reviews(first: 100, states: [COMMENTED, APPROVED, CHANGES_REQUESTED])"`
+					} `graphql:"commits(first: 100)"`
 				} `graphql:"... on PullRequest"`
 			}
 		}
-	} `graphql:"search(query: $query, type: ISSUE, first: 20, after: $cursor)"` // Use a smaller page size for this complex query
+	} `graphql:"search(query: $query, type: ISSUE, first: $first, after: $cursor)"`
 }
 
 // NewGitHubGateway is a constructor that creates a new instance of GitHubGateway.
-func NewGitHubGateway(token string, logger *log.Logger) (Fetcher, error) {
-	rateLimitWaiter, err := github_ratelimit.NewRateLimitWaiter(nil, github_ratelimit.WithSingleSleepLimit(1*time.Hour, nil))
+func NewGitHubGateway(token string, logger *log.Logger, opts ...Option) (Fetcher, error) {
+	g := &GitHubGateway{
+		logger:         logger,
+		maxRetries:     DefaultMaxRetries,
+		retryBaseDelay: DefaultRetryBaseDelay,
+		maxSleep:       DefaultMaxSleep,
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	// Retries sit below the rate limit waiter, so a retried request still honors the rate limit.
+	// The debug log sits below the retries to show every attempt, with the token already added.
+	var base http.RoundTripper = g.baseTransport()
+	if g.httpDebug != nil {
+		base = &debugTransport{base: base, w: g.httpDebug}
+	}
+	if g.caveats != nil {
+		base = &scopeCheckTransport{base: base, caveats: g.caveats}
+	}
+	retrier := &retryTransport{
+		base:       base,
+		maxRetries: g.maxRetries,
+		baseDelay:  g.retryBaseDelay,
+		logger:     logger,
+		stats:      g.retryStats,
+	}
+	rateLimitWaiter, err := github_ratelimit.NewRateLimitWaiter(retrier, g.secondaryRateLimitOptions()...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create rate limit waiter: %w", err)
 	}
@@ -97,114 +686,759 @@ func NewGitHubGateway(token string, logger *log.Logger) (Fetcher, error) {
 			Source: ts,
 		},
 	}
-	return &GitHubGateway{
-		restClient:    github.NewClient(httpClient),
-		graphqlClient: githubv4.NewClient(httpClient),
-		logger:        logger,
-	}, nil
+	g.restClient = github.NewClient(httpClient)
+	g.graphqlClient = githubv4.NewClient(httpClient)
+	if g.baseURL != "" {
+		restURL, graphqlURL, err := enterpriseURLs(g.baseURL)
+		if err != nil {
+			return nil, err
+		}
+		if g.restClient, err = g.restClient.WithEnterpriseURLs(restURL, restURL); err != nil {
+			return nil, fmt.Errorf("failed to configure REST client for %s: %w", restURL, err)
+		}
+		g.graphqlClient = githubv4.NewEnterpriseClient(graphqlURL, httpClient)
+	} else if g.restURL != "" || g.graphqlURL != "" {
+		restURL, graphqlURL, err := enterpriseURLs(cmp.Or(g.restURL, g.graphqlURL))
+		if err != nil {
+			return nil, err
+		}
+		if g.restURL != "" {
+			restURL = strings.TrimRight(g.restURL, "/") + "/"
+		}
+		graphqlURL = cmp.Or(g.graphqlURL, graphqlURL)
+		if g.restClient, err = g.restClient.WithEnterpriseURLs(restURL, restURL); err != nil {
+			return nil, fmt.Errorf("failed to configure REST client for %s: %w", restURL, err)
+		}
+		g.graphqlClient = githubv4.NewEnterpriseClient(graphqlURL, httpClient)
+	}
+	for _, size := range []int{g.pageSizes.Counts, g.pageSizes.PullRequests} {
+		if size != 0 {
+			if err := ValidatePageSize(size); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return g, nil
 }
 
 func (g *GitHubGateway) FetchCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
-	g.logger.Println("[1/4] Fetching commit data using REST API...")
-	query := fmt.Sprintf("org:%s author:%s%s", org, user, dateRange)
-	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	if g.commitsSource == CommitsSourceGraphQL {
+		return g.fetchCommitsGraphQL(ctx, org, user, dateRange)
+	}
+	ctx = g.step(ctx, "commits", "[1/4] Fetching commit data using REST API...")
 	commitCounts := make(map[string]int)
+	err := g.searchCommits(ctx, CommitsQuery(org, user, dateRange), func(repoName string, _ *github.CommitResult) {
+		commitCounts[repoName]++
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.logger.Println("Completed fetching commit data.")
+	return commitCounts, nil
+}
+
+// FetchCommitTimes returns the author date of every commit the user authored, per repository.
+// It always uses the REST commit search, whatever the commits source.
+func (g *GitHubGateway) FetchCommitTimes(ctx context.Context, org, user, dateRange string) (map[string][]time.Time, error) {
+	ctx = g.step(ctx, "commit times", "Fetching commit times...")
+	times := make(map[string][]time.Time)
+	err := g.searchCommits(ctx, CommitsQuery(org, user, dateRange), func(repoName string, commit *github.CommitResult) {
+		times[repoName] = append(times[repoName], commit.GetCommit().GetAuthor().GetDate().Time)
+	})
+	if err != nil {
+		return nil, err
+	}
+	g.logger.Println("Completed fetching commit times.")
+	return times, nil
+}
+
+// searchCommits pages through the REST commit search and calls visit with every commit and its "owner/name" repository.
+// With WithCommitDedupe, a commit found again in the same repository is only visited once.
+func (g *GitHubGateway) searchCommits(ctx context.Context, query string, visit func(repoName string, commit *github.CommitResult)) error {
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: g.countPageSize()}}
+	seen := make(map[string]map[string]bool)
 	for {
 		result, resp, err := g.restClient.Search.Commits(ctx, query, opts)
 		if err != nil {
-			return nil, fmt.Errorf("failed to search commits with REST API: %w", err)
+			return fmt.Errorf("failed to search commits with REST API: %w", err)
 		}
+		g.checkSearchCap(query, result.GetTotal(), result.GetIncompleteResults())
 		for _, commit := range result.Commits {
 			repoName := commit.GetRepository().GetFullName()
-			commitCounts[repoName]++
+			if g.dedupeCommits {
+				if seen[repoName] == nil {
+					seen[repoName] = make(map[string]bool)
+				}
+				if seen[repoName][commit.GetSHA()] {
+					continue
+				}
+				seen[repoName][commit.GetSHA()] = true
+			}
+			visit(repoName, commit)
 		}
 		if resp.NextPage == 0 {
-			break
+			return nil
 		}
 		opts.Page = resp.NextPage
 		g.logger.Println("  Fetching next page of commits...")
 	}
-	g.logger.Println("Completed fetching commit data.")
+}
+
+// FetchMergedPRCommits counts commits authored by the user on the user's merged pull requests.
+// Commits are deduplicated by SHA per repository, and only the first 100 commits of each PR are inspected.
+func (g *GitHubGateway) FetchMergedPRCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "merged PR commits", "[1/4] Fetching commits on merged PRs...")
+	query := MergedPRCommitsQuery(org, user, dateRange)
+	variables := map[string]interface{}{
+		"query":  githubv4.String(query),
+		"first":  githubv4.Int(g.pullRequestPageSize()),
+		"cursor": (*githubv4.String)(nil),
+	}
+	seen := make(map[string]map[string]bool)
+	for {
+		var q mergedPRCommitsQuery
+		if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for merged PR commits: %w", err)
+		}
+		for _, edge := range q.Search.Edges {
+			prNode := edge.Node.PullRequest
+			if edge.Node.Typename != "PullRequest" {
+				continue
+			}
+			repoName := prNode.Repository.NameWithOwner
+			for _, node := range prNode.Commits.Nodes {
+				if !strings.EqualFold(node.Commit.Author.User.Login, user) {
+					continue // Skip commits pushed to the PR by someone else.
+				}
+				if seen[repoName] == nil {
+					seen[repoName] = make(map[string]bool)
+				}
+				seen[repoName][node.Commit.Oid] = true
+			}
+		}
+		g.checkSearchCap(query, q.Search.IssueCount, false)
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
+		g.logger.Println("  Fetching next page of merged PRs for commits...")
+	}
+
+	commitCounts := make(map[string]int, len(seen))
+	for repoName, shas := range seen {
+		commitCounts[repoName] = len(shas)
+	}
+	g.logger.Println("Completed fetching commits on merged PRs.")
 	return commitCounts, nil
 }
 
 func (g *GitHubGateway) FetchCreatedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
-	g.logger.Println("[2/4] Fetching created PR data...")
-	query := fmt.Sprintf("org:%s author:%s is:pr%s", org, user, dateRange)
-	return g.fetchPRCounts(ctx, query)
+	ctx = g.step(ctx, "created PRs", "[2/4] Fetching created PR data...")
+	query := CreatedPRsQuery(org, user, dateRange, g.labels)
+	return g.fetchSearchCounts(ctx, query)
+}
+
+// FetchMergedPRs counts the user's merged pull requests. dateRange filters on the creation date like FetchCreatedPRs,
+// so the result is the part of the created PRs that got merged, whenever that happened.
+func (g *GitHubGateway) FetchMergedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "merged PRs", "Fetching merged PR data...")
+	query := MergedPRsQuery(org, user, dateRange, g.labels)
+	return g.fetchSearchCounts(ctx, query)
+}
+
+// FetchDraftPRs runs the created PR search again, counting only the pull requests that are drafts.
+func (g *GitHubGateway) FetchDraftPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "draft PRs", "Fetching draft PR data...")
+	query := CreatedPRsQuery(org, user, dateRange, g.labels)
+	return g.fetchSearchCountsWhere(ctx, query, func(pr searchIssuesPullRequest) bool { return pr.IsDraft })
 }
 
 func (g *GitHubGateway) FetchReviewedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
-	g.logger.Println("[3/4] Fetching reviewed PR data...")
-	query := fmt.Sprintf("org:%s reviewed-by:%s is:pr%s", org, user, dateRange)
-	return g.fetchPRCounts(ctx, query)
+	ctx = g.step(ctx, "reviewed PRs", "[3/4] Fetching reviewed PR data...")
+	query := ReviewedPRsQuery(org, user, dateRange, g.labels)
+	if g.excludeBots {
+		return g.fetchSearchCountsWhere(ctx, query, func(pr searchIssuesPullRequest) bool {
+			return !g.isBot(pr.Author.Typename, pr.Author.Login)
+		})
+	}
+	return g.fetchSearchCounts(ctx, query)
+}
+
+// FetchCommentedPRs counts pull requests on which the user left at least one comment.
+func (g *GitHubGateway) FetchCommentedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "commented PRs", "Fetching commented PR data...")
+	query := CommentedPRsQuery(org, user, dateRange)
+	return g.fetchSearchCounts(ctx, query)
+}
+
+// FetchCreatedIssues counts issues opened by the user.
+func (g *GitHubGateway) FetchCreatedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "created issues", "Fetching created issue data...")
+	query := CreatedIssuesQuery(org, user, dateRange)
+	return g.fetchSearchCounts(ctx, query)
+}
+
+// FetchClosedIssues counts closed issues assigned to the user.
+func (g *GitHubGateway) FetchClosedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "closed issues", "Fetching closed issue data...")
+	query := ClosedIssuesQuery(org, user, dateRange)
+	return g.fetchSearchCounts(ctx, query)
+}
+
+// FetchAssignedPRs counts the pull requests assigned to the user.
+func (g *GitHubGateway) FetchAssignedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "assigned PRs", "Fetching assigned PR data...")
+	query := AssignedPRsQuery(org, user, dateRange)
+	return g.fetchSearchCounts(ctx, query)
 }
 
-func (g *GitHubGateway) fetchPRCounts(ctx context.Context, query string) (map[string]int, error) {
-	variables := map[string]interface{}{"query": githubv4.String(query), "cursor": (*githubv4.String)(nil)}
-	prCounts := make(map[string]int)
+// FetchOpenPRs counts the user's currently open pull requests, whenever they were created.
+func (g *GitHubGateway) FetchOpenPRs(ctx context.Context, org, user string) (map[string]int, error) {
+	ctx = g.step(ctx, "open PRs", "Fetching open PR data...")
+	query := OpenPRsQuery(org, user)
+	return g.fetchSearchCounts(ctx, query)
+}
+
+// FetchReviewComments counts the comments the user left in reviews of pull requests, per repository.
+// Only the first 100 reviews by the user on each PR are inspected.
+func (g *GitHubGateway) FetchReviewComments(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "review comments", "Fetching review comment data...")
+	query := ReviewedPRsQuery(org, user, dateRange, g.labels)
+	variables := map[string]interface{}{
+		"query":  githubv4.String(query),
+		"user":   githubv4.String(user),
+		"first":  githubv4.Int(g.pullRequestPageSize()),
+		"cursor": (*githubv4.String)(nil),
+	}
+	commentCounts := make(map[string]int)
 	for {
-		var q searchIssuesQuery
+		var q reviewCommentsQuery
+		if err := g.queryAllowingPartial(ctx, &q, variables, func() bool { return len(q.Search.Edges) > 0 }); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for review comments: %w", err)
+		}
+		for _, edge := range q.Search.Edges {
+			if edge.Node.Typename != "PullRequest" {
+				continue
+			}
+			prNode := edge.Node.PullRequest
+			for _, review := range prNode.Reviews.Nodes {
+				commentCounts[prNode.Repository.NameWithOwner] += review.Comments.TotalCount
+			}
+		}
+		g.checkSearchCap(query, q.Search.IssueCount, false)
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
+		g.logger.Println("  Fetching next page of reviewed PRs for comments...")
+	}
+	g.logger.Println("Completed fetching review comment data.")
+	return commentCounts, nil
+}
+
+// FetchApprovals counts the reviewed pull requests in which at least one of the user's reviews is an approval.
+// Comment-only and changes-requested reviews are not counted, and approving the same PR again after new pushes counts once.
+func (g *GitHubGateway) FetchApprovals(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "approvals", "Fetching approval data...")
+	query := ReviewedPRsQuery(org, user, dateRange, g.labels)
+	variables := map[string]interface{}{
+		"query":  githubv4.String(query),
+		"user":   githubv4.String(user),
+		"first":  githubv4.Int(g.pullRequestPageSize()),
+		"cursor": (*githubv4.String)(nil),
+	}
+	approvalCounts := make(map[string]int)
+	for {
+		var q reviewStatesQuery
+		if err := g.queryAllowingPartial(ctx, &q, variables, func() bool { return len(q.Search.Edges) > 0 }); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for approvals: %w", err)
+		}
+		for _, edge := range q.Search.Edges {
+			if edge.Node.Typename != "PullRequest" {
+				continue
+			}
+			prNode := edge.Node.PullRequest
+			for _, review := range prNode.Reviews.Nodes {
+				if review.State == githubv4.PullRequestReviewStateApproved {
+					approvalCounts[prNode.Repository.NameWithOwner]++
+					break
+				}
+			}
+		}
+		g.checkSearchCap(query, q.Search.IssueCount, false)
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
+		g.logger.Println("  Fetching next page of reviewed PRs for approvals...")
+	}
+	g.logger.Println("Completed fetching approval data.")
+	return approvalCounts, nil
+}
+
+// FetchReviewerLatencies fetches the PRs the user reviewed with the time they became ready and the user's earliest submitted review.
+func (g *GitHubGateway) FetchReviewerLatencies(ctx context.Context, org, user, dateRange string) (map[string][]ReviewerLatencyData, error) {
+	ctx = g.step(ctx, "reviewer latencies", "Fetching reviewer latency data...")
+	query := ReviewedPRsQuery(org, user, dateRange, g.labels)
+	variables := map[string]interface{}{
+		"query":  githubv4.String(query),
+		"user":   githubv4.String(user),
+		"first":  githubv4.Int(g.pullRequestPageSize()),
+		"cursor": (*githubv4.String)(nil),
+	}
+	latenciesByRepo := make(map[string][]ReviewerLatencyData)
+	for {
+		var q reviewerLatencyQuery
+		if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for reviewer latencies: %w", err)
+		}
+		for _, edge := range q.Search.Edges {
+			if edge.Node.Typename != "PullRequest" {
+				continue
+			}
+			prNode := edge.Node.PullRequest
+			var firstReviewAt time.Time
+			for _, review := range prNode.Reviews.Nodes {
+				if review.SubmittedAt != nil && (firstReviewAt.IsZero() || review.SubmittedAt.Before(firstReviewAt)) {
+					firstReviewAt = review.SubmittedAt.Time
+				}
+			}
+			if firstReviewAt.IsZero() {
+				continue // Only a pending review, or none, so the user hasn't responded yet.
+			}
+			readyAt := prNode.CreatedAt.Time
+			if events := prNode.TimelineItems.Nodes; len(events) > 0 {
+				readyAt = events[0].ReadyForReviewEvent.CreatedAt.Time
+			}
+			repoName := prNode.Repository.NameWithOwner
+			latenciesByRepo[repoName] = append(latenciesByRepo[repoName], ReviewerLatencyData{
+				Number:        prNode.Number,
+				ReadyAt:       readyAt,
+				FirstReviewAt: firstReviewAt,
+			})
+		}
+		g.checkSearchCap(query, q.Search.IssueCount, false)
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
+		g.logger.Println("  Fetching next page of reviewed PRs for reviewer latencies...")
+	}
+	g.logger.Println("Completed fetching reviewer latency data.")
+	return latenciesByRepo, nil
+}
+
+// FetchAuthorWaits fetches when each pull request the user created got its first review from someone else.
+func (g *GitHubGateway) FetchAuthorWaits(ctx context.Context, org, user, dateRange string) (map[string][]AuthorWaitData, error) {
+	ctx = g.step(ctx, "author waits", "Fetching author wait data...")
+	query := CreatedPRsQuery(org, user, dateRange, g.labels)
+	variables := map[string]interface{}{
+		"query":  githubv4.String(query),
+		"first":  githubv4.Int(g.pullRequestPageSize()),
+		"cursor": (*githubv4.String)(nil),
+	}
+	waitsByRepo := make(map[string][]AuthorWaitData)
+	for {
+		var q authorWaitQuery
+		if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for author waits: %w", err)
+		}
+		for _, edge := range q.Search.Edges {
+			if edge.Node.Typename != "PullRequest" {
+				continue
+			}
+			prNode := edge.Node.PullRequest
+			var firstReviewAt time.Time
+			for _, review := range prNode.Reviews.Nodes {
+				if review.SubmittedAt == nil || (review.Author != nil && strings.EqualFold(review.Author.Login, user)) {
+					continue // A pending review, or the author replying to review comments.
+				}
+				if firstReviewAt.IsZero() || review.SubmittedAt.Before(firstReviewAt) {
+					firstReviewAt = review.SubmittedAt.Time
+				}
+			}
+			if firstReviewAt.IsZero() {
+				continue // Not reviewed yet, so there is no wait to measure.
+			}
+			repoName := prNode.Repository.NameWithOwner
+			waitsByRepo[repoName] = append(waitsByRepo[repoName], AuthorWaitData{
+				Number:        prNode.Number,
+				CreatedAt:     prNode.CreatedAt.Time,
+				FirstReviewAt: firstReviewAt,
+			})
+		}
+		g.checkSearchCap(query, q.Search.IssueCount, false)
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
+		g.logger.Println("  Fetching next page of created PRs for author waits...")
+	}
+	g.logger.Println("Completed fetching author wait data.")
+	return waitsByRepo, nil
+}
+
+// FetchPRSizes fetches the additions and deletions of every pull request the user created.
+func (g *GitHubGateway) FetchPRSizes(ctx context.Context, org, user, dateRange string) (map[string][]PRSize, error) {
+	ctx = g.step(ctx, "PR sizes", "Fetching PR size data...")
+	query := CreatedPRsQuery(org, user, dateRange, g.labels)
+	variables := map[string]interface{}{
+		"query":  githubv4.String(query),
+		"first":  githubv4.Int(g.countPageSize()),
+		"cursor": (*githubv4.String)(nil),
+	}
+	sizesByRepo := make(map[string][]PRSize)
+	for {
+		var q prSizeQuery
 		if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for PR sizes: %w", err)
+		}
+		for _, edge := range q.Search.Edges {
+			prNode := edge.Node.PullRequest
+			if edge.Node.Typename != "PullRequest" {
+				continue
+			}
+			repoName := prNode.Repository.NameWithOwner
+			sizesByRepo[repoName] = append(sizesByRepo[repoName], PRSize{
+				Number:    prNode.Number,
+				Additions: prNode.Additions,
+				Deletions: prNode.Deletions,
+			})
+		}
+		g.checkSearchCap(query, q.Search.IssueCount, false)
+		if !q.Search.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
+		g.logger.Println("  Fetching next page of PR sizes...")
+	}
+	g.logger.Println("Completed fetching PR size data.")
+	return sizesByRepo, nil
+}
+
+// fetchSearchCounts counts the pull requests or issues matching query per repository.
+func (g *GitHubGateway) fetchSearchCounts(ctx context.Context, query string) (map[string]int, error) {
+	return g.fetchSearchCountsWhere(ctx, query, nil)
+}
+
+// fetchSearchCountsWhere is fetchSearchCounts counting only the pull requests for which keep returns true.
+// A nil keep counts every match, and issues are always counted.
+func (g *GitHubGateway) fetchSearchCountsWhere(ctx context.Context, query string, keep func(searchIssuesPullRequest) bool) (map[string]int, error) {
+	variables := map[string]interface{}{
+		"query":  githubv4.String(query),
+		"first":  githubv4.Int(g.countPageSize()),
+		"cursor": (*githubv4.String)(nil),
+	}
+	counts := make(map[string]int)
+	for {
+		var q searchIssuesQuery
+		if err := g.queryAllowingPartial(ctx, &q, variables, func() bool { return len(q.Search.Edges) > 0 }); err != nil {
 			return nil, fmt.Errorf("failed to execute GraphQL query for counts: %w", err)
 		}
 		for _, edge := range q.Search.Edges {
-			if repoName := edge.Node.PullRequest.Repository.NameWithOwner; repoName != "" {
-				prCounts[repoName]++
+			repoName := edge.Node.PullRequest.Repository.NameWithOwner
+			if edge.Node.Typename == "Issue" {
+				repoName = edge.Node.Issue.Repository.NameWithOwner
+			} else if keep != nil && !keep(edge.Node.PullRequest) {
+				continue
+			}
+			if repoName != "" {
+				counts[repoName]++
 			}
 		}
+		g.checkSearchCap(query, q.Search.IssueCount, false)
 		if !q.Search.PageInfo.HasNextPage {
 			break
 		}
 		variables["cursor"] = githubv4.NewString(q.Search.PageInfo.EndCursor)
-		g.logger.Println("  Fetching next page of pull requests for counts...")
+		g.logger.Println("  Fetching next page of search results for counts...")
+	}
+	g.logger.Printf("Completed fetching counts for query: %s\n", query)
+	return counts, nil
+}
+
+// queryAllowingPartial runs a GraphQL query, tolerating the errors GitHub returns next to data,
+// e.g. for a repository the token can't see: they are logged and recorded as caveats, and the returned data is used.
+// hasData reports whether q was filled, since an error response without data leaves q at its zero value.
+func (g *GitHubGateway) queryAllowingPartial(ctx context.Context, q any, variables map[string]interface{}, hasData func() bool) error {
+	err := g.graphqlClient.Query(ctx, q, variables)
+	messages := graphQLErrorMessages(err)
+	if len(messages) == 0 || !hasData() {
+		return err
+	}
+	for _, message := range messages {
+		g.logger.Printf("  Ignoring GraphQL error in partial response: %s\n", message)
+		g.caveat("GraphQL returned partial results for %q: %s", variables["query"], message)
+	}
+	return nil
+}
+
+// graphQLErrorMessages returns the messages of the "errors" of a GraphQL response, or nil when err is
+// another error, e.g. of the transport. githubv4 doesn't export the type, so it is inspected by reflection.
+func graphQLErrorMessages(err error) []string {
+	v := reflect.ValueOf(err)
+	if !v.IsValid() || v.Kind() != reflect.Slice || v.Type().PkgPath() != "github.com/shurcooL/graphql" {
+		return nil
+	}
+	messages := make([]string, 0, v.Len())
+	for i := range v.Len() {
+		if message := v.Index(i).FieldByName("Message"); message.Kind() == reflect.String {
+			messages = append(messages, message.String())
+		}
+	}
+	return messages
+}
+
+// FetchRepoCommitTotals fetches the number of commits by any author for each repository.
+// Only the total count of the search result is read, so a single request per repository is enough.
+func (g *GitHubGateway) FetchRepoCommitTotals(ctx context.Context, repos []string, dateRange string) (map[string]int, error) {
+	ctx = g.step(ctx, "share totals", "Fetching repository commit totals for share calculation...")
+	opts := &github.SearchOptions{ListOptions: github.ListOptions{PerPage: 1}}
+	totals := make(map[string]int, len(repos))
+	for _, repo := range repos {
+		query := RepoCommitTotalsQuery(repo, dateRange)
+		result, _, err := g.restClient.Search.Commits(ctx, query, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to search commit totals for %s: %w", repo, err)
+		}
+		totals[repo] = result.GetTotal()
+	}
+	g.logger.Println("Completed fetching repository commit totals.")
+	return totals, nil
+}
+
+// FetchRepoPRTotals fetches the number of pull requests by any author for each repository,
+// limited to the WithLabels labels like the created PR search.
+func (g *GitHubGateway) FetchRepoPRTotals(ctx context.Context, repos []string, dateRange string, excludeDrafts bool) (map[string]int, error) {
+	ctx = g.step(ctx, "share totals", "Fetching repository pull request totals for share calculation...")
+	totals := make(map[string]int, len(repos))
+	for _, repo := range repos {
+		query := RepoPRTotalsQuery(repo, dateRange, g.labels, excludeDrafts)
+		var q searchIssueCountQuery
+		if err := g.graphqlClient.Query(ctx, &q, map[string]interface{}{"query": githubv4.String(query)}); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for pull request totals of %s: %w", repo, err)
+		}
+		totals[repo] = q.Search.IssueCount
+	}
+	g.logger.Println("Completed fetching repository pull request totals.")
+	return totals, nil
+}
+
+// CheckOrgAccess checks the authenticated user's membership in the organization.
+// A missing membership (404) or a token without permission to read it (403) is reported as limited access rather than an error.
+func (g *GitHubGateway) CheckOrgAccess(ctx context.Context, org string) (bool, error) {
+	ctx = g.step(ctx, "access checks", fmt.Sprintf("Checking access to organization %s...", org))
+	membership, resp, err := g.restClient.Organizations.GetOrgMembership(ctx, "", org)
+	if err != nil {
+		if resp != nil && (resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check membership of organization %s: %w", org, err)
 	}
-	g.logger.Printf("Completed fetching pull request counts for query: %s\n", query)
-	return prCounts, nil
+	return membership.GetState() == "active", nil
+}
+
+// OrgExists looks the organization up, treating a 404 as a missing organization.
+func (g *GitHubGateway) OrgExists(ctx context.Context, org string) (bool, error) {
+	ctx = g.step(ctx, "target checks", fmt.Sprintf("Checking that organization %s exists...", org))
+	_, resp, err := g.restClient.Organizations.Get(ctx, org)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up organization %s: %w", org, err)
+	}
+	return true, nil
+}
+
+// UserExists looks the user up, treating a 404 as a missing user.
+func (g *GitHubGateway) UserExists(ctx context.Context, user string) (bool, error) {
+	ctx = g.step(ctx, "target checks", fmt.Sprintf("Checking that user %s exists...", user))
+	_, resp, err := g.restClient.Users.Get(ctx, user)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to look up user %s: %w", user, err)
+	}
+	return true, nil
+}
+
+// FetchTeamMembers lists the members of a team, including the members of its child teams.
+func (g *GitHubGateway) FetchTeamMembers(ctx context.Context, org, teamSlug string) ([]string, error) {
+	ctx = g.step(ctx, "team members", fmt.Sprintf("Fetching members of team %s/%s...", org, teamSlug))
+	opts := &github.TeamListTeamMembersOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	var members []string
+	for {
+		users, resp, err := g.restClient.Teams.ListTeamMembersBySlug(ctx, org, teamSlug, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list members of team %s/%s: %w", org, teamSlug, err)
+		}
+		for _, user := range users {
+			members = append(members, user.GetLogin())
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+		g.logger.Println("  Fetching next page of team members...")
+	}
+	g.logger.Println("Completed fetching team members.")
+	return members, nil
+}
+
+// FetchRateLimit reads the rate limit status, which doesn't count against the limits itself.
+// Resources the server doesn't report, such as the search limit of some Enterprise Servers, are left out.
+func (g *GitHubGateway) FetchRateLimit(ctx context.Context) ([]RateLimit, error) {
+	limits, _, err := g.restClient.RateLimit.Get(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch the rate limit status: %w", err)
+	}
+	var result []RateLimit
+	for _, resource := range []struct {
+		name string
+		rate *github.Rate
+	}{
+		{"core", limits.Core},
+		{"search", limits.Search},
+		{"graphql", limits.GraphQL},
+	} {
+		if resource.rate == nil {
+			continue
+		}
+		result = append(result, RateLimit{
+			Resource:  resource.name,
+			Limit:     resource.rate.Limit,
+			Remaining: resource.rate.Remaining,
+			Reset:     resource.rate.Reset.Time,
+		})
+	}
+	return result, nil
+}
+
+// FetchRepoNodeIDs resolves the GraphQL node ID for each repository.
+func (g *GitHubGateway) FetchRepoNodeIDs(ctx context.Context, repos []string) (map[string]string, error) {
+	ctx = g.step(ctx, "node IDs", "Fetching repository node IDs...")
+	ids := make(map[string]string, len(repos))
+	for _, repo := range repos {
+		owner, name, ok := strings.Cut(repo, "/")
+		if !ok {
+			return nil, fmt.Errorf("invalid repository name %q, expected owner/name", repo)
+		}
+		var q repositoryIDQuery
+		variables := map[string]interface{}{"owner": githubv4.String(owner), "name": githubv4.String(name)}
+		if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for node ID of %s: %w", repo, err)
+		}
+		ids[repo] = fmt.Sprint(q.Repository.ID)
+	}
+	g.logger.Println("Completed fetching repository node IDs.")
+	return ids, nil
+}
+
+// FetchRepoLanguages resolves the primary language of the repositories with one repository search per batch,
+// instead of one request per repository like FetchRepoNodeIDs.
+func (g *GitHubGateway) FetchRepoLanguages(ctx context.Context, repos []string) (map[string]string, error) {
+	ctx = g.step(ctx, "languages", "Fetching repository languages...")
+	// Search returns the canonical owner/name, which may differ in case from the requested one.
+	requested := make(map[string]string, len(repos))
+	for _, repo := range repos {
+		requested[strings.ToLower(repo)] = repo
+	}
+	languages := make(map[string]string, len(repos))
+	for start := 0; start < len(repos); start += repoLanguagesBatchSize {
+		batch := repos[start:min(start+repoLanguagesBatchSize, len(repos))]
+		var q repoLanguagesQuery
+		variables := map[string]interface{}{
+			"query": githubv4.String(RepoLanguagesQuery(batch)),
+			"first": githubv4.Int(len(batch)),
+		}
+		if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+			return nil, fmt.Errorf("failed to execute GraphQL query for repository languages: %w", err)
+		}
+		for _, node := range q.Search.Nodes {
+			repo, ok := requested[strings.ToLower(node.Repository.NameWithOwner)]
+			if ok && node.Repository.PrimaryLanguage != nil {
+				languages[repo] = node.Repository.PrimaryLanguage.Name
+			}
+		}
+	}
+	g.logger.Println("Completed fetching repository languages.")
+	return languages, nil
 }
 
 // FetchPRLeadTimes fetches PR creation and last review timestamps.
 func (g *GitHubGateway) FetchPRLeadTimes(ctx context.Context, org, user, dateRange string) (map[string][]PRLeadTimeData, error) {
-	g.logger.Println("[4/4] Fetching PR lead time data...")
+	ctx = g.step(ctx, "lead times", "[4/4] Fetching PR lead time data...")
 	// We are looking for PRs authored by the user that are now merged or closed.
-	query := fmt.Sprintf("org:%s author:%s is:pr is:closed%s", org, user, dateRange)
+	query := PRLeadTimesQuery(org, user, dateRange, g.labels)
 
+	states := g.reviewStates
+	if states == nil {
+		states = DefaultReviewStates
+	}
+	stateValues := make([]githubv4.PullRequestReviewState, len(states))
+	for i, state := range states {
+		stateValues[i] = githubv4.PullRequestReviewState(state)
+	}
 	variables := map[string]interface{}{
 		"query":  githubv4.String(query),
+		"first":  githubv4.Int(g.pullRequestPageSize()),
 		"cursor": (*githubv4.String)(nil),
+		"states": stateValues,
 	}
 
 	leadTimesByRepo := make(map[string][]PRLeadTimeData)
 
 	for {
 		var q prLeadTimeQuery
-		if err := g.graphqlClient.Query(ctx, &q, variables); err != nil {
+		if err := g.queryAllowingPartial(ctx, &q, variables, func() bool { return len(q.Search.Edges) > 0 }); err != nil {
 			return nil, fmt.Errorf("failed to execute GraphQL query for lead times: %w", err)
 		}
 
 		for _, edge := range q.Search.Edges {
 			prNode := edge.Node.PullRequest
-			if edge.Node.Typename != "PullRequest" || len(prNode.Reviews.Nodes) == 0 {
-				continue // Skip if not a PR or has no reviews.
+			if edge.Node.Typename != "PullRequest" {
+				continue
 			}
 
-			// Find the latest review timestamp.
-			lastReviewedAt := prNode.Reviews.Nodes[0].SubmittedAt.Time
-			for _, review := range prNode.Reviews.Nodes[1:] {
+			// Find the earliest and latest review timestamps, skipping reviews that were never submitted.
+			var firstReviewedAt, lastReviewedAt time.Time
+			for _, review := range prNode.Reviews.Nodes {
+				if review.SubmittedAt == nil {
+					continue
+				}
+				if firstReviewedAt.IsZero() || review.SubmittedAt.Before(firstReviewedAt) {
+					firstReviewedAt = review.SubmittedAt.Time
+				}
 				if review.SubmittedAt.After(lastReviewedAt) {
 					lastReviewedAt = review.SubmittedAt.Time
 				}
 			}
+			if lastReviewedAt.IsZero() && prNode.MergedAt == nil {
+				continue // Neither a submitted review nor a merge to measure.
+			}
 
 			data := PRLeadTimeData{
-				CreatedAt:      prNode.CreatedAt.Time,
-				LastReviewedAt: lastReviewedAt,
+				Number:          prNode.Number,
+				CreatedAt:       prNode.CreatedAt.Time,
+				FirstReviewedAt: firstReviewedAt,
+				LastReviewedAt:  lastReviewedAt,
+				Additions:       prNode.Additions,
+				Deletions:       prNode.Deletions,
+			}
+			if prNode.MergedAt != nil {
+				data.MergedAt = prNode.MergedAt.Time
+			}
+			if reopens := prNode.TimelineItems.Nodes; len(reopens) > 0 {
+				data.LastReopenedAt = reopens[len(reopens)-1].ReopenedEvent.CreatedAt.Time
 			}
 
 			repoName := prNode.Repository.NameWithOwner
 			leadTimesByRepo[repoName] = append(leadTimesByRepo[repoName], data)
 		}
 
+		g.checkSearchCap(query, q.Search.IssueCount, false)
 		if !q.Search.PageInfo.HasNextPage {
 			break
 		}
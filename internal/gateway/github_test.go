@@ -2,13 +2,18 @@ package gateway
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v62/github"
 	"github.com/shurcooL/githubv4"
@@ -84,6 +89,59 @@ func TestGitHubGateway_FetchCommits(t *testing.T) {
 	}
 }
 
+func TestGitHubGateway_FetchCommits_Dedupe(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 3, "items": [
+			{"sha": "abc123", "repository": {"full_name": "org/repo-a"}},
+			{"sha": "abc123", "repository": {"full_name": "org/repo-a"}},
+			{"sha": "abc123", "repository": {"full_name": "fork/repo-a"}}
+		]}`)
+	}
+	testCases := []struct {
+		name        string
+		opts        []Option
+		expectedMap map[string]int
+	}{
+		{name: "without dedupe", expectedMap: map[string]int{"org/repo-a": 2, "fork/repo-a": 1}},
+		// The same SHA still counts once in every repository it appears in.
+		{name: "with dedupe", opts: []Option{WithCommitDedupe()}, expectedMap: map[string]int{"org/repo-a": 1, "fork/repo-a": 1}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+			defer server.Close()
+			for _, opt := range tc.opts {
+				opt(gateway)
+			}
+
+			counts, err := gateway.FetchCommits(context.Background(), "org", "any-user", "")
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMap, counts)
+		})
+	}
+}
+
+func TestGitHubGateway_FetchCommitTimes(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "org:org author:any-user author-date:2025-01-01..2025-01-31", r.URL.Query().Get("q"))
+		fmt.Fprint(w, `{"total_count": 3, "items": [
+			{"sha": "a1", "repository": {"full_name": "org/repo-a"}, "commit": {"author": {"date": "2025-01-06T09:30:00Z"}}},
+			{"sha": "a2", "repository": {"full_name": "org/repo-a"}, "commit": {"author": {"date": "2025-01-07T18:00:00+09:00"}}},
+			{"sha": "b1", "repository": {"full_name": "org/repo-b"}, "commit": {"author": {"date": "2025-01-08T12:00:00Z"}}}
+		]}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	times, err := gateway.FetchCommitTimes(context.Background(), "org", "any-user", " author-date:2025-01-01..2025-01-31")
+	require.NoError(t, err)
+	require.Len(t, times["org/repo-a"], 2)
+	assert.True(t, times["org/repo-a"][0].Equal(time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC)))
+	assert.True(t, times["org/repo-a"][1].Equal(time.Date(2025, 1, 7, 9, 0, 0, 0, time.UTC)))
+	require.Len(t, times["org/repo-b"], 1)
+	assert.True(t, times["org/repo-b"][0].Equal(time.Date(2025, 1, 8, 12, 0, 0, 0, time.UTC)))
+}
+
 // TestGitHubGateway_GraphQLFetches consolidates the GraphQL tests into a single table-driven test.
 func TestGitHubGateway_GraphQLFetches(t *testing.T) {
 	testCases := []struct {
@@ -116,6 +174,16 @@ func TestGitHubGateway_GraphQLFetches(t *testing.T) {
 			expectedMap:   map[string]int{"org/repo-reviewed": 1},
 			expectError:   false,
 		},
+		{
+			name: "FetchCommentedPRs - happy path",
+			methodToTest: func(gateway *GitHubGateway) (map[string]int, error) {
+				return gateway.FetchCommentedPRs(context.Background(), "any-org", "any-user", "")
+			},
+			queryContains: "commenter:any-user",
+			responseBody:  `{"data":{"search":{"edges":[{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-commented"}}}]}}}`,
+			expectedMap:   map[string]int{"org/repo-commented": 1},
+			expectError:   false,
+		},
 		{
 			name: "FetchCreatedPRs - error case",
 			methodToTest: func(gateway *GitHubGateway) (map[string]int, error) {
@@ -158,3 +226,1166 @@ func TestGitHubGateway_GraphQLFetches(t *testing.T) {
 		})
 	}
 }
+
+func TestGitHubGateway_FetchRepoTotals(t *testing.T) {
+	t.Run("FetchRepoCommitTotals reads the total count per repository", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Contains(t, r.URL.Path, "/search/commits")
+			q := r.URL.Query().Get("q")
+			assert.NotContains(t, q, "author:")
+			switch q {
+			case "repo:org/repo-a author-date:2025-01-01..*":
+				fmt.Fprint(w, `{"total_count": 42, "items": []}`)
+			case "repo:org/repo-b author-date:2025-01-01..*":
+				fmt.Fprint(w, `{"total_count": 7, "items": []}`)
+			default:
+				t.Errorf("unexpected query: %s", q)
+			}
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+
+		totals, err := gateway.FetchRepoCommitTotals(context.Background(), []string{"org/repo-a", "org/repo-b"}, " author-date:2025-01-01..*")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"org/repo-a": 42, "org/repo-b": 7}, totals)
+	})
+
+	t.Run("FetchRepoPRTotals reads the issue count per repository", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "repo:org/repo-a is:pr")
+			assert.NotContains(t, string(body), "author:")
+			fmt.Fprint(w, `{"data":{"search":{"issueCount":12}}}`)
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+
+		totals, err := gateway.FetchRepoPRTotals(context.Background(), []string{"org/repo-a"}, "", false)
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"org/repo-a": 12}, totals)
+	})
+}
+
+func TestGitHubGateway_FetchMergedPRCommits(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "is:merged")
+		// The same commit appears in two PRs, one commit belongs to another author, and one has no linked user.
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"commits":{"nodes":[
+				{"commit":{"oid":"sha-1","author":{"user":{"login":"Any-User"}}}},
+				{"commit":{"oid":"sha-2","author":{"user":{"login":"someone-else"}}}},
+				{"commit":{"oid":"sha-3","author":{"user":null}}}
+			]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"commits":{"nodes":[
+				{"commit":{"oid":"sha-1","author":{"user":{"login":"any-user"}}}},
+				{"commit":{"oid":"sha-4","author":{"user":{"login":"any-user"}}}}
+			]}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	counts, err := gateway.FetchMergedPRCommits(context.Background(), "any-org", "any-user", "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo-a": 2}, counts)
+}
+
+func TestGitHubGateway_FetchRepoNodeIDs(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), `"owner":"org"`)
+		assert.Contains(t, string(body), `"name":"repo-a"`)
+		fmt.Fprint(w, `{"data":{"repository":{"id":"R_kgDOabc"}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	ids, err := gateway.FetchRepoNodeIDs(context.Background(), []string{"org/repo-a"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"org/repo-a": "R_kgDOabc"}, ids)
+
+	_, err = gateway.FetchRepoNodeIDs(context.Background(), []string{"not-a-repo"})
+	assert.ErrorContains(t, err, "expected owner/name")
+}
+
+func TestGitHubGateway_CheckOrgAccess(t *testing.T) {
+	testCases := []struct {
+		name           string
+		status         int
+		responseBody   string
+		expectedAccess bool
+		expectError    bool
+	}{
+		{name: "active member", status: http.StatusOK, responseBody: `{"state":"active","role":"member"}`, expectedAccess: true},
+		{name: "pending invitation", status: http.StatusOK, responseBody: `{"state":"pending","role":"member"}`, expectedAccess: false},
+		{name: "not a member", status: http.StatusNotFound, responseBody: `{"message":"Not Found"}`, expectedAccess: false},
+		{name: "token cannot read membership", status: http.StatusForbidden, responseBody: `{"message":"Resource not accessible"}`, expectedAccess: false},
+		{name: "server error", status: http.StatusInternalServerError, responseBody: `{"message":"boom"}`, expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/user/memberships/orgs/any-org", r.URL.Path)
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.responseBody)
+			}
+			gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+			defer server.Close()
+
+			hasAccess, err := gateway.CheckOrgAccess(context.Background(), "any-org")
+			if tc.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedAccess, hasAccess)
+			}
+		})
+	}
+}
+
+func TestGitHubGateway_OrgAndUserExists(t *testing.T) {
+	testCases := []struct {
+		name         string
+		status       int
+		responseBody string
+		expected     bool
+		expectError  bool
+	}{
+		{name: "found", status: http.StatusOK, responseBody: `{"login":"acme"}`, expected: true},
+		{name: "not found", status: http.StatusNotFound, responseBody: `{"message":"Not Found"}`, expected: false},
+		{name: "server error", status: http.StatusInternalServerError, responseBody: `{"message":"boom"}`, expectError: true},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var paths []string
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.responseBody)
+			}
+			gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+			defer server.Close()
+
+			orgExists, orgErr := gateway.OrgExists(context.Background(), "acme")
+			userExists, userErr := gateway.UserExists(context.Background(), "alice")
+			if tc.expectError {
+				assert.ErrorContains(t, orgErr, "organization acme")
+				assert.ErrorContains(t, userErr, "user alice")
+			} else {
+				assert.NoError(t, orgErr)
+				assert.NoError(t, userErr)
+				assert.Equal(t, tc.expected, orgExists)
+				assert.Equal(t, tc.expected, userExists)
+			}
+			assert.Equal(t, []string{"/orgs/acme", "/users/alice"}, paths)
+		})
+	}
+}
+
+func TestGitHubGateway_FetchMergedPRs(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Query string `json:"query"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "org:any-org author:any-user is:pr is:merged created:2025-01-01..2025-01-31", req.Variables.Query)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	counts, err := gateway.FetchMergedPRs(context.Background(), "any-org", "any-user", " created:2025-01-01..2025-01-31")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo-a": 2, "org/repo-b": 1}, counts)
+}
+
+func TestGitHubGateway_FetchDraftPRs(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Query string `json:"query"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Query, "isDraft")
+		assert.Equal(t, "org:any-org author:any-user is:pr", req.Variables.Query)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"isDraft":true}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"isDraft":false}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"isDraft":true}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"},"isDraft":false}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	drafts, err := gateway.FetchDraftPRs(context.Background(), "any-org", "any-user", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo-a": 2}, drafts)
+
+	// The same response still counts every PR as created.
+	created, err := gateway.FetchCreatedPRs(context.Background(), "any-org", "any-user", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo-a": 3, "org/repo-b": 1}, created)
+}
+
+func TestGitHubGateway_FetchReviewedPRs_ExcludeBots(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Query, "author{__typename,login}")
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"author":{"__typename":"User","login":"alice"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"author":{"__typename":"Bot","login":"dependabot"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"author":{"__typename":"User","login":"renovate[bot]"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"},"author":{"__typename":"User","login":"Release-Robot"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"},"author":null}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	counts, err := gateway.FetchReviewedPRs(context.Background(), "any-org", "any-user", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo-a": 3, "org/repo-b": 2}, counts)
+
+	WithBotExclusion([]string{"release-robot"})(gateway)
+	counts, err = gateway.FetchReviewedPRs(context.Background(), "any-org", "any-user", "")
+	require.NoError(t, err)
+	// GitHub Apps, "[bot]" logins and the configured logins are left out; a deleted author is kept.
+	assert.Equal(t, map[string]int{"org/repo-a": 1, "org/repo-b": 1}, counts)
+}
+
+func TestGitHubGateway_FetchCreatedPRs_PartialErrors(t *testing.T) {
+	testCases := []struct {
+		name            string
+		response        string
+		expectedMap     map[string]int
+		expectedCaveats []string
+		expectError     bool
+	}{
+		{
+			name: "data with errors keeps the returned edges",
+			response: `{"data":{"search":{"edges":[
+				{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}},
+				{"node":null},
+				{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}}
+			]}},"errors":[{"type":"FORBIDDEN","path":["search","edges",1,"node"],"message":"Resource not accessible by integration"}]}`,
+			expectedMap:     map[string]int{"org/repo-a": 2},
+			expectedCaveats: []string{`GraphQL returned partial results for "org:any-org author:any-user is:pr": Resource not accessible by integration`},
+		},
+		{
+			name:        "errors without data fail",
+			response:    `{"data":null,"errors":[{"type":"RATE_LIMITED","message":"API rate limit exceeded"}]}`,
+			expectError: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway, server := setupTestGateway(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.response)
+			}))
+			defer server.Close()
+			var caveats Caveats
+			WithCaveats(&caveats)(gateway)
+
+			counts, err := gateway.FetchCreatedPRs(context.Background(), "any-org", "any-user", "")
+			if tc.expectError {
+				assert.ErrorContains(t, err, "API rate limit exceeded")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedMap, counts)
+			assert.Equal(t, tc.expectedCaveats, caveats.Drain())
+		})
+	}
+}
+
+func TestGitHubGateway_PartialErrors(t *testing.T) {
+	// partial answers with node and a node the token can't see.
+	partial := func(node string) string {
+		return `{"data":{"search":{"edges":[{"node":` + node + `},{"node":null}]}},` +
+			`"errors":[{"type":"FORBIDDEN","path":["search","edges",1,"node"],"message":"Resource not accessible by integration"}]}`
+	}
+	testCases := []struct {
+		name     string
+		response string
+		fetch    func(g *GitHubGateway) (int, error)
+	}{
+		{
+			name: "lead times",
+			response: partial(`{"__typename":"PullRequest","number":1,"createdAt":"2025-01-01T00:00:00Z","mergedAt":"2025-01-01T02:00:00Z",
+				"repository":{"nameWithOwner":"org/repo-a"},"reviews":{"nodes":[{"submittedAt":"2025-01-01T01:00:00Z"}]}}`),
+			fetch: func(g *GitHubGateway) (int, error) {
+				leadTimes, err := g.FetchPRLeadTimes(context.Background(), "any-org", "any-user", "")
+				return len(leadTimes["org/repo-a"]), err
+			},
+		},
+		{
+			name:     "approvals",
+			response: partial(`{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"reviews":{"nodes":[{"state":"APPROVED"}]}}`),
+			fetch: func(g *GitHubGateway) (int, error) {
+				counts, err := g.FetchApprovals(context.Background(), "any-org", "any-user", "")
+				return counts["org/repo-a"], err
+			},
+		},
+		{
+			name:     "review comments",
+			response: partial(`{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"reviews":{"nodes":[{"comments":{"totalCount":2}}]}}`),
+			fetch: func(g *GitHubGateway) (int, error) {
+				counts, err := g.FetchReviewComments(context.Background(), "any-org", "any-user", "")
+				return counts["org/repo-a"], err
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gateway, server := setupTestGateway(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tc.response)
+			}))
+			defer server.Close()
+			var caveats Caveats
+			WithCaveats(&caveats)(gateway)
+
+			found, err := tc.fetch(gateway)
+			require.NoError(t, err)
+			assert.Positive(t, found)
+			assert.Len(t, caveats.Drain(), 1)
+		})
+	}
+}
+
+func TestGraphQLErrorMessages(t *testing.T) {
+	assert.Nil(t, graphQLErrorMessages(nil))
+	assert.Nil(t, graphQLErrorMessages(errors.New("non-200 OK status code")))
+}
+
+func TestGitHubGateway_FetchApprovals(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Query string `json:"query"`
+				User  string `json:"user"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Query, "reviews(first: 100, author: $user)")
+		assert.Equal(t, "org:any-org reviewed-by:any-user is:pr", req.Variables.Query)
+		assert.Equal(t, "any-user", req.Variables.User)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"reviews":{"nodes":[
+				{"state":"CHANGES_REQUESTED"},
+				{"state":"APPROVED"},
+				{"state":"APPROVED"}
+			]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"reviews":{"nodes":[{"state":"COMMENTED"}]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"},"reviews":{"nodes":[{"state":"DISMISSED"}]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-c"},"reviews":{"nodes":[{"state":"COMMENTED"},{"state":"APPROVED"}]}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	counts, err := gateway.FetchApprovals(context.Background(), "any-org", "any-user", "")
+	assert.NoError(t, err)
+	// A PR approved twice counts once; comments, change requests and dismissed approvals don't count.
+	assert.Equal(t, map[string]int{"org/repo-a": 1, "org/repo-c": 1}, counts)
+}
+
+func TestGitHubGateway_FetchReviewerLatencies(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Query string `json:"query"`
+				User  string `json:"user"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Query, "timelineItems(last: 1, itemTypes: [READY_FOR_REVIEW_EVENT])")
+		assert.Equal(t, "org:any-org reviewed-by:any-user is:pr", req.Variables.Query)
+		assert.Equal(t, "any-user", req.Variables.User)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":1,"createdAt":"2024-05-01T10:00:00Z",
+				"reviews":{"nodes":[{"submittedAt":"2024-05-01T15:00:00Z"},{"submittedAt":"2024-05-01T12:00:00Z"}]},
+				"timelineItems":{"nodes":[]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":2,"createdAt":"2024-05-01T10:00:00Z",
+				"reviews":{"nodes":[{"submittedAt":"2024-05-03T09:00:00Z"}]},
+				"timelineItems":{"nodes":[{"createdAt":"2024-05-03T08:00:00Z"}]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"},"number":3,"createdAt":"2024-05-01T10:00:00Z",
+				"reviews":{"nodes":[{"submittedAt":null}]},
+				"timelineItems":{"nodes":[]}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	latencies, err := gateway.FetchReviewerLatencies(context.Background(), "any-org", "any-user", "")
+	assert.NoError(t, err)
+	// The earliest review counts, a draft starts at its ready-for-review event, and a PR with only a pending review is skipped.
+	assert.Equal(t, map[string][]ReviewerLatencyData{
+		"org/repo-a": {
+			{Number: 1, ReadyAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), FirstReviewAt: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)},
+			{Number: 2, ReadyAt: time.Date(2024, 5, 3, 8, 0, 0, 0, time.UTC), FirstReviewAt: time.Date(2024, 5, 3, 9, 0, 0, 0, time.UTC)},
+		},
+	}, latencies)
+}
+
+func TestGitHubGateway_FetchAuthorWaits(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Query string `json:"query"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Query, "reviews(first: 100)")
+		assert.Equal(t, "org:any-org author:any-user is:pr", req.Variables.Query)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":1,"createdAt":"2024-05-01T10:00:00Z",
+				"reviews":{"nodes":[
+					{"author":{"login":"Any-User"},"submittedAt":"2024-05-01T11:00:00Z"},
+					{"author":{"login":"reviewer"},"submittedAt":"2024-05-01T15:00:00Z"},
+					{"author":null,"submittedAt":"2024-05-01T13:00:00Z"}
+				]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":2,"createdAt":"2024-05-01T10:00:00Z",
+				"reviews":{"nodes":[]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"},"number":3,"createdAt":"2024-05-01T10:00:00Z",
+				"reviews":{"nodes":[{"author":{"login":"reviewer"},"submittedAt":null},{"author":{"login":"any-user"},"submittedAt":"2024-05-02T10:00:00Z"}]}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	waits, err := gateway.FetchAuthorWaits(context.Background(), "any-org", "any-user", "")
+	assert.NoError(t, err)
+	// The author's own reviews and pending reviews don't count, and PRs without another review are skipped.
+	assert.Equal(t, map[string][]AuthorWaitData{
+		"org/repo-a": {
+			{Number: 1, CreatedAt: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), FirstReviewAt: time.Date(2024, 5, 1, 13, 0, 0, 0, time.UTC)},
+		},
+	}, waits)
+}
+
+func TestGitHubGateway_FetchRepoLanguages(t *testing.T) {
+	repos := make([]string, 25)
+	for i := range repos {
+		repos[i] = fmt.Sprintf("org/repo-%02d", i)
+	}
+	var queries []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables struct {
+				Query string `json:"query"`
+				First int    `json:"first"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		queries = append(queries, body.Variables.Query)
+		if len(queries) == 1 {
+			assert.Equal(t, 20, body.Variables.First)
+			// The canonical name differs in case; repo-01 has no detected language.
+			fmt.Fprint(w, `{"data":{"search":{"nodes":[
+				{"nameWithOwner":"Org/Repo-00","primaryLanguage":{"name":"Go"}},
+				{"nameWithOwner":"org/repo-01","primaryLanguage":null}
+			]}}}`)
+			return
+		}
+		assert.Equal(t, 5, body.Variables.First)
+		fmt.Fprint(w, `{"data":{"search":{"nodes":[{"nameWithOwner":"org/repo-24","primaryLanguage":{"name":"Rust"}}]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	languages, err := gateway.FetchRepoLanguages(context.Background(), repos)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"org/repo-00": "Go", "org/repo-24": "Rust"}, languages)
+	require.Len(t, queries, 2)
+	assert.True(t, strings.HasPrefix(queries[0], "fork:true repo:org/repo-00 repo:org/repo-01 "))
+	assert.Equal(t, "fork:true repo:org/repo-20 repo:org/repo-21 repo:org/repo-22 repo:org/repo-23 repo:org/repo-24", queries[1])
+}
+
+func TestGitHubGateway_FetchRateLimit(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rate_limit", r.URL.Path)
+		fmt.Fprint(w, `{"resources": {
+			"core": {"limit": 5000, "remaining": 4990, "reset": 1760529600},
+			"search": {"limit": 30, "remaining": 12, "reset": 1760526060},
+			"graphql": {"limit": 5000, "remaining": 4321, "reset": 1760529600}
+		}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	limits, err := gateway.FetchRateLimit(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []RateLimit{
+		{Resource: "core", Limit: 5000, Remaining: 4990, Reset: time.Unix(1760529600, 0)},
+		{Resource: "search", Limit: 30, Remaining: 12, Reset: time.Unix(1760526060, 0)},
+		{Resource: "graphql", Limit: 5000, Remaining: 4321, Reset: time.Unix(1760529600, 0)},
+	}, limits)
+}
+
+func TestGitHubGateway_FetchPRLeadTimes(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "is:closed")
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":5,
+				"createdAt":"2025-01-01T00:00:00Z","additions":10,"deletions":2,
+				"reviews":{"nodes":[{"submittedAt":"2025-01-01T03:00:00Z"},{"submittedAt":null},{"submittedAt":"2025-01-01T01:00:00Z"}]},
+				"timelineItems":{"nodes":[{"createdAt":"2025-01-01T02:00:00Z"}]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":6,
+				"createdAt":"2025-01-02T00:00:00Z","reviews":{"nodes":[]},"timelineItems":{"nodes":[]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":7,
+				"createdAt":"2025-01-03T00:00:00Z","reviews":{"nodes":[{"submittedAt":null}]},"timelineItems":{"nodes":[]}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	result, err := gateway.FetchPRLeadTimes(context.Background(), "any-org", "any-user", "")
+	assert.NoError(t, err)
+	// The unreviewed PRs are skipped, a review without submission time (pending) doesn't count as the first,
+	// and the latest review wins.
+	assert.Equal(t, map[string][]PRLeadTimeData{
+		"org/repo-a": {{
+			Number:          5,
+			CreatedAt:       time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+			FirstReviewedAt: time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC),
+			LastReviewedAt:  time.Date(2025, 1, 1, 3, 0, 0, 0, time.UTC),
+			LastReopenedAt:  time.Date(2025, 1, 1, 2, 0, 0, 0, time.UTC),
+			Additions:       10,
+			Deletions:       2,
+		}},
+	}, result)
+}
+
+func TestGitHubGateway_FetchPRLeadTimes_ReviewStates(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     []Option
+		expected []string
+	}{
+		{name: "default", expected: []string{"APPROVED", "CHANGES_REQUESTED", "COMMENTED"}},
+		{name: "approvals only", opts: []Option{WithReviewStates([]string{"APPROVED"})}, expected: []string{"APPROVED"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Query     string `json:"query"`
+					Variables struct {
+						States []string `json:"states"`
+					} `json:"variables"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Contains(t, req.Query, "$states:[PullRequestReviewState!]!")
+				assert.Contains(t, req.Query, "reviews(first: 100, states: $states)")
+				assert.Equal(t, tc.expected, req.Variables.States)
+				fmt.Fprint(w, `{"data":{"search":{"edges":[]}}}`)
+			}
+			gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+			defer server.Close()
+			for _, opt := range tc.opts {
+				opt(gateway)
+			}
+
+			_, err := gateway.FetchPRLeadTimes(context.Background(), "any-org", "any-user", "")
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestParseReviewStates(t *testing.T) {
+	states, err := ParseReviewStates([]string{"commented", " APPROVED", "approved"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"APPROVED", "COMMENTED"}, states)
+
+	// A pending review has no submission time to measure to.
+	for _, invalid := range [][]string{{"approve"}, {"APPROVED", "MERGED"}, {"PENDING"}, {}} {
+		_, err := ParseReviewStates(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestGitHubGateway_FetchPRLeadTimes_MergedAt(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":1,
+				"createdAt":"2025-01-01T00:00:00Z","mergedAt":"2025-01-02T00:00:00Z",
+				"reviews":{"nodes":[{"submittedAt":"2025-01-01T05:00:00Z"}]},"timelineItems":{"nodes":[]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":2,
+				"createdAt":"2025-01-01T00:00:00Z","mergedAt":null,
+				"reviews":{"nodes":[{"submittedAt":"2025-01-01T02:00:00Z"}]},"timelineItems":{"nodes":[]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":3,
+				"createdAt":"2025-01-01T00:00:00Z","mergedAt":"2025-01-01T01:00:00Z",
+				"reviews":{"nodes":[]},"timelineItems":{"nodes":[]}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	result, err := gateway.FetchPRLeadTimes(context.Background(), "any-org", "any-user", "")
+	require.NoError(t, err)
+	require.Len(t, result["org/repo-a"], 3)
+
+	merged, closedUnmerged, mergedWithoutReview := result["org/repo-a"][0], result["org/repo-a"][1], result["org/repo-a"][2]
+	assert.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), merged.MergedAt)
+	assert.True(t, closedUnmerged.MergedAt.IsZero())
+	assert.Equal(t, time.Date(2025, 1, 1, 1, 0, 0, 0, time.UTC), mergedWithoutReview.MergedAt)
+	assert.True(t, mergedWithoutReview.LastReviewedAt.IsZero())
+}
+
+func TestGitHubGateway_MultipleOrgs(t *testing.T) {
+	t.Run("GraphQL queries combine the org qualifiers", func(t *testing.T) {
+		requests := 0
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "org:org-a org:org-b author:any-user is:pr")
+			fmt.Fprint(w, `{"data":{"search":{"edges":[
+				{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org-a/repo"}}},
+				{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org-b/repo"}}}
+			]}}}`)
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+
+		counts, err := gateway.FetchCreatedPRs(context.Background(), "org-a, org-b", "any-user", "")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"org-a/repo": 1, "org-b/repo": 1}, counts)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("commit search combines the org qualifiers", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "org:org-a org:org-b author:any-user", r.URL.Query().Get("q"))
+			fmt.Fprint(w, `{"total_count": 1, "items": [{"repository": {"full_name": "org-b/repo"}}]}`)
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+
+		counts, err := gateway.FetchCommits(context.Background(), "org-a,org-b", "any-user", "")
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]int{"org-b/repo": 1}, counts)
+	})
+}
+
+func TestGitHubGateway_RepoScope(t *testing.T) {
+	t.Run("GraphQL queries use a repo qualifier", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "repo:acme/api reviewed-by:any-user is:pr")
+			assert.NotContains(t, string(body), "org:")
+			fmt.Fprint(w, `{"data":{"search":{"edges":[
+				{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"acme/api"}}}
+			]}}}`)
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+
+		counts, err := gateway.FetchReviewedPRs(context.Background(), "acme/api", "any-user", "")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"acme/api": 1}, counts)
+	})
+
+	t.Run("commit search uses a repo qualifier", func(t *testing.T) {
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "repo:acme/api author:any-user", r.URL.Query().Get("q"))
+			fmt.Fprint(w, `{"total_count": 1, "items": [{"repository": {"full_name": "acme/api"}}]}`)
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+
+		counts, err := gateway.FetchCommits(context.Background(), "acme/api", "any-user", "")
+		require.NoError(t, err)
+		assert.Equal(t, map[string]int{"acme/api": 1}, counts)
+	})
+}
+
+func TestGitHubGateway_PageSizes(t *testing.T) {
+	// decodeFirst returns the "first" variable of a GraphQL request.
+	decodeFirst := func(t *testing.T, r *http.Request) int {
+		var req struct {
+			Variables struct {
+				First int `json:"first"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		return req.Variables.First
+	}
+	emptySearch := `{"data":{"search":{"edges":[]}}}`
+
+	t.Run("defaults", func(t *testing.T) {
+		var firsts []int
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			firsts = append(firsts, decodeFirst(t, r))
+			fmt.Fprint(w, emptySearch)
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+
+		_, err := gateway.FetchCreatedPRs(context.Background(), "org", "user", "")
+		require.NoError(t, err)
+		_, err = gateway.FetchPRLeadTimes(context.Background(), "org", "user", "")
+		require.NoError(t, err)
+		assert.Equal(t, []int{100, 20}, firsts)
+	})
+
+	t.Run("configured", func(t *testing.T) {
+		var firsts []int
+		var perPage string
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/search/commits" {
+				perPage = r.URL.Query().Get("per_page")
+				fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+				return
+			}
+			firsts = append(firsts, decodeFirst(t, r))
+			fmt.Fprint(w, emptySearch)
+		}
+		gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+		defer server.Close()
+		WithPageSizes(PageSizes{Counts: 50, PullRequests: 5})(gateway)
+
+		_, err := gateway.FetchCommits(context.Background(), "org", "user", "")
+		require.NoError(t, err)
+		_, err = gateway.FetchReviewedPRs(context.Background(), "org", "user", "")
+		require.NoError(t, err)
+		_, err = gateway.FetchPRLeadTimes(context.Background(), "org", "user", "")
+		require.NoError(t, err)
+		_, err = gateway.FetchMergedPRCommits(context.Background(), "org", "user", "")
+		require.NoError(t, err)
+		assert.Equal(t, "50", perPage)
+		assert.Equal(t, []int{50, 5, 5}, firsts)
+	})
+}
+
+func TestNewGitHubGateway_InvalidPageSize(t *testing.T) {
+	_, err := NewGitHubGateway("token", log.New(io.Discard, "", 0), WithPageSizes(PageSizes{Counts: 101}))
+	assert.ErrorContains(t, err, "page size must be between 1 and 100, got 101")
+	assert.NoError(t, ValidatePageSize(1))
+	assert.Error(t, ValidatePageSize(-1))
+}
+
+func TestGitHubGateway_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+	defer close(release)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	_, err := gateway.FetchCreatedPRs(ctx, "org", "user", "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	_, err = gateway.FetchCommits(ctx, "org", "user", "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNewGitHubGateway_BaseURL(t *testing.T) {
+	var paths []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/graphql") {
+			fmt.Fprint(w, `{"data":{"search":{"edges":[]}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	gateway, err := NewGitHubGateway("token", log.New(io.Discard, "", 0), WithBaseURL(server.URL))
+	require.NoError(t, err)
+
+	_, err = gateway.FetchCommits(context.Background(), "org", "user", "")
+	require.NoError(t, err)
+	_, err = gateway.FetchCreatedPRs(context.Background(), "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/v3/search/commits", "/api/graphql"}, paths)
+}
+
+func TestNewGitHubGateway_APIURLs(t *testing.T) {
+	var paths []string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if strings.HasSuffix(r.URL.Path, "/graphql") {
+			fmt.Fprint(w, `{"data":{"search":{"edges":[]}}}`)
+			return
+		}
+		fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	getenv := func(key string) string {
+		return map[string]string{
+			"GITHUB_API_URL":     server.URL + "/api/v3",
+			"GITHUB_GRAPHQL_URL": server.URL + "/custom/graphql",
+		}[key]
+	}
+	gateway, err := NewGitHubGateway("token", log.New(io.Discard, "", 0), WithAPIURLs(ActionsAPIURLs(getenv)))
+	require.NoError(t, err)
+
+	_, err = gateway.FetchCommits(context.Background(), "org", "user", "")
+	require.NoError(t, err)
+	_, err = gateway.FetchCreatedPRs(context.Background(), "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"/api/v3/search/commits", "/custom/graphql"}, paths)
+}
+
+func TestActionsAPIURLs(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	rest, graphql := ActionsAPIURLs(env(map[string]string{
+		"GITHUB_API_URL":     "https://ghe.example.com/api/v3",
+		"GITHUB_GRAPHQL_URL": "https://ghe.example.com/api/graphql",
+	}))
+	assert.Equal(t, "https://ghe.example.com/api/v3", rest)
+	assert.Equal(t, "https://ghe.example.com/api/graphql", graphql)
+
+	// github.com runners point at the public API, which the clients use anyway.
+	rest, graphql = ActionsAPIURLs(env(map[string]string{
+		"GITHUB_API_URL":     "https://api.github.com",
+		"GITHUB_GRAPHQL_URL": "https://api.github.com/graphql",
+	}))
+	assert.Empty(t, rest)
+	assert.Empty(t, graphql)
+
+	rest, graphql = ActionsAPIURLs(env(nil))
+	assert.Empty(t, rest)
+	assert.Empty(t, graphql)
+}
+
+func TestEnterpriseURLs(t *testing.T) {
+	testCases := []struct {
+		baseURL, rest, graphql string
+	}{
+		{baseURL: "https://ghe.example.com", rest: "https://ghe.example.com/api/v3/", graphql: "https://ghe.example.com/api/graphql"},
+		{baseURL: "https://ghe.example.com/api/v3/", rest: "https://ghe.example.com/api/v3/", graphql: "https://ghe.example.com/api/graphql"},
+		{baseURL: "https://ghe.example.com/api/graphql", rest: "https://ghe.example.com/api/v3/", graphql: "https://ghe.example.com/api/graphql"},
+		{baseURL: "https://example.com/github/", rest: "https://example.com/github/api/v3/", graphql: "https://example.com/github/api/graphql"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.baseURL, func(t *testing.T) {
+			rest, graphql, err := enterpriseURLs(tc.baseURL)
+			require.NoError(t, err)
+			assert.Equal(t, tc.rest, rest)
+			assert.Equal(t, tc.graphql, graphql)
+		})
+	}
+
+	_, _, err := enterpriseURLs("ghe.example.com")
+	assert.ErrorContains(t, err, "invalid base URL")
+}
+
+func TestGitHubGateway_FetchPRSizes(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		assert.Contains(t, string(body), "author:any-user is:pr")
+		assert.Contains(t, string(body), "additions")
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":1,"additions":120,"deletions":30}},
+			{"node":{"__typename":"Issue"}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"number":2,"additions":3,"deletions":0}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	sizes, err := gateway.FetchPRSizes(context.Background(), "any-org", "any-user", "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]PRSize{"org/repo-a": {
+		{Number: 1, Additions: 120, Deletions: 30},
+		{Number: 2, Additions: 3, Deletions: 0},
+	}}, sizes)
+}
+
+func TestGitHubGateway_FetchReviewComments(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string `json:"query"`
+			Variables struct {
+				Query string `json:"query"`
+				User  string `json:"user"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Contains(t, req.Query, "reviews(first: 100, author: $user)")
+		assert.Equal(t, "org:any-org reviewed-by:any-user is:pr", req.Variables.Query)
+		assert.Equal(t, "any-user", req.Variables.User)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"reviews":{"nodes":[
+				{"comments":{"totalCount":3}},
+				{"comments":{"totalCount":0}}
+			]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"},"reviews":{"nodes":[{"comments":{"totalCount":2}}]}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"},"reviews":{"nodes":[{"comments":{"totalCount":0}}]}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	counts, err := gateway.FetchReviewComments(context.Background(), "any-org", "any-user", "")
+	assert.NoError(t, err)
+	// An approval without comments still lists the repository with zero comments.
+	assert.Equal(t, map[string]int{"org/repo-a": 5, "org/repo-b": 0}, counts)
+}
+
+func TestGitHubGateway_Labels(t *testing.T) {
+	testCases := []struct {
+		name          string
+		fetch         func(g *GitHubGateway) (map[string]int, error)
+		expectedQuery string
+	}{
+		{
+			name: "created PRs",
+			fetch: func(g *GitHubGateway) (map[string]int, error) {
+				return g.FetchCreatedPRs(context.Background(), "any-org", "any-user", " created:2025-01-01..2025-01-31")
+			},
+			expectedQuery: `org:any-org author:any-user is:pr label:"bugfix" label:"good first issue" created:2025-01-01..2025-01-31`,
+		},
+		{
+			name: "reviewed PRs",
+			fetch: func(g *GitHubGateway) (map[string]int, error) {
+				return g.FetchReviewedPRs(context.Background(), "any-org", "any-user", "")
+			},
+			expectedQuery: `org:any-org reviewed-by:any-user is:pr label:"bugfix" label:"good first issue"`,
+		},
+		{
+			name: "merged PRs",
+			fetch: func(g *GitHubGateway) (map[string]int, error) {
+				return g.FetchMergedPRs(context.Background(), "any-org", "any-user", "")
+			},
+			expectedQuery: `org:any-org author:any-user is:pr is:merged label:"bugfix" label:"good first issue"`,
+		},
+		{
+			name: "other searches are not filtered",
+			fetch: func(g *GitHubGateway) (map[string]int, error) {
+				return g.FetchCreatedIssues(context.Background(), "any-org", "any-user", "")
+			},
+			expectedQuery: "org:any-org author:any-user is:issue",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Variables struct {
+						Query string `json:"query"`
+					} `json:"variables"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, tc.expectedQuery, req.Variables.Query)
+				fmt.Fprint(w, `{"data":{"search":{"edges":[{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}}]}}}`)
+			}
+			gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+			defer server.Close()
+			WithLabels([]string{"bugfix", "good first issue"})(gateway)
+
+			counts, err := tc.fetch(gateway)
+			require.NoError(t, err)
+			assert.Equal(t, map[string]int{"org/repo-a": 1}, counts)
+		})
+	}
+}
+
+func TestGitHubGateway_FetchAssignedPRs(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Query string `json:"query"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "org:any-org assignee:any-user is:pr created:2025-01-01..2025-01-31", req.Variables.Query)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-b"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	counts, err := gateway.FetchAssignedPRs(context.Background(), "any-org", "any-user", " created:2025-01-01..2025-01-31")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo-a": 2, "org/repo-b": 1}, counts)
+}
+
+func TestGitHubGateway_FetchOpenPRs(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Variables struct {
+				Query string `json:"query"`
+			} `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "org:any-org author:any-user is:pr is:open", req.Variables.Query)
+		fmt.Fprint(w, `{"data":{"search":{"edges":[
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}},
+			{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo-a"}}}
+		]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	counts, err := gateway.FetchOpenPRs(context.Background(), "any-org", "any-user")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo-a": 2}, counts)
+}
+
+func TestGitHubGateway_FetchIssues(t *testing.T) {
+	testCases := []struct {
+		name          string
+		fetch         func(g *GitHubGateway) (map[string]int, error)
+		expectedQuery string
+	}{
+		{
+			name: "created issues",
+			fetch: func(g *GitHubGateway) (map[string]int, error) {
+				return g.FetchCreatedIssues(context.Background(), "any-org", "any-user", "")
+			},
+			expectedQuery: "org:any-org author:any-user is:issue",
+		},
+		{
+			name: "closed issues",
+			fetch: func(g *GitHubGateway) (map[string]int, error) {
+				return g.FetchClosedIssues(context.Background(), "any-org", "any-user", "")
+			},
+			expectedQuery: "org:any-org assignee:any-user is:issue is:closed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				var req struct {
+					Variables struct {
+						Query string `json:"query"`
+					} `json:"variables"`
+				}
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Equal(t, tc.expectedQuery, req.Variables.Query)
+				fmt.Fprint(w, `{"data":{"search":{"edges":[
+					{"node":{"__typename":"Issue","repository":{"nameWithOwner":"org/repo-a"}}},
+					{"node":{"__typename":"Issue","repository":{"nameWithOwner":"org/repo-a"}}},
+					{"node":{"__typename":"Issue","repository":{"nameWithOwner":"org/repo-b"}}}
+				]}}}`)
+			}
+			gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+			defer server.Close()
+
+			counts, err := tc.fetch(gateway)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]int{"org/repo-a": 2, "org/repo-b": 1}, counts)
+		})
+	}
+}
+
+// recordingProgress records the reported steps.
+type recordingProgress struct {
+	mu    sync.Mutex
+	steps []string
+}
+
+func (p *recordingProgress) Step(description string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.steps = append(p.steps, description)
+}
+
+func TestGitHubGateway_Progress(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/search/commits" {
+			fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+			return
+		}
+		fmt.Fprint(w, `{"data":{"search":{"edges":[]}}}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+	progress := &recordingProgress{}
+	WithProgress(progress)(gateway)
+
+	ctx := context.Background()
+	_, err := gateway.FetchCommits(ctx, "org", "user", "")
+	require.NoError(t, err)
+	_, err = gateway.FetchCreatedPRs(ctx, "org", "user", "")
+	require.NoError(t, err)
+	_, err = gateway.FetchReviewedPRs(ctx, "org", "user", "")
+	require.NoError(t, err)
+	_, err = gateway.FetchPRLeadTimes(ctx, "org", "user", "")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"[1/4] Fetching commit data using REST API...",
+		"[2/4] Fetching created PR data...",
+		"[3/4] Fetching reviewed PR data...",
+		"[4/4] Fetching PR lead time data...",
+	}, progress.steps)
+}
+
+func TestGitHubGateway_FetchTeamMembers(t *testing.T) {
+	var serverURL string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/orgs/acme/teams/platform/members", r.URL.Path)
+		assert.Equal(t, "100", r.URL.Query().Get("per_page"))
+		switch r.URL.Query().Get("page") {
+		case "":
+			w.Header().Set("Link", fmt.Sprintf(`<%s/orgs/acme/teams/platform/members?per_page=100&page=2>; rel="next"`, serverURL))
+			fmt.Fprint(w, `[{"login":"alice"},{"login":"bob"}]`)
+		case "2":
+			fmt.Fprint(w, `[{"login":"carol"}]`)
+		default:
+			t.Errorf("unexpected page %q", r.URL.Query().Get("page"))
+		}
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+	serverURL = server.URL
+
+	members, err := gateway.FetchTeamMembers(context.Background(), "acme", "platform")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob", "carol"}, members)
+}
+
+func TestGitHubGateway_FetchTeamMembers_NotFound(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message":"Not Found"}`)
+	}
+	gateway, server := setupTestGateway(t, http.HandlerFunc(handler))
+	defer server.Close()
+
+	_, err := gateway.FetchTeamMembers(context.Background(), "acme", "missing")
+	assert.ErrorContains(t, err, "failed to list members of team acme/missing")
+}
@@ -0,0 +1,126 @@
+package gateway
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The functions below build the search queries of the fetches. They are pure so the queries can be shown
+// without calling the API, e.g. by --dry-run. dateRange is appended as is, e.g. " created:2025-01-01..2025-01-31".
+
+// CommitsQuery searches the commits authored by the user (FetchCommits and FetchCommitTimes).
+func CommitsQuery(org, user, dateRange string) string {
+	return fmt.Sprintf("%s author:%s%s", orgQualifier(org), user, dateRange)
+}
+
+// MergedPRCommitsQuery searches the user's merged pull requests for their commits (FetchMergedPRCommits).
+func MergedPRCommitsQuery(org, user, dateRange string) string {
+	return MergedPRsQuery(org, user, dateRange, nil)
+}
+
+// MergedPRsQuery searches the user's merged pull requests (FetchMergedPRs),
+// limited to those carrying every one of labels like CreatedPRsQuery, so they stay a part of the created PRs.
+func MergedPRsQuery(org, user, dateRange string, labels []string) string {
+	return fmt.Sprintf("%s author:%s is:pr is:merged%s%s", orgQualifier(org), user, labelQualifiers(labels), dateRange)
+}
+
+// CreatedPRsQuery searches the pull requests created by the user (FetchCreatedPRs and FetchPRSizes),
+// limited to those carrying every one of labels.
+func CreatedPRsQuery(org, user, dateRange string, labels []string) string {
+	return fmt.Sprintf("%s author:%s is:pr%s%s", orgQualifier(org), user, labelQualifiers(labels), dateRange)
+}
+
+// ReviewedPRsQuery searches the pull requests reviewed by the user (FetchReviewedPRs, FetchReviewComments and FetchApprovals),
+// limited to those carrying every one of labels.
+func ReviewedPRsQuery(org, user, dateRange string, labels []string) string {
+	return fmt.Sprintf("%s reviewed-by:%s is:pr%s%s", orgQualifier(org), user, labelQualifiers(labels), dateRange)
+}
+
+// CommentedPRsQuery searches the pull requests the user commented on (FetchCommentedPRs).
+func CommentedPRsQuery(org, user, dateRange string) string {
+	return fmt.Sprintf("%s commenter:%s is:pr%s", orgQualifier(org), user, dateRange)
+}
+
+// CreatedIssuesQuery searches the issues opened by the user (FetchCreatedIssues).
+func CreatedIssuesQuery(org, user, dateRange string) string {
+	return fmt.Sprintf("%s author:%s is:issue%s", orgQualifier(org), user, dateRange)
+}
+
+// ClosedIssuesQuery searches the closed issues assigned to the user (FetchClosedIssues).
+func ClosedIssuesQuery(org, user, dateRange string) string {
+	return fmt.Sprintf("%s assignee:%s is:issue is:closed%s", orgQualifier(org), user, dateRange)
+}
+
+// AssignedPRsQuery searches the pull requests assigned to the user (FetchAssignedPRs).
+func AssignedPRsQuery(org, user, dateRange string) string {
+	return fmt.Sprintf("%s assignee:%s is:pr%s", orgQualifier(org), user, dateRange)
+}
+
+// OpenPRsQuery searches the user's pull requests that are open now (FetchOpenPRs).
+// It has no date range, since it describes the current state rather than a period.
+func OpenPRsQuery(org, user string) string {
+	return fmt.Sprintf("%s author:%s is:pr is:open", orgQualifier(org), user)
+}
+
+// PRLeadTimesQuery searches the user's merged or closed pull requests (FetchPRLeadTimes),
+// limited to those carrying every one of labels.
+func PRLeadTimesQuery(org, user, dateRange string, labels []string) string {
+	return fmt.Sprintf("%s author:%s is:pr is:closed%s%s", orgQualifier(org), user, labelQualifiers(labels), dateRange)
+}
+
+// RepoCommitTotalsQuery searches the commits of all authors in the "owner/name" repository (FetchRepoCommitTotals).
+func RepoCommitTotalsQuery(repo, dateRange string) string {
+	return fmt.Sprintf("repo:%s%s", repo, dateRange)
+}
+
+// RepoPRTotalsQuery searches the pull requests of all authors in the "owner/name" repository (FetchRepoPRTotals),
+// filtered like the user's created PRs so a share compares the same kind of PRs: limited to those carrying
+// every one of labels, and without drafts when excludeDrafts is set.
+func RepoPRTotalsQuery(repo, dateRange string, labels []string, excludeDrafts bool) string {
+	drafts := ""
+	if excludeDrafts {
+		drafts = " draft:false"
+	}
+	return fmt.Sprintf("repo:%s is:pr%s%s%s", repo, labelQualifiers(labels), drafts, dateRange)
+}
+
+// RepoLanguagesQuery searches the given "owner/name" repositories (FetchRepoLanguages).
+// fork:true keeps forks, which the repository search otherwise leaves out.
+func RepoLanguagesQuery(repos []string) string {
+	qualifiers := make([]string, 0, len(repos)+1)
+	qualifiers = append(qualifiers, "fork:true")
+	for _, repo := range repos {
+		qualifiers = append(qualifiers, "repo:"+repo)
+	}
+	return strings.Join(qualifiers, " ")
+}
+
+// labelQualifiers turns labels into label: qualifiers with a leading space, or "" for none.
+// The search ANDs separate qualifiers, so a pull request has to carry every label. The names are quoted,
+// since labels such as "good first issue" may contain spaces.
+func labelQualifiers(labels []string) string {
+	var b strings.Builder
+	for _, label := range labels {
+		fmt.Fprintf(&b, ` label:"%s"`, label)
+	}
+	return b.String()
+}
+
+// orgQualifier turns a comma-separated list of organizations into search qualifiers.
+// Several org: qualifiers in one query match any of the organizations, so each fetch costs the
+// same number of requests no matter how many organizations are given. The tradeoff is that the
+// search API's 1,000 result cap applies to all organizations together rather than to each one.
+func orgQualifier(org string) string {
+	var qualifiers []string
+	for _, o := range strings.Split(org, ",") {
+		if o = strings.TrimSpace(o); o == "" {
+			continue
+		}
+		if strings.Contains(o, "/") {
+			qualifiers = append(qualifiers, "repo:"+o)
+		} else {
+			qualifiers = append(qualifiers, "org:"+o)
+		}
+	}
+	return strings.Join(qualifiers, " ")
+}
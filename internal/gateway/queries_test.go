@@ -0,0 +1,42 @@
+package gateway
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoPRTotalsQuery_MatchesCreatedPRFilters(t *testing.T) {
+	testCases := []struct {
+		name          string
+		labels        []string
+		excludeDrafts bool
+		expected      string
+	}{
+		{name: "no filters", expected: "repo:org/repo is:pr created:2025-01-01..*"},
+		{
+			name:     "labels",
+			labels:   []string{"bug", "good first issue"},
+			expected: `repo:org/repo is:pr label:"bug" label:"good first issue" created:2025-01-01..*`,
+		},
+		{
+			name:          "labels without drafts",
+			labels:        []string{"bug"},
+			excludeDrafts: true,
+			expected:      `repo:org/repo is:pr label:"bug" draft:false created:2025-01-01..*`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query := RepoPRTotalsQuery("org/repo", " created:2025-01-01..*", tc.labels, tc.excludeDrafts)
+			assert.Equal(t, tc.expected, query)
+
+			// The totals carry the same label qualifiers as the user's created PRs they are compared with.
+			created := CreatedPRsQuery("org", "user", " created:2025-01-01..*", tc.labels)
+			assert.Equal(t, strings.Count(created, "label:"), strings.Count(query, "label:"))
+			assert.Contains(t, created, labelQualifiers(tc.labels))
+			assert.Contains(t, query, labelQualifiers(tc.labels))
+		})
+	}
+}
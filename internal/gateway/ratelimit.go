@@ -0,0 +1,69 @@
+package gateway
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gofri/go-github-ratelimit/github_ratelimit"
+)
+
+// DefaultMaxSleep is the longest secondary rate limit the gateway waits out by default.
+const DefaultMaxSleep = time.Hour
+
+// WithMaxSleep sets the longest secondary rate limit the gateway waits out before retrying a request.
+// A request hitting a longer limit fails with GitHub's rate limit error instead, e.g. so a CI job fails fast
+// rather than sleeping; zero never waits.
+func WithMaxSleep(maxSleep time.Duration) Option {
+	return func(g *GitHubGateway) {
+		g.maxSleep = maxSleep
+	}
+}
+
+// RateLimitSleeps adds up the pauses for secondary rate limits, e.g. for a summary after the run.
+// It is safe for concurrent use.
+type RateLimitSleeps struct {
+	mu    sync.Mutex
+	count int
+	total time.Duration
+}
+
+// add records a pause of d.
+func (s *RateLimitSleeps) add(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	s.total += d
+}
+
+// Summary returns how many pauses there were and how long they took together.
+func (s *RateLimitSleeps) Summary() (count int, total time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count, s.total
+}
+
+// WithRateLimitSleeps records every pause for a secondary rate limit in sleeps.
+func WithRateLimitSleeps(sleeps *RateLimitSleeps) Option {
+	return func(g *GitHubGateway) {
+		g.sleeps = sleeps
+	}
+}
+
+// secondaryRateLimitOptions configures the rate limit waiter built in NewGitHubGateway.
+func (g *GitHubGateway) secondaryRateLimitOptions() []github_ratelimit.Option {
+	return []github_ratelimit.Option{
+		github_ratelimit.WithLimitDetectedCallback(func(cc *github_ratelimit.CallbackContext) {
+			// GitHub gives the wait in whole seconds, so round off the time spent since the response.
+			wait := time.Until(*cc.SleepUntil).Round(time.Second)
+			g.logger.Printf("Pausing %s for a secondary rate limit on %s %s", wait, cc.Request.Method, cc.Request.URL.Path)
+			if g.sleeps != nil {
+				g.sleeps.add(wait)
+			}
+		}),
+		github_ratelimit.WithSingleSleepLimit(g.maxSleep, func(cc *github_ratelimit.CallbackContext) {
+			wait := time.Until(*cc.SleepUntil).Round(time.Second)
+			g.logger.Printf("Secondary rate limit asks to wait %s, more than the maximum sleep of %s; not retrying %s %s",
+				wait, g.maxSleep, cc.Request.Method, cc.Request.URL.Path)
+		}),
+	}
+}
@@ -0,0 +1,93 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// secondaryRateLimitServer answers the first request with a secondary rate limit of retryAfter seconds
+// and every later one with an empty commit search result.
+func secondaryRateLimitServer(retryAfter int) (*httptest.Server, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", fmt.Sprint(retryAfter))
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `{"message": "You have exceeded a secondary rate limit."}`)
+			return
+		}
+		fmt.Fprint(w, `{"total_count": 0, "items": []}`)
+	}))
+	return server, &requests
+}
+
+func TestNewGitHubGateway_MaxSleep(t *testing.T) {
+	server, requests := secondaryRateLimitServer(60)
+	defer server.Close()
+
+	var logs bytes.Buffer
+	gateway, err := NewGitHubGateway("token", log.New(&logs, "", 0), WithBaseURL(server.URL), WithMaxSleep(time.Second))
+	require.NoError(t, err)
+
+	start := time.Now()
+	_, err = gateway.FetchCommits(context.Background(), "org", "user", "")
+	assert.Error(t, err, "a limit above the maximum sleep fails the request")
+	assert.Less(t, time.Since(start), 10*time.Second, "the gateway does not sleep")
+	assert.Equal(t, int32(1), requests.Load())
+	assert.Contains(t, logs.String(), "more than the maximum sleep of 1s; not retrying GET /api/v3/search/commits")
+}
+
+func TestNewGitHubGateway_MaxSleep_WaitsShorterLimits(t *testing.T) {
+	server, requests := secondaryRateLimitServer(1)
+	defer server.Close()
+
+	var logs bytes.Buffer
+	gateway, err := NewGitHubGateway("token", log.New(&logs, "", 0), WithBaseURL(server.URL), WithMaxSleep(time.Minute))
+	require.NoError(t, err)
+
+	_, err = gateway.FetchCommits(context.Background(), "org", "user", "")
+	require.NoError(t, err, "a limit within the maximum sleep is waited out and retried")
+	assert.Equal(t, int32(2), requests.Load())
+	assert.NotContains(t, logs.String(), "maximum sleep")
+}
+
+func TestNewGitHubGateway_RateLimitSleeps(t *testing.T) {
+	server, requests := secondaryRateLimitServer(1)
+	defer server.Close()
+
+	var logs bytes.Buffer
+	var sleeps RateLimitSleeps
+	gateway, err := NewGitHubGateway("token", log.New(&logs, "", 0), WithBaseURL(server.URL), WithRateLimitSleeps(&sleeps))
+	require.NoError(t, err)
+
+	_, err = gateway.FetchCommits(context.Background(), "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Contains(t, logs.String(), "Pausing 1s for a secondary rate limit on GET /api/v3/search/commits")
+	count, total := sleeps.Summary()
+	assert.Equal(t, 1, count)
+	assert.Equal(t, time.Second, total)
+}
+
+func TestRateLimitSleeps(t *testing.T) {
+	var sleeps RateLimitSleeps
+	count, total := sleeps.Summary()
+	assert.Zero(t, count)
+	assert.Zero(t, total)
+
+	sleeps.add(30 * time.Second)
+	sleeps.add(90 * time.Second)
+	count, total = sleeps.Summary()
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 2*time.Minute, total)
+}
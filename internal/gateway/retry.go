@@ -0,0 +1,170 @@
+package gateway
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Default retry behavior for transient failures.
+const (
+	DefaultMaxRetries     = 2
+	DefaultRetryBaseDelay = time.Second
+)
+
+// WithRetries sets how often a request failing with a 5xx status or a network error is retried,
+// and the delay before the first retry. The delay doubles with every attempt and is jittered.
+// Client errors (4xx) are never retried.
+func WithRetries(maxRetries int, baseDelay time.Duration) Option {
+	return func(g *GitHubGateway) {
+		g.maxRetries = maxRetries
+		g.retryBaseDelay = baseDelay
+	}
+}
+
+// PhaseRetries are the retries of one fetch phase.
+type PhaseRetries struct {
+	// Retries counts the retries of transient failures.
+	Retries int
+	// Exhausted reports whether a request still failed after its last retry.
+	Exhausted bool
+}
+
+// RetryStats counts the retries of transient failures per fetch phase, e.g. "created PRs",
+// and records whether a request in the phase used up its retries. It is safe for concurrent use.
+type RetryStats struct {
+	mu     sync.Mutex
+	phases map[string]PhaseRetries
+}
+
+// update applies f to the retries of phase.
+func (s *RetryStats) update(phase string, f func(*PhaseRetries)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.phases == nil {
+		s.phases = make(map[string]PhaseRetries)
+	}
+	retries := s.phases[phase]
+	f(&retries)
+	s.phases[phase] = retries
+}
+
+// retried records a retry in phase.
+func (s *RetryStats) retried(phase string) {
+	s.update(phase, func(r *PhaseRetries) { r.Retries++ })
+}
+
+// gaveUp records that a request in phase used up its retries.
+func (s *RetryStats) gaveUp(phase string) {
+	s.update(phase, func(r *PhaseRetries) { r.Exhausted = true })
+}
+
+// Drain returns the retries per phase, or nil when nothing was retried, and forgets them so a later run
+// only reports its own.
+func (s *RetryStats) Drain() map[string]PhaseRetries {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	phases := s.phases
+	s.phases = nil
+	return phases
+}
+
+// WithRetryStats counts the retries of every request in stats.
+func WithRetryStats(stats *RetryStats) Option {
+	return func(g *GitHubGateway) {
+		g.retryStats = stats
+	}
+}
+
+// phaseKey is the context key of the fetch phase a request belongs to.
+type phaseKey struct{}
+
+// withPhase tags the requests made with ctx as belonging to phase.
+func withPhase(ctx context.Context, phase string) context.Context {
+	return context.WithValue(ctx, phaseKey{}, phase)
+}
+
+// phaseOf returns the phase ctx was tagged with, or "other" for requests outside a fetch step.
+func phaseOf(ctx context.Context) string {
+	if phase, ok := ctx.Value(phaseKey{}).(string); ok {
+		return phase
+	}
+	return "other"
+}
+
+// retryTransport retries requests that failed for transient reasons.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	baseDelay  time.Duration
+	logger     *log.Logger
+	// stats counts the retries when set.
+	stats *RetryStats
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if !isTransient(resp, err) {
+			return resp, err
+		}
+		if attempt >= t.maxRetries {
+			if t.stats != nil && t.maxRetries > 0 {
+				t.stats.gaveUp(phaseOf(req.Context()))
+			}
+			return resp, err
+		}
+		// A request body can only be sent again if it can be recreated.
+		if req.Body != nil && req.GetBody == nil {
+			return resp, err
+		}
+		if t.stats != nil {
+			t.stats.retried(phaseOf(req.Context()))
+		}
+
+		if err != nil {
+			t.logger.Printf("  Request to %s failed (%v), retrying...\n", req.URL.Path, err)
+		} else {
+			t.logger.Printf("  Request to %s returned %s, retrying...\n", req.URL.Path, resp.Status)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(backoff(t.baseDelay, attempt))
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		case <-timer.C:
+		}
+
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+	}
+}
+
+// isTransient reports whether a request may succeed when sent again.
+func isTransient(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	return resp.StatusCode >= http.StatusInternalServerError
+}
+
+// backoff returns the delay before retry number attempt (starting at 0): baseDelay doubled per attempt,
+// with jitter spreading it over [delay/2, delay) so concurrent clients don't retry in lockstep.
+func backoff(baseDelay time.Duration, attempt int) time.Duration {
+	delay := baseDelay << attempt
+	if delay <= 1 {
+		return delay
+	}
+	return delay/2 + rand.N(delay/2)
+}
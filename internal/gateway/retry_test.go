@@ -0,0 +1,123 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetries_FailTwiceThenSucceed(t *testing.T) {
+	var graphqlRequests, restRequests int
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/graphql" {
+			graphqlRequests++
+			// The body must be resent intact on every attempt.
+			body, err := io.ReadAll(r.Body)
+			require.NoError(t, err)
+			assert.Contains(t, string(body), "org:org author:user is:pr")
+			if graphqlRequests <= 2 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			fmt.Fprint(w, `{"data":{"search":{"edges":[{"node":{"__typename":"PullRequest","repository":{"nameWithOwner":"org/repo"}}}]}}}`)
+			return
+		}
+		restRequests++
+		if restRequests <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"total_count": 1, "items": [{"repository": {"full_name": "org/repo"}}]}`)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	var stats RetryStats
+	gateway, err := NewGitHubGateway("token", log.New(io.Discard, "", 0), WithBaseURL(server.URL), WithRetries(2, time.Millisecond), WithRetryStats(&stats))
+	require.NoError(t, err)
+
+	prs, err := gateway.FetchCreatedPRs(context.Background(), "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo": 1}, prs)
+	assert.Equal(t, 3, graphqlRequests)
+
+	commits, err := gateway.FetchCommits(context.Background(), "org", "user", "")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"org/repo": 1}, commits)
+	assert.Equal(t, 3, restRequests)
+
+	assert.Equal(t, map[string]PhaseRetries{"created PRs": {Retries: 2}, "commits": {Retries: 2}}, stats.Drain(),
+		"every request succeeded within its retries")
+	assert.Nil(t, stats.Drain(), "draining forgets the retries")
+}
+
+func TestRetries_GiveUp(t *testing.T) {
+	testCases := []struct {
+		name             string
+		status           int
+		expectedRequests int
+		expectedRetries  map[string]PhaseRetries
+	}{
+		{name: "client errors are not retried", status: http.StatusNotFound, expectedRequests: 1},
+		{
+			name:             "server errors stop after the last retry",
+			status:           http.StatusInternalServerError,
+			expectedRequests: 3,
+			expectedRetries:  map[string]PhaseRetries{"created PRs": {Retries: 2, Exhausted: true}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tc.status)
+			}
+			server := httptest.NewServer(http.HandlerFunc(handler))
+			defer server.Close()
+
+			var stats RetryStats
+			gateway, err := NewGitHubGateway("token", log.New(io.Discard, "", 0), WithBaseURL(server.URL), WithRetries(2, time.Millisecond), WithRetryStats(&stats))
+			require.NoError(t, err)
+
+			_, err = gateway.FetchCreatedPRs(context.Background(), "org", "user", "")
+			assert.Error(t, err)
+			assert.Equal(t, tc.expectedRequests, requests)
+			assert.Equal(t, tc.expectedRetries, stats.Drain())
+		})
+	}
+}
+
+func TestRetries_ContextCancelledBetweenAttempts(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	gateway, err := NewGitHubGateway("token", log.New(io.Discard, "", 0), WithBaseURL(server.URL), WithRetries(5, time.Hour))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err = gateway.FetchCreatedPRs(ctx, "org", "user", "")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 4; attempt++ {
+		delay := backoff(time.Second, attempt)
+		max := time.Second << attempt
+		assert.GreaterOrEqual(t, delay, max/2)
+		assert.Less(t, delay, max)
+	}
+	assert.Equal(t, time.Duration(0), backoff(0, 3))
+}
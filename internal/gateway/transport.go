@@ -0,0 +1,136 @@
+package gateway
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// WithProxy sends every request through the proxy at proxyURL instead of the one configured
+// in HTTP_PROXY, HTTPS_PROXY and NO_PROXY.
+func WithProxy(proxyURL *url.URL) Option {
+	return func(g *GitHubGateway) {
+		g.proxyURL = proxyURL
+	}
+}
+
+// WithRootCAs verifies the server certificates against pool instead of the system roots,
+// e.g. for a GitHub Enterprise Server with a certificate from an internal CA.
+func WithRootCAs(pool *x509.CertPool) Option {
+	return func(g *GitHubGateway) {
+		g.rootCAs = pool
+	}
+}
+
+// WithInsecureSkipVerify accepts any server certificate. It leaves the connection open to
+// man-in-the-middle attacks and is only meant for testing against a server with a broken certificate.
+func WithInsecureSkipVerify() Option {
+	return func(g *GitHubGateway) {
+		g.insecureSkipVerify = true
+	}
+}
+
+// LoadCACertPool returns the system roots with the PEM certificates in the file at path added.
+func LoadCACertPool(path string) (*x509.CertPool, error) {
+	pem, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no PEM certificate found in %s", path)
+	}
+	return pool, nil
+}
+
+// WithHTTPDebug writes a line to w for every HTTP request the gateway sends, retries included,
+// with its method, URL, headers, response status and duration. The Authorization header is redacted.
+func WithHTTPDebug(w io.Writer) Option {
+	return func(g *GitHubGateway) {
+		g.httpDebug = w
+	}
+}
+
+// debugTransport logs every request passing through it.
+type debugTransport struct {
+	base http.RoundTripper
+	w    io.Writer
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	result := fmt.Sprintf("error: %v", err)
+	if err == nil {
+		result = resp.Status
+	}
+	fmt.Fprintf(t.w, "HTTP %s %s -> %s in %s [%s]\n", req.Method, req.URL, result, elapsed, redactedHeaders(req.Header))
+	return resp, err
+}
+
+// redactedHeaders formats header as sorted "Name: value" pairs, keeping only the scheme of the Authorization
+// header so the token never ends up in a log.
+func redactedHeaders(header http.Header) string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		value := strings.Join(header.Values(name), ", ")
+		if strings.EqualFold(name, "Authorization") {
+			scheme, _, _ := strings.Cut(value, " ")
+			value = scheme + " [REDACTED]"
+		}
+		pairs = append(pairs, name+": "+value)
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// ParseProxyURL parses the URL of an http, https or socks5 proxy.
+func ParseProxyURL(s string) (*url.URL, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy URL %q must start with http://, https:// or socks5://", s)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL %q has no host", s)
+	}
+	return u, nil
+}
+
+// baseTransport returns the transport the retries, rate limit waiter and token sit on top of.
+// It has the settings of http.DefaultTransport, the proxy from WithProxy, or from the environment,
+// and the certificate checks from WithRootCAs and WithInsecureSkipVerify.
+func (g *GitHubGateway) baseTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	if g.proxyURL != nil {
+		transport.Proxy = http.ProxyURL(g.proxyURL)
+	}
+	if g.rootCAs != nil || g.insecureSkipVerify {
+		if transport.TLSClientConfig == nil {
+			transport.TLSClientConfig = &tls.Config{}
+		}
+		transport.TLSClientConfig.RootCAs = g.rootCAs
+		transport.TLSClientConfig.InsecureSkipVerify = g.insecureSkipVerify
+	}
+	return transport
+}
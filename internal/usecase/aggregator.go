@@ -2,13 +2,20 @@
 package usecase
 
 import (
+	"cmp"
 	"context"
+	"errors"
+	"fmt"
 	"log"
 	"sort"
+	"sync"
+	"time"
 
+	"github.com/montanaflynn/stats"
 	"github.com/naka-gawa/github-stats/internal/domain"
 	"github.com/naka-gawa/github-stats/internal/gateway"
 	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/semaphore"
 )
 
 // Aggregator is the use case for aggregating GitHub stats.
@@ -16,51 +23,343 @@ import (
 type Aggregator struct {
 	fetcher gateway.Fetcher
 	logger  *log.Logger
+	// sem bounds the gateway calls in flight across all users and fetches.
+	sem *semaphore.Weighted
+}
+
+// DefaultConcurrency is how many gateway calls an Aggregator runs at once unless WithConcurrency says otherwise.
+const DefaultConcurrency = 4
+
+// AggregatorOption configures an Aggregator.
+type AggregatorOption func(*Aggregator)
+
+// WithConcurrency bounds the number of concurrent gateway calls to n, which must be positive.
+// Fewer parallel requests are slower but less likely to trip GitHub's secondary rate limits.
+func WithConcurrency(n int) AggregatorOption {
+	return func(a *Aggregator) {
+		a.sem = semaphore.NewWeighted(int64(n))
+	}
+}
+
+// Options controls which data Aggregate fetches and how the queries are filtered.
+type Options struct {
+	// CommitDateRange is appended to the commit search query (e.g. " author-date:2025-01-01..2025-01-31").
+	CommitDateRange string
+	// PRDateRange is appended to the pull request search queries (e.g. " created:2025-01-01..2025-01-31").
+	PRDateRange string
+	// SkipCommits, SkipCreatedPRs and SkipReviewedPRs leave out the corresponding fetch phase entirely.
+	SkipCommits     bool
+	SkipCreatedPRs  bool
+	SkipReviewedPRs bool
+	// Drafts additionally counts the created PRs that are still drafts, and ExcludeDrafts also leaves them out
+	// of the created PR count. Both need the created PR fetch. The time series counts are not affected.
+	Drafts        bool
+	ExcludeDrafts bool
+	// MergedPRs additionally counts the created PRs that have been merged.
+	MergedPRs bool
+	// CommentStats additionally counts the pull requests the user commented on.
+	CommentStats bool
+	// MergedCommitsOnly counts only the user's commits on merged pull requests instead of every authored commit.
+	MergedCommitsOnly bool
+	// CalculateLeadTime controls whether the expensive lead time query is executed.
+	CalculateLeadTime bool
+	// HandleReopens measures lead time from the latest reopen instead of creation,
+	// so the time a PR spent closed is not counted.
+	HandleReopens bool
+	// Share additionally fetches the totals of all authors for every repository the user touched.
+	Share bool
+	// Issues additionally counts the issues the user opened and the closed issues assigned to the user.
+	Issues bool
+	// ReviewComments additionally counts the comments the user left in reviews.
+	ReviewComments bool
+	// Approvals additionally counts the pull requests the user approved.
+	Approvals bool
+	// Assigned additionally counts the pull requests assigned to the user.
+	Assigned bool
+	// OpenPRs additionally counts the user's pull requests that are open now. The date range doesn't apply to them.
+	OpenPRs bool
+	// Heatmap additionally counts the user's commits per weekday and hour of their author date in HeatmapLocation,
+	// which is UTC when nil.
+	Heatmap         bool
+	HeatmapLocation *time.Location
+	// CommitChurn additionally sums the lines added and deleted by the user's commits from the contributor statistics
+	// of every repository the user committed to, or of every repository in the result when commits are skipped.
+	CommitChurn bool
+	// ReviewerLatency additionally measures how long the user took to first review each PR after it became ready.
+	ReviewerLatency bool
+	// AuthorWait additionally measures how long each PR the user created waited for its first review by someone else.
+	AuthorWait bool
+	// Churn additionally fetches the size of every created PR for the addition, deletion and median size stats.
+	Churn bool
+	// WithNodeIDs resolves the GraphQL node ID of every repository in the result.
+	WithNodeIDs bool
+	// Languages resolves the primary language of every repository in the result.
+	Languages bool
+	// Labels are the PR labels the gateway was set up with (gateway.WithLabels). The fetches don't need them,
+	// but PlannedQueries shows them in the queries.
+	Labels []string
+	// IncludeRepos and ExcludeRepos are glob patterns matched against "owner/name".
+	// When IncludeRepos is set only matching repositories are kept; ExcludeRepos wins when both match.
+	IncludeRepos []string
+	ExcludeRepos []string
+	// Buckets additionally splits the commit and PR counts into a time series with one set of queries per bucket.
+	// The whole-range counts are still fetched once, so they stay exact.
+	Buckets []Bucket
+	// BestEffort keeps going when a fetch fails: the other fetches are not cancelled and the available data is merged.
+	// Each failure is passed to OnFetchError; Aggregate only fails when every fetch failed.
+	BestEffort bool
+	// OnFetchError is called with the user, a short description of the failed fetch and its error in best-effort mode.
+	// It may be called concurrently.
+	OnFetchError func(user, fetch string, err error)
+}
+
+// reportFetchError passes a tolerated fetch failure to OnFetchError, if set.
+func (opts Options) reportFetchError(user, fetch string, err error) {
+	if opts.OnFetchError != nil {
+		opts.OnFetchError(user, fetch, err)
+	}
+}
+
+// fetchFailures collects the errors of concurrent fetches.
+type fetchFailures struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (f *fetchFailures) add(fetch string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.errs = append(f.errs, fmt.Errorf("%s: %w", fetch, err))
+}
+
+func (f *fetchFailures) join() error {
+	return errors.Join(f.errs...)
 }
 
 // NewAggregator creates a new Aggregator instance.
-func NewAggregator(fetcher gateway.Fetcher, logger *log.Logger) *Aggregator {
-	return &Aggregator{
+func NewAggregator(fetcher gateway.Fetcher, logger *log.Logger, opts ...AggregatorOption) *Aggregator {
+	a := &Aggregator{
 		fetcher: fetcher,
 		logger:  logger,
+		sem:     semaphore.NewWeighted(DefaultConcurrency),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	return a
+}
+
+// limited runs call once a concurrency slot is free. Every gateway call goes through it.
+func (a *Aggregator) limited(ctx context.Context, call func() error) error {
+	if err := a.sem.Acquire(ctx, 1); err != nil {
+		return err
+	}
+	defer a.sem.Release(1)
+	return call()
+}
+
+// maxConcurrentUsers caps how many users are aggregated at once.
+// Each user already runs its fetches concurrently, so this bounds the total number of in-flight requests.
+const maxConcurrentUsers = 2
+
+// AggregateUsers runs Aggregate for every user and tags each result with the user it belongs to.
+// The results are grouped by user in the given order, and sorted by repository name within each user.
+func (a *Aggregator) AggregateUsers(ctx context.Context, org string, users []string, opts Options) ([]*domain.RepoStats, error) {
+	resultsByUser := make([][]*domain.RepoStats, len(users))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(maxConcurrentUsers)
+	for i, user := range users {
+		eg.Go(func() error {
+			results, err := a.Aggregate(egCtx, org, user, opts)
+			if err != nil {
+				return fmt.Errorf("failed to aggregate stats for user %s: %w", user, err)
+			}
+			for _, repoStat := range results {
+				repoStat.User = user
+			}
+			resultsByUser[i] = results
+			return nil
+		})
+	}
+	if err := eg.Wait(); err != nil {
+		return nil, err
+	}
+
+	var allResults []*domain.RepoStats
+	for _, results := range resultsByUser {
+		allResults = append(allResults, results...)
+	}
+	return allResults, nil
 }
 
 // Aggregate performs the main business logic.
 // It fetches all required data concurrently from the gateway and aggregates it.
-// The `calculateLeadTime` flag controls whether the expensive lead time query is executed.
-func (a *Aggregator) Aggregate(ctx context.Context, org, user, commitDateRange, prDateRange string, calculateLeadTime bool) ([]*domain.RepoStats, error) {
+func (a *Aggregator) Aggregate(ctx context.Context, org, user string, opts Options) ([]*domain.RepoStats, error) {
 	a.logger.Println("Usecase: Starting data aggregation...")
 
-	var commitCounts, createdPRCounts, reviewedPRCounts map[string]int
+	var commitCounts, createdPRCounts, reviewedPRCounts, commentedPRCounts, reviewCommentCounts map[string]int
+	var createdIssueCounts, closedIssueCounts, approvalCounts, draftPRCounts, mergedPRCounts, assignedPRCounts, openPRCounts map[string]int
 	var leadTimesByRepo map[string][]gateway.PRLeadTimeData
+	var prSizesByRepo map[string][]gateway.PRSize
+	var reviewerLatenciesByRepo map[string][]gateway.ReviewerLatencyData
+	var authorWaitsByRepo map[string][]gateway.AuthorWaitData
+	var commitTimesByRepo map[string][]time.Time
 
 	// Use an errgroup to fetch all data concurrently.
+	// In best-effort mode a failure must not cancel the other fetches, so the group gets no shared context.
 	eg, egCtx := errgroup.WithContext(ctx)
+	if opts.BestEffort {
+		eg, egCtx = &errgroup.Group{}, ctx
+	}
+	var fetches int
+	var failures fetchFailures
+	goFetch := func(name string, fetch func() error) {
+		fetches++
+		eg.Go(func() error {
+			err := a.limited(egCtx, fetch)
+			if err != nil && opts.BestEffort {
+				failures.add(name, err)
+				opts.reportFetchError(user, name, err)
+				return nil
+			}
+			return err
+		})
+	}
 
-	eg.Go(func() error {
-		var err error
-		commitCounts, err = a.fetcher.FetchCommits(egCtx, org, user, commitDateRange)
-		return err
-	})
+	if !opts.SkipCommits {
+		goFetch("commits", func() error {
+			var err error
+			if opts.MergedCommitsOnly {
+				commitCounts, err = a.fetcher.FetchMergedPRCommits(egCtx, org, user, opts.PRDateRange)
+			} else {
+				commitCounts, err = a.fetcher.FetchCommits(egCtx, org, user, opts.CommitDateRange)
+			}
+			return err
+		})
+	}
 
-	eg.Go(func() error {
-		var err error
-		createdPRCounts, err = a.fetcher.FetchCreatedPRs(egCtx, org, user, prDateRange)
-		return err
-	})
+	if !opts.SkipCreatedPRs {
+		goFetch("created PRs", func() error {
+			var err error
+			createdPRCounts, err = a.fetcher.FetchCreatedPRs(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+		if opts.Drafts || opts.ExcludeDrafts {
+			goFetch("draft PRs", func() error {
+				var err error
+				draftPRCounts, err = a.fetcher.FetchDraftPRs(egCtx, org, user, opts.PRDateRange)
+				return err
+			})
+		}
+	}
 
-	eg.Go(func() error {
-		var err error
-		reviewedPRCounts, err = a.fetcher.FetchReviewedPRs(egCtx, org, user, prDateRange)
-		return err
-	})
+	if opts.MergedPRs {
+		goFetch("merged PRs", func() error {
+			var err error
+			mergedPRCounts, err = a.fetcher.FetchMergedPRs(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if !opts.SkipReviewedPRs {
+		goFetch("reviewed PRs", func() error {
+			var err error
+			reviewedPRCounts, err = a.fetcher.FetchReviewedPRs(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.CommentStats {
+		goFetch("commented PRs", func() error {
+			var err error
+			commentedPRCounts, err = a.fetcher.FetchCommentedPRs(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
 
 	// Only fetch lead time data if requested.
-	if calculateLeadTime {
-		eg.Go(func() error {
+	if opts.CalculateLeadTime {
+		goFetch("lead times", func() error {
+			var err error
+			leadTimesByRepo, err = a.fetcher.FetchPRLeadTimes(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.Issues {
+		goFetch("created issues", func() error {
+			var err error
+			createdIssueCounts, err = a.fetcher.FetchCreatedIssues(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+		goFetch("closed issues", func() error {
+			var err error
+			closedIssueCounts, err = a.fetcher.FetchClosedIssues(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.ReviewComments {
+		goFetch("review comments", func() error {
+			var err error
+			reviewCommentCounts, err = a.fetcher.FetchReviewComments(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.Approvals {
+		goFetch("approvals", func() error {
+			var err error
+			approvalCounts, err = a.fetcher.FetchApprovals(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.Assigned {
+		goFetch("assigned PRs", func() error {
 			var err error
-			leadTimesByRepo, err = a.fetcher.FetchPRLeadTimes(egCtx, org, user, prDateRange)
+			assignedPRCounts, err = a.fetcher.FetchAssignedPRs(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.OpenPRs {
+		goFetch("open PRs", func() error {
+			var err error
+			openPRCounts, err = a.fetcher.FetchOpenPRs(egCtx, org, user)
+			return err
+		})
+	}
+
+	if opts.Heatmap {
+		goFetch("commit times", func() error {
+			var err error
+			commitTimesByRepo, err = a.fetcher.FetchCommitTimes(egCtx, org, user, opts.CommitDateRange)
+			return err
+		})
+	}
+
+	if opts.ReviewerLatency {
+		goFetch("reviewer latencies", func() error {
+			var err error
+			reviewerLatenciesByRepo, err = a.fetcher.FetchReviewerLatencies(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.AuthorWait {
+		goFetch("author waits", func() error {
+			var err error
+			authorWaitsByRepo, err = a.fetcher.FetchAuthorWaits(egCtx, org, user, opts.PRDateRange)
+			return err
+		})
+	}
+
+	if opts.Churn {
+		goFetch("PR sizes", func() error {
+			var err error
+			prSizesByRepo, err = a.fetcher.FetchPRSizes(egCtx, org, user, opts.PRDateRange)
 			return err
 		})
 	}
@@ -68,7 +367,14 @@ func (a *Aggregator) Aggregate(ctx context.Context, org, user, commitDateRange,
 	if err := eg.Wait(); err != nil {
 		return nil, err
 	}
-	a.logger.Println("Usecase: All data fetched successfully.")
+	if fetches > 0 && len(failures.errs) == fetches {
+		return nil, fmt.Errorf("every fetch failed: %w", failures.join())
+	}
+	if len(failures.errs) > 0 {
+		a.logger.Printf("Usecase: %d of %d fetches failed, continuing with partial data.\n", len(failures.errs), fetches)
+	} else {
+		a.logger.Println("Usecase: All data fetched successfully.")
+	}
 
 	// Merge all results into a single map.
 	statsMap := make(map[string]*domain.RepoStats)
@@ -88,20 +394,193 @@ func (a *Aggregator) Aggregate(ctx context.Context, org, user, commitDateRange,
 		ensureRepoStat(repoName)
 		statsMap[repoName].CreatedPRs = count
 	}
+	for repoName, count := range mergedPRCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].MergedPRs = count
+	}
+	for repoName, count := range draftPRCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].DraftPRs = count
+		// Without the created PR counts (a best-effort failure) there is nothing to subtract from.
+		if opts.ExcludeDrafts && createdPRCounts != nil {
+			statsMap[repoName].CreatedPRs -= count
+		}
+	}
 	for repoName, count := range reviewedPRCounts {
 		ensureRepoStat(repoName)
 		statsMap[repoName].ReviewedPRs = count
 	}
+	for repoName, count := range commentedPRCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].CommentedPRs = count
+	}
+
+	for repoName, count := range createdIssueCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].CreatedIssues = count
+	}
+	for repoName, count := range closedIssueCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].ClosedIssues = count
+	}
+	for repoName, count := range reviewCommentCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].ReviewComments = count
+	}
+	for repoName, count := range approvalCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].ApprovalsGiven = count
+	}
+	for repoName, count := range assignedPRCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].AssignedPRs = count
+	}
+	for repoName, count := range openPRCounts {
+		ensureRepoStat(repoName)
+		statsMap[repoName].OpenPRs = count
+	}
+	for repoName, sizes := range prSizesByRepo {
+		ensureRepoStat(repoName)
+		addPRSizes(statsMap[repoName], sizes)
+	}
+	heatmapLocation := cmp.Or(opts.HeatmapLocation, time.UTC)
+	for repoName, times := range commitTimesByRepo {
+		ensureRepoStat(repoName)
+		heatmap := &domain.Heatmap{}
+		for _, t := range times {
+			heatmap.Add(t, heatmapLocation)
+		}
+		statsMap[repoName].CommitHeatmap = heatmap
+	}
+	for repoName, latencies := range reviewerLatenciesByRepo {
+		ensureRepoStat(repoName)
+		for _, data := range latencies {
+			// A review of the draft, before it was marked ready, counts as an immediate response.
+			latency := max(data.FirstReviewAt.Sub(data.ReadyAt), 0)
+			statsMap[repoName].ReviewerLatencySeconds = append(statsMap[repoName].ReviewerLatencySeconds, latency.Seconds())
+		}
+	}
+	for repoName, waits := range authorWaitsByRepo {
+		ensureRepoStat(repoName)
+		for _, data := range waits {
+			// Clamped like the reviewer latency, in case the clocks disagree.
+			wait := max(data.FirstReviewAt.Sub(data.CreatedAt), 0)
+			statsMap[repoName].AuthorWaitSeconds = append(statsMap[repoName].AuthorWaitSeconds, wait.Seconds())
+		}
+	}
 
 	// Calculate and add lead times if the data was fetched.
-	if calculateLeadTime {
+	if opts.CalculateLeadTime {
 		for repoName, leadTimeDataList := range leadTimesByRepo {
 			ensureRepoStat(repoName)
-			for _, data := range leadTimeDataList {
-				// Calculate the duration from creation to the last review.
-				duration := data.LastReviewedAt.Sub(data.CreatedAt)
+			for _, data := range sortedLeadTimeData(leadTimeDataList) {
+				// Closed-without-merge PRs have no merge time.
+				if !data.MergedAt.IsZero() {
+					statsMap[repoName].MergeTimeSeconds = append(statsMap[repoName].MergeTimeSeconds, data.MergedAt.Sub(data.CreatedAt).Seconds())
+				}
+				if data.LastReviewedAt.IsZero() {
+					continue // Merged without review, so there is no review lead time.
+				}
+				statsMap[repoName].TimeToFirstReviewSeconds = append(statsMap[repoName].TimeToFirstReviewSeconds, data.FirstReviewedAt.Sub(data.CreatedAt).Seconds())
+
+				// Calculate the duration from creation (or the latest reopen) to the last review.
+				duration := data.LastReviewedAt.Sub(leadTimeStart(data, opts.HandleReopens))
 				statsMap[repoName].LeadTimeToLastReviewSeconds = append(statsMap[repoName].LeadTimeToLastReviewSeconds, duration.Seconds())
+				statsMap[repoName].PullRequests = append(statsMap[repoName].PullRequests, domain.PRLeadTime{
+					Number:          data.Number,
+					CreatedAt:       data.CreatedAt,
+					LeadTimeSeconds: duration.Seconds(),
+					Additions:       data.Additions,
+					Deletions:       data.Deletions,
+				})
+			}
+		}
+	}
+
+	// Filter before any per-repository follow-up queries, so excluded repositories cost nothing extra.
+	filterRepos(statsMap, opts.IncludeRepos, opts.ExcludeRepos)
+
+	if len(opts.Buckets) > 0 {
+		if err := a.fetchSeries(ctx, org, user, statsMap, opts); err != nil {
+			if !opts.BestEffort {
+				return nil, err
+			}
+			opts.reportFetchError(user, "time series", err)
+		}
+	}
+
+	if opts.Share {
+		if err := a.fetchShareTotals(ctx, statsMap, opts); err != nil {
+			if !opts.BestEffort {
+				return nil, err
+			}
+			opts.reportFetchError(user, "share totals", err)
+		}
+	}
+
+	if opts.WithNodeIDs {
+		var nodeIDs map[string]string
+		err := a.limited(ctx, func() error {
+			var err error
+			nodeIDs, err = a.fetcher.FetchRepoNodeIDs(ctx, repoNames(statsMap))
+			return err
+		})
+		switch {
+		case err == nil:
+			for repoName, repoStat := range statsMap {
+				repoStat.NodeID = nodeIDs[repoName]
+			}
+		case opts.BestEffort:
+			opts.reportFetchError(user, "node IDs", err)
+		default:
+			return nil, err
+		}
+	}
+
+	if opts.CommitChurn {
+		var repos []string
+		for _, name := range repoNames(statsMap) {
+			if opts.SkipCommits || statsMap[name].Commits > 0 {
+				repos = append(repos, name)
+			}
+		}
+		var churn map[string]gateway.CommitChurn
+		err := a.limited(ctx, func() error {
+			var err error
+			churn, err = a.fetcher.FetchCommitChurn(ctx, repos, user, opts.CommitDateRange)
+			return err
+		})
+		switch {
+		case err == nil:
+			for repoName, repoChurn := range churn {
+				if repoStat, ok := statsMap[repoName]; ok {
+					repoStat.CommitAdditions = repoChurn.Additions
+					repoStat.CommitDeletions = repoChurn.Deletions
+				}
 			}
+		case opts.BestEffort:
+			opts.reportFetchError(user, "commit churn", err)
+		default:
+			return nil, err
+		}
+	}
+
+	if opts.Languages {
+		var languages map[string]string
+		err := a.limited(ctx, func() error {
+			var err error
+			languages, err = a.fetcher.FetchRepoLanguages(ctx, repoNames(statsMap))
+			return err
+		})
+		switch {
+		case err == nil:
+			for repoName, repoStat := range statsMap {
+				repoStat.PrimaryLanguage = languages[repoName]
+			}
+		case opts.BestEffort:
+			opts.reportFetchError(user, "languages", err)
+		default:
+			return nil, err
 		}
 	}
 
@@ -117,3 +596,88 @@ func (a *Aggregator) Aggregate(ctx context.Context, org, user, commitDateRange,
 	a.logger.Println("Usecase: Aggregation complete.")
 	return sortedStats, nil
 }
+
+// addPRSizes adds the churn of the given pull requests to repoStat and updates its median PR size.
+func addPRSizes(repoStat *domain.RepoStats, sizes []gateway.PRSize) {
+	for _, size := range sizes {
+		repoStat.TotalAdditions += size.Additions
+		repoStat.TotalDeletions += size.Deletions
+		repoStat.PRSizeLines = append(repoStat.PRSizeLines, float64(size.Additions+size.Deletions))
+	}
+	repoStat.MedianPRSizeLines = medianOf(repoStat.PRSizeLines)
+}
+
+// medianOf returns the median of values, or 0 when there are none.
+// With an even number of values it is the mean of the two middle ones.
+func medianOf(values []float64) float64 {
+	median, err := stats.Median(values)
+	if err != nil {
+		return 0
+	}
+	return median
+}
+
+// leadTimeStart returns when the lead time of a PR starts.
+// With handleReopens, a reopen that happened before the last review starts the latest open-to-review interval.
+// A review that predates the reopen belongs to an earlier interval, so the creation time is kept then.
+func leadTimeStart(data gateway.PRLeadTimeData, handleReopens bool) time.Time {
+	if handleReopens && data.LastReopenedAt.After(data.CreatedAt) && data.LastReopenedAt.Before(data.LastReviewedAt) {
+		return data.LastReopenedAt
+	}
+	return data.CreatedAt
+}
+
+// repoNames returns the sorted names of the repositories in statsMap.
+func repoNames(statsMap map[string]*domain.RepoStats) []string {
+	repos := make([]string, 0, len(statsMap))
+	for repoName := range statsMap {
+		repos = append(repos, repoName)
+	}
+	sort.Strings(repos)
+	return repos
+}
+
+// sortedLeadTimeData returns a copy of the data ordered by creation time and then PR number,
+// so the per-PR output does not depend on the order the API returned the pull requests in.
+func sortedLeadTimeData(data []gateway.PRLeadTimeData) []gateway.PRLeadTimeData {
+	sorted := append([]gateway.PRLeadTimeData(nil), data...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.Before(sorted[j].CreatedAt)
+		}
+		return sorted[i].Number < sorted[j].Number
+	})
+	return sorted
+}
+
+// fetchShareTotals fetches the all-author totals for the repositories in statsMap.
+// It has to run after the user's data is merged, since only the touched repositories are queried.
+func (a *Aggregator) fetchShareTotals(ctx context.Context, statsMap map[string]*domain.RepoStats, opts Options) error {
+	repos := repoNames(statsMap)
+
+	var commitTotals, prTotals map[string]int
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.Go(func() error {
+		return a.limited(egCtx, func() error {
+			var err error
+			commitTotals, err = a.fetcher.FetchRepoCommitTotals(egCtx, repos, opts.CommitDateRange)
+			return err
+		})
+	})
+	eg.Go(func() error {
+		return a.limited(egCtx, func() error {
+			var err error
+			prTotals, err = a.fetcher.FetchRepoPRTotals(egCtx, repos, opts.PRDateRange, opts.ExcludeDrafts)
+			return err
+		})
+	})
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	for repoName, repoStat := range statsMap {
+		repoStat.OrgCommits = commitTotals[repoName]
+		repoStat.OrgCreatedPRs = prTotals[repoName]
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/naka-gawa/github-stats/internal/gateway"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // mockFetcher is a mock implementation of the gateway.Fetcher interface.
@@ -54,6 +55,17 @@ func (m *mockFetcher) FetchReviewedPRs(ctx context.Context, org, user, dateRange
 	return args.Get(0).(map[string]int), args.Error(1)
 }
 
+// FetchCommentedPRs is the mock's implementation for commented PRs.
+func (m *mockFetcher) FetchCommentedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
 // FetchPRLeadTimes is the mock's implementation for fetching lead time data.
 func (m *mockFetcher) FetchPRLeadTimes(ctx context.Context, org, user, dateRange string) (map[string][]gateway.PRLeadTimeData, error) {
 	m.mu.Lock()
@@ -65,6 +77,250 @@ func (m *mockFetcher) FetchPRLeadTimes(ctx context.Context, org, user, dateRange
 	return args.Get(0).(map[string][]gateway.PRLeadTimeData), args.Error(1)
 }
 
+// FetchMergedPRCommits is the mock's implementation for commits on merged PRs.
+func (m *mockFetcher) FetchMergedPRCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchCreatedIssues is the mock's implementation for created issues.
+func (m *mockFetcher) FetchCreatedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchClosedIssues is the mock's implementation for closed issues.
+func (m *mockFetcher) FetchClosedIssues(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchReviewComments is the mock's implementation for review comments.
+func (m *mockFetcher) FetchReviewComments(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchReviewerLatencies is the mock's implementation for reviewer latencies.
+func (m *mockFetcher) FetchReviewerLatencies(ctx context.Context, org, user, dateRange string) (map[string][]gateway.ReviewerLatencyData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]gateway.ReviewerLatencyData), args.Error(1)
+}
+
+// FetchAuthorWaits is the mock's implementation for author waits.
+func (m *mockFetcher) FetchAuthorWaits(ctx context.Context, org, user, dateRange string) (map[string][]gateway.AuthorWaitData, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]gateway.AuthorWaitData), args.Error(1)
+}
+
+// FetchCommitChurn is the mock's implementation for commit churn.
+func (m *mockFetcher) FetchCommitChurn(ctx context.Context, repos []string, user, dateRange string) (map[string]gateway.CommitChurn, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, repos, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]gateway.CommitChurn), args.Error(1)
+}
+
+// FetchCommitTimes is the mock's implementation for commit times.
+func (m *mockFetcher) FetchCommitTimes(ctx context.Context, org, user, dateRange string) (map[string][]time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]time.Time), args.Error(1)
+}
+
+// FetchPRSizes is the mock's implementation for PR sizes.
+func (m *mockFetcher) FetchPRSizes(ctx context.Context, org, user, dateRange string) (map[string][]gateway.PRSize, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string][]gateway.PRSize), args.Error(1)
+}
+
+// FetchTeamMembers is the mock's implementation for team members.
+func (m *mockFetcher) FetchTeamMembers(ctx context.Context, org, teamSlug string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, teamSlug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+// FetchMergedPRs is the mock's implementation for merged PRs.
+func (m *mockFetcher) FetchMergedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchDraftPRs is the mock's implementation for draft PRs.
+func (m *mockFetcher) FetchDraftPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchAssignedPRs is the mock's implementation for assigned PRs.
+func (m *mockFetcher) FetchAssignedPRs(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchOpenPRs is the mock's implementation for open PRs.
+func (m *mockFetcher) FetchOpenPRs(ctx context.Context, org, user string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchApprovals is the mock's implementation for approvals.
+func (m *mockFetcher) FetchApprovals(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org, user, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchRepoLanguages is the mock's implementation for repository languages.
+func (m *mockFetcher) FetchRepoLanguages(ctx context.Context, repos []string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, repos)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
+// FetchRateLimit is the mock's implementation for the rate limit status.
+func (m *mockFetcher) FetchRateLimit(ctx context.Context) ([]gateway.RateLimit, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]gateway.RateLimit), args.Error(1)
+}
+
+// FetchRepoCommitTotals is the mock's implementation for all-author commit totals.
+func (m *mockFetcher) FetchRepoCommitTotals(ctx context.Context, repos []string, dateRange string) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, repos, dateRange)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// FetchRepoPRTotals is the mock's implementation for all-author pull request totals.
+func (m *mockFetcher) FetchRepoPRTotals(ctx context.Context, repos []string, dateRange string, excludeDrafts bool) (map[string]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, repos, dateRange, excludeDrafts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]int), args.Error(1)
+}
+
+// CheckOrgAccess is the mock's implementation for the organization access check.
+func (m *mockFetcher) CheckOrgAccess(ctx context.Context, org string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org)
+	return args.Bool(0), args.Error(1)
+}
+
+// OrgExists is the mock's implementation for the organization lookup.
+func (m *mockFetcher) OrgExists(ctx context.Context, org string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, org)
+	return args.Bool(0), args.Error(1)
+}
+
+// UserExists is the mock's implementation for the user lookup.
+func (m *mockFetcher) UserExists(ctx context.Context, user string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, user)
+	return args.Bool(0), args.Error(1)
+}
+
+// FetchRepoNodeIDs is the mock's implementation for repository node ID lookups.
+func (m *mockFetcher) FetchRepoNodeIDs(ctx context.Context, repos []string) (map[string]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	args := m.Called(ctx, repos)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]string), args.Error(1)
+}
+
 func TestAggregator_Aggregate(t *testing.T) {
 	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
 	// Define the structure for our test cases
@@ -101,8 +357,12 @@ func TestAggregator_Aggregate(t *testing.T) {
 			mockLeadTimeData: map[string][]gateway.PRLeadTimeData{
 				"repo-a": {
 					{
-						CreatedAt:      baseTime.Add(-2 * time.Hour), // 2 hours ago
-						LastReviewedAt: baseTime.Add(-1 * time.Hour), // 1 hour ago
+						Number:          7,
+						CreatedAt:       baseTime.Add(-2 * time.Hour),    // 2 hours ago
+						FirstReviewedAt: baseTime.Add(-90 * time.Minute), // 1.5 hours ago
+						LastReviewedAt:  baseTime.Add(-1 * time.Hour),    // 1 hour ago
+						Additions:       10,
+						Deletions:       4,
 					},
 				},
 			},
@@ -110,6 +370,10 @@ func TestAggregator_Aggregate(t *testing.T) {
 				{
 					Name: "repo-a", Commits: 1, CreatedPRs: 1, ReviewedPRs: 0,
 					LeadTimeToLastReviewSeconds: []float64{3600},
+					TimeToFirstReviewSeconds:    []float64{1800},
+					PullRequests: []domain.PRLeadTime{
+						{Number: 7, CreatedAt: baseTime.Add(-2 * time.Hour), LeadTimeSeconds: 3600, Additions: 10, Deletions: 4},
+					},
 				},
 			},
 			expectError: false,
@@ -131,9 +395,10 @@ func TestAggregator_Aggregate(t *testing.T) {
 
 			// Set up mock expectations based on the test case data
 			if tc.mockErr != nil {
-				fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.mockErr)
-				fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.mockErr)
-				fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.mockErr)
+				// The first failure cancels the fetches still waiting for a concurrency slot, so not every call happens.
+				fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.mockErr).Maybe()
+				fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.mockErr).Maybe()
+				fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, tc.mockErr).Maybe()
 			} else {
 				fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tc.mockCommits, nil)
 				fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(tc.mockCreatedPRs, nil)
@@ -146,7 +411,11 @@ func TestAggregator_Aggregate(t *testing.T) {
 			}
 
 			aggregator := NewAggregator(fetcher, logger)
-			results, err := aggregator.Aggregate(ctx, "any-org", "any-user", "any-commit-range", "any-pr-range", tc.calculateLeadTime)
+			results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{
+				CommitDateRange:   "any-commit-range",
+				PRDateRange:       "any-pr-range",
+				CalculateLeadTime: tc.calculateLeadTime,
+			})
 
 			if tc.expectError {
 				assert.Error(t, err)
@@ -162,3 +431,822 @@ func TestAggregator_Aggregate(t *testing.T) {
 		})
 	}
 }
+
+func TestAggregator_Aggregate_Share(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+
+	fetcher.On("FetchCommits", mock.Anything, "any-org", "any-user", " commit-range").Return(map[string]int{"repo-a": 3}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "any-org", "any-user", " pr-range").Return(map[string]int{"repo-b": 2}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "any-org", "any-user", " pr-range").Return(map[string]int{}, nil)
+	// Only the repositories the user touched are queried, in sorted order.
+	fetcher.On("FetchRepoCommitTotals", mock.Anything, []string{"repo-a", "repo-b"}, " commit-range").Return(map[string]int{"repo-a": 30, "repo-b": 7}, nil)
+	fetcher.On("FetchRepoPRTotals", mock.Anything, []string{"repo-a", "repo-b"}, " pr-range", false).Return(map[string]int{"repo-b": 8}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{
+		CommitDateRange: " commit-range",
+		PRDateRange:     " pr-range",
+		Share:           true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "repo-a", Commits: 3, OrgCommits: 30},
+		{Name: "repo-b", CreatedPRs: 2, OrgCommits: 7, OrgCreatedPRs: 8},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_SkipPhases(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+
+	// Only lead time is requested; any call to a skipped phase would fail the mock.
+	fetcher.On("FetchPRLeadTimes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string][]gateway.PRLeadTimeData{}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{
+		SkipCommits:       true,
+		SkipCreatedPRs:    true,
+		SkipReviewedPRs:   true,
+		CalculateLeadTime: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+	fetcher.AssertExpectations(t)
+	fetcher.AssertNotCalled(t, "FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	fetcher.AssertNotCalled(t, "FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	fetcher.AssertNotCalled(t, "FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAggregator_Aggregate_SortsPullRequests(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+
+	// Two PRs share a creation time, so the PR number decides their order.
+	fetcher.On("FetchPRLeadTimes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string][]gateway.PRLeadTimeData{
+		"repo-a": {
+			{Number: 3, CreatedAt: baseTime.Add(time.Hour), LastReviewedAt: baseTime.Add(2 * time.Hour)},
+			{Number: 2, CreatedAt: baseTime, LastReviewedAt: baseTime.Add(time.Hour)},
+			{Number: 1, CreatedAt: baseTime, LastReviewedAt: baseTime.Add(3 * time.Hour)},
+		},
+	}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{
+		SkipCommits:       true,
+		SkipCreatedPRs:    true,
+		SkipReviewedPRs:   true,
+		CalculateLeadTime: true,
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		var numbers []int
+		for _, pr := range results[0].PullRequests {
+			numbers = append(numbers, pr.Number)
+		}
+		assert.Equal(t, []int{1, 2, 3}, numbers)
+		assert.Equal(t, []float64{10800, 3600, 3600}, results[0].LeadTimeToLastReviewSeconds)
+	}
+}
+
+func TestAggregator_Aggregate_SortsReposByName(t *testing.T) {
+	fetcher := new(mockFetcher)
+	// Equal counts must not leave the order to the map iteration, so reports diff cleanly between runs.
+	fetcher.On("FetchCommits", mock.Anything, "org", "any-user", mock.Anything).Return(map[string]int{
+		"org/repo-c": 3, "org/repo-a": 3, "org/repo-b": 3, "org/repo-d": 1,
+	}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	for range 5 {
+		results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{SkipCreatedPRs: true, SkipReviewedPRs: true})
+		require.NoError(t, err)
+		var names []string
+		for _, repoStat := range results {
+			names = append(names, repoStat.Name)
+		}
+		assert.Equal(t, []string{"org/repo-a", "org/repo-b", "org/repo-c", "org/repo-d"}, names)
+	}
+}
+
+func TestAggregator_Aggregate_MergedCommitsOnly(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+
+	fetcher.On("FetchMergedPRCommits", mock.Anything, "any-org", "any-user", " pr-range").Return(map[string]int{"repo-a": 4}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{"repo-a": 1}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{
+		CommitDateRange:   " commit-range",
+		PRDateRange:       " pr-range",
+		MergedCommitsOnly: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{{Name: "repo-a", Commits: 4, CreatedPRs: 1}}, results)
+	fetcher.AssertExpectations(t)
+	fetcher.AssertNotCalled(t, "FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestAggregator_Aggregate_WithNodeIDs(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{"org/repo-b": 1, "org/repo-a": 2}, nil)
+	fetcher.On("FetchRepoNodeIDs", mock.Anything, []string{"org/repo-a", "org/repo-b"}).Return(map[string]string{"org/repo-a": "R_a", "org/repo-b": "R_b"}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+		WithNodeIDs:     true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", NodeID: "R_a", Commits: 2},
+		{Name: "org/repo-b", NodeID: "R_b", Commits: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_Languages(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{"org/repo-b": 1, "org/repo-a": 2}, nil)
+	// repo-b has no detected language, so it is missing from the answer.
+	fetcher.On("FetchRepoLanguages", mock.Anything, []string{"org/repo-a", "org/repo-b"}).Return(map[string]string{"org/repo-a": "Go"}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+		Languages:       true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", PrimaryLanguage: "Go", Commits: 2},
+		{Name: "org/repo-b", Commits: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_CommentStats(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{"repo-a": 1}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	fetcher.On("FetchCommentedPRs", mock.Anything, "any-org", "any-user", " pr-range").Return(map[string]int{"repo-a": 2, "repo-b": 5}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "any-org", "any-user", Options{PRDateRange: " pr-range", CommentStats: true})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "repo-a", Commits: 1, CommentedPRs: 2},
+		{Name: "repo-b", CommentedPRs: 5},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_HandleReopens(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	leadTimeData := map[string][]gateway.PRLeadTimeData{
+		"repo-a": {
+			// Closed for a week, reopened, then reviewed an hour later.
+			{Number: 1, CreatedAt: baseTime, LastReopenedAt: baseTime.Add(7 * 24 * time.Hour), LastReviewedAt: baseTime.Add(7*24*time.Hour + time.Hour)},
+			// Reviewed before being reopened, so the reopen does not shorten the interval.
+			{Number: 2, CreatedAt: baseTime, LastReviewedAt: baseTime.Add(2 * time.Hour), LastReopenedAt: baseTime.Add(48 * time.Hour)},
+			// Never reopened.
+			{Number: 3, CreatedAt: baseTime, LastReviewedAt: baseTime.Add(3 * time.Hour)},
+		},
+	}
+	testCases := []struct {
+		name          string
+		handleReopens bool
+		expected      []float64
+	}{
+		{name: "without reopen handling", handleReopens: false, expected: []float64{(7*24 + 1) * 3600, 2 * 3600, 3 * 3600}},
+		{name: "with reopen handling", handleReopens: true, expected: []float64{3600, 2 * 3600, 3 * 3600}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := new(mockFetcher)
+			fetcher.On("FetchPRLeadTimes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(leadTimeData, nil)
+
+			aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+			results, err := aggregator.Aggregate(context.Background(), "any-org", "any-user", Options{
+				SkipCommits:       true,
+				SkipCreatedPRs:    true,
+				SkipReviewedPRs:   true,
+				CalculateLeadTime: true,
+				HandleReopens:     tc.handleReopens,
+			})
+
+			assert.NoError(t, err)
+			if assert.Len(t, results, 1) {
+				assert.Equal(t, tc.expected, results[0].LeadTimeToLastReviewSeconds)
+			}
+		})
+	}
+}
+
+func TestAggregator_Aggregate_MergeTime(t *testing.T) {
+	baseTime := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchPRLeadTimes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string][]gateway.PRLeadTimeData{
+		"repo-a": {
+			{Number: 1, CreatedAt: baseTime, LastReviewedAt: baseTime.Add(time.Hour), MergedAt: baseTime.Add(5 * time.Hour)},
+			// Closed without merge: review lead time only.
+			{Number: 2, CreatedAt: baseTime, LastReviewedAt: baseTime.Add(2 * time.Hour)},
+			// Merged without review: merge time only.
+			{Number: 3, CreatedAt: baseTime, MergedAt: baseTime.Add(30 * time.Minute)},
+		},
+	}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "any-org", "any-user", Options{
+		SkipCommits:       true,
+		SkipCreatedPRs:    true,
+		SkipReviewedPRs:   true,
+		CalculateLeadTime: true,
+	})
+
+	assert.NoError(t, err)
+	if assert.Len(t, results, 1) {
+		assert.Equal(t, []float64{5 * 3600, 1800}, results[0].MergeTimeSeconds)
+		assert.Equal(t, []float64{3600, 2 * 3600}, results[0].LeadTimeToLastReviewSeconds)
+	}
+}
+
+func TestAggregator_AggregateUsers(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommits", mock.Anything, "any-org", "alice", mock.Anything).Return(map[string]int{"repo-b": 2, "repo-a": 1}, nil)
+	fetcher.On("FetchCommits", mock.Anything, "any-org", "bob", mock.Anything).Return(map[string]int{"repo-a": 7}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "any-org", "alice", mock.Anything).Return(map[string]int{}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "any-org", "bob", mock.Anything).Return(map[string]int{"repo-c": 3}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "any-org", mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.AggregateUsers(context.Background(), "any-org", []string{"bob", "alice"}, Options{})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "repo-a", User: "bob", Commits: 7},
+		{Name: "repo-c", User: "bob", CreatedPRs: 3},
+		{Name: "repo-a", User: "alice", Commits: 1},
+		{Name: "repo-b", User: "alice", Commits: 2},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_AggregateUsers_Error(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, "alice", mock.Anything).Return(map[string]int{}, nil)
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, "bob", mock.Anything).Return(nil, errors.New("github api error"))
+	fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.AggregateUsers(context.Background(), "any-org", []string{"alice", "bob"}, Options{})
+
+	assert.ErrorContains(t, err, "user bob")
+	assert.Nil(t, results)
+}
+
+func TestAggregator_Aggregate_RepoFilter(t *testing.T) {
+	commits := map[string]int{"org/api": 1, "org/web": 2, "org/legacy-api": 3, "other/tool": 4}
+	testCases := []struct {
+		name     string
+		include  []string
+		exclude  []string
+		expected []string
+	}{
+		{name: "no filter", expected: []string{"org/api", "org/legacy-api", "org/web", "other/tool"}},
+		{name: "include org glob", include: []string{"org/*"}, expected: []string{"org/api", "org/legacy-api", "org/web"}},
+		{name: "include exact names", include: []string{"org/web", "Other/Tool"}, expected: []string{"org/web", "other/tool"}},
+		{name: "exclude glob", exclude: []string{"*/legacy-*"}, expected: []string{"org/api", "org/web", "other/tool"}},
+		{name: "exclude wins over include", include: []string{"org/*"}, exclude: []string{"org/web"}, expected: []string{"org/api", "org/legacy-api"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := new(mockFetcher)
+			fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(commits, nil)
+			fetcher.On("FetchPRLeadTimes", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string][]gateway.PRLeadTimeData{
+				"org/web": {{Number: 1, CreatedAt: time.Unix(0, 0), FirstReviewedAt: time.Unix(60, 0), LastReviewedAt: time.Unix(60, 0)}},
+			}, nil)
+
+			aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+			results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+				SkipCreatedPRs:    true,
+				SkipReviewedPRs:   true,
+				CalculateLeadTime: true,
+				IncludeRepos:      tc.include,
+				ExcludeRepos:      tc.exclude,
+			})
+
+			assert.NoError(t, err)
+			var names []string
+			for _, result := range results {
+				names = append(names, result.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestAggregator_Aggregate_RepoFilterBeforeShare(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{"org/api": 1, "org/archived": 5}, nil)
+	// Excluded repositories are not queried for totals.
+	fetcher.On("FetchRepoCommitTotals", mock.Anything, []string{"org/api"}, mock.Anything).Return(map[string]int{"org/api": 10}, nil)
+	fetcher.On("FetchRepoPRTotals", mock.Anything, []string{"org/api"}, mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+		Share:           true,
+		ExcludeRepos:    []string{"org/archived"},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{{Name: "org/api", Commits: 1, OrgCommits: 10}}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_MergedPRs(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 3, "org/repo-b": 1}, nil)
+	fetcher.On("FetchMergedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 2}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:     " pr-range",
+		SkipCommits:     true,
+		SkipReviewedPRs: true,
+		MergedPRs:       true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", CreatedPRs: 3, MergedPRs: 2},
+		{Name: "org/repo-b", CreatedPRs: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_Drafts(t *testing.T) {
+	testCases := []struct {
+		name          string
+		excludeDrafts bool
+		expected      []*domain.RepoStats
+	}{
+		{
+			name:     "drafts reported",
+			expected: []*domain.RepoStats{{Name: "org/repo-a", CreatedPRs: 3, DraftPRs: 2}, {Name: "org/repo-b", CreatedPRs: 1}},
+		},
+		{
+			name:          "drafts excluded",
+			excludeDrafts: true,
+			expected:      []*domain.RepoStats{{Name: "org/repo-a", CreatedPRs: 1, DraftPRs: 2}, {Name: "org/repo-b", CreatedPRs: 1}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fetcher := new(mockFetcher)
+			fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 3, "org/repo-b": 1}, nil)
+			fetcher.On("FetchDraftPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 2}, nil)
+
+			aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+			results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+				PRDateRange:     " pr-range",
+				SkipCommits:     true,
+				SkipReviewedPRs: true,
+				Drafts:          !tc.excludeDrafts,
+				ExcludeDrafts:   tc.excludeDrafts,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, results)
+			fetcher.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAggregator_Aggregate_Approvals(t *testing.T) {
+	ctx := context.Background()
+	logger := log.New(io.Discard, "", 0)
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{"org/repo-a": 3, "org/repo-b": 1}, nil)
+	fetcher.On("FetchApprovals", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 2}, nil)
+
+	aggregator := NewAggregator(fetcher, logger)
+	results, err := aggregator.Aggregate(ctx, "org", "any-user", Options{
+		PRDateRange:    " pr-range",
+		SkipCommits:    true,
+		SkipCreatedPRs: true,
+		Approvals:      true,
+	})
+
+	assert.NoError(t, err)
+	// The reviewed PR count still includes the reviews that were not approvals.
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", ReviewedPRs: 3, ApprovalsGiven: 2},
+		{Name: "org/repo-b", ReviewedPRs: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_ReviewerLatency(t *testing.T) {
+	ready := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchReviewerLatencies", mock.Anything, "org", "any-user", " pr-range").Return(map[string][]gateway.ReviewerLatencyData{
+		"org/repo-a": {
+			{Number: 1, ReadyAt: ready, FirstReviewAt: ready.Add(2 * time.Hour)},
+			// Reviewed while still a draft.
+			{Number: 2, ReadyAt: ready, FirstReviewAt: ready.Add(-time.Hour)},
+		},
+		"org/repo-b": {{Number: 3, ReadyAt: ready, FirstReviewAt: ready.Add(30 * time.Minute)}},
+	}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:     " pr-range",
+		SkipCommits:     true,
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+		ReviewerLatency: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", ReviewerLatencySeconds: []float64{7200, 0}},
+		{Name: "org/repo-b", ReviewerLatencySeconds: []float64{1800}},
+	}, results)
+	assert.Equal(t, []float64{7200, 0, 1800}, Totals(results).ReviewerLatencySeconds)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_AuthorWait(t *testing.T) {
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchAuthorWaits", mock.Anything, "org", "any-user", " pr-range").Return(map[string][]gateway.AuthorWaitData{
+		"org/repo-a": {
+			{Number: 1, CreatedAt: created, FirstReviewAt: created.Add(3 * time.Hour)},
+			{Number: 2, CreatedAt: created, FirstReviewAt: created.Add(24 * time.Hour)},
+		},
+		"org/repo-b": {{Number: 3, CreatedAt: created, FirstReviewAt: created.Add(-time.Minute)}},
+	}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:     " pr-range",
+		SkipCommits:     true,
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+		AuthorWait:      true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", AuthorWaitSeconds: []float64{10800, 86400}},
+		// A review timestamped before the creation counts as no wait.
+		{Name: "org/repo-b", AuthorWaitSeconds: []float64{0}},
+	}, results)
+	assert.Equal(t, []float64{10800, 86400, 0}, Totals(results).AuthorWaitSeconds)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_CommitChurn(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommits", mock.Anything, "org", "any-user", " commit-range").Return(map[string]int{"org/repo-a": 4}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-b": 1}, nil)
+	// Only the repository with commits is looked up.
+	fetcher.On("FetchCommitChurn", mock.Anything, []string{"org/repo-a"}, "any-user", " commit-range").Return(map[string]gateway.CommitChurn{
+		"org/repo-a": {Additions: 120, Deletions: 30},
+	}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		CommitDateRange: " commit-range",
+		PRDateRange:     " pr-range",
+		SkipCreatedPRs:  true,
+		CommitChurn:     true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", Commits: 4, CommitAdditions: 120, CommitDeletions: 30},
+		{Name: "org/repo-b", ReviewedPRs: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_Churn(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchPRSizes", mock.Anything, "org", "any-user", " pr-range").Return(map[string][]gateway.PRSize{
+		"org/repo-a": {
+			{Number: 1, Additions: 100, Deletions: 20},
+			{Number: 2, Additions: 5, Deletions: 5},
+			{Number: 3, Additions: 40, Deletions: 0},
+			{Number: 4, Additions: 0, Deletions: 60},
+		},
+		"org/repo-b": {{Number: 9, Additions: 7, Deletions: 1}},
+	}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:     " pr-range",
+		SkipCommits:     true,
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+		Churn:           true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		// Sizes 120, 10, 40 and 60: the median is the mean of the middle two.
+		{Name: "org/repo-a", TotalAdditions: 145, TotalDeletions: 85, MedianPRSizeLines: 50, PRSizeLines: []float64{120, 10, 40, 60}},
+		{Name: "org/repo-b", TotalAdditions: 7, TotalDeletions: 1, MedianPRSizeLines: 8, PRSizeLines: []float64{8}},
+	}, results)
+
+	total := Totals(results)
+	assert.Equal(t, 152, total.TotalAdditions)
+	assert.Equal(t, 86, total.TotalDeletions)
+	assert.Equal(t, 40.0, total.MedianPRSizeLines)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_ReviewComments(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 4, "org/repo-b": 1}, nil)
+	fetcher.On("FetchReviewComments", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 9, "org/repo-b": 0}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:    " pr-range",
+		SkipCommits:    true,
+		SkipCreatedPRs: true,
+		ReviewComments: true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", ReviewedPRs: 4, ReviewComments: 9},
+		{Name: "org/repo-b", ReviewedPRs: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_Issues(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCreatedIssues", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 2}, nil)
+	fetcher.On("FetchClosedIssues", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 1, "org/repo-b": 3}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:     " pr-range",
+		SkipCommits:     true,
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+		Issues:          true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", CreatedIssues: 2, ClosedIssues: 1},
+		{Name: "org/repo-b", ClosedIssues: 3},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_Assigned(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 1}, nil)
+	fetcher.On("FetchAssignedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 2, "org/repo-b": 1}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:    " pr-range",
+		SkipCommits:    true,
+		SkipCreatedPRs: true,
+		Assigned:       true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", ReviewedPRs: 1, AssignedPRs: 2},
+		{Name: "org/repo-b", AssignedPRs: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_OpenPRs(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", " pr-range").Return(map[string]int{"org/repo-a": 3}, nil)
+	// Open PRs are a snapshot, so they are fetched without the date range.
+	fetcher.On("FetchOpenPRs", mock.Anything, "org", "any-user").Return(map[string]int{"org/repo-a": 1, "org/repo-b": 2}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		PRDateRange:     " pr-range",
+		SkipCommits:     true,
+		SkipReviewedPRs: true,
+		OpenPRs:         true,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", CreatedPRs: 3, OpenPRs: 1},
+		{Name: "org/repo-b", OpenPRs: 2},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_Heatmap(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommitTimes", mock.Anything, "org", "any-user", " commit-range").Return(map[string][]time.Time{
+		"org/repo-a": {
+			time.Date(2025, 1, 6, 9, 30, 0, 0, time.UTC),  // Monday 09:30 UTC, 18:30 in Tokyo
+			time.Date(2025, 1, 6, 9, 59, 0, 0, time.UTC),  // Monday 09:59 UTC, 18:59 in Tokyo
+			time.Date(2025, 1, 4, 20, 15, 0, 0, time.UTC), // Saturday 20:15 UTC, Sunday 05:15 in Tokyo
+		},
+	}, nil)
+
+	testCases := []struct {
+		name     string
+		location *time.Location
+		expected func(h *domain.Heatmap)
+	}{
+		{
+			name: "UTC by default",
+			expected: func(h *domain.Heatmap) {
+				h[time.Monday][9] = 2
+				h[time.Saturday][20] = 1
+			},
+		},
+		{
+			name:     "in the given time zone",
+			location: tokyo,
+			expected: func(h *domain.Heatmap) {
+				h[time.Monday][18] = 2
+				h[time.Sunday][5] = 1
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+			results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+				CommitDateRange: " commit-range",
+				SkipCommits:     true,
+				SkipCreatedPRs:  true,
+				SkipReviewedPRs: true,
+				Heatmap:         true,
+				HeatmapLocation: tc.location,
+			})
+
+			require.NoError(t, err)
+			require.Len(t, results, 1)
+			expected := &domain.Heatmap{}
+			tc.expected(expected)
+			assert.Equal(t, expected, results[0].CommitHeatmap)
+		})
+	}
+}
+
+func TestAggregator_Aggregate_BestEffort(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommits", mock.Anything, "org", "any-user", mock.Anything).Return(map[string]int{"org/repo-a": 4}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", mock.Anything).Return(map[string]int{"org/repo-a": 1}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "org", "any-user", mock.Anything).Return(nil, errors.New("github api error"))
+
+	var failed []string
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		BestEffort: true,
+		OnFetchError: func(user, fetch string, err error) {
+			failed = append(failed, user+": "+fetch+": "+err.Error())
+		},
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "org/repo-a", Commits: 4, CreatedPRs: 1},
+	}, results)
+	assert.Equal(t, []string{"any-user: reviewed PRs: github api error"}, failed)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_Aggregate_BestEffortAllFailed(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("commits down"))
+	fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("search down"))
+	fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("search down"))
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{BestEffort: true})
+
+	assert.ErrorContains(t, err, "every fetch failed")
+	assert.ErrorContains(t, err, "commits: commits down")
+	assert.Nil(t, results)
+}
+
+func TestAggregator_Aggregate_WithoutBestEffortFails(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchCommits", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{"org/repo-a": 4}, nil).Maybe()
+	fetcher.On("FetchCreatedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(map[string]int{}, nil).Maybe()
+	fetcher.On("FetchReviewedPRs", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, errors.New("github api error"))
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{})
+
+	assert.ErrorContains(t, err, "github api error")
+	assert.Nil(t, results)
+}
+
+// countingFetcher records the highest number of calls in flight at once.
+// Only the counting fetches are implemented; anything else panics on the nil embedded Fetcher.
+type countingFetcher struct {
+	gateway.Fetcher
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *countingFetcher) count() (map[string]int, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	c.mu.Lock()
+	c.inFlight--
+	c.mu.Unlock()
+	return map[string]int{"org/repo": 1}, nil
+}
+
+func (c *countingFetcher) FetchCommits(context.Context, string, string, string) (map[string]int, error) {
+	return c.count()
+}
+
+func (c *countingFetcher) FetchCreatedPRs(context.Context, string, string, string) (map[string]int, error) {
+	return c.count()
+}
+
+func (c *countingFetcher) FetchReviewedPRs(context.Context, string, string, string) (map[string]int, error) {
+	return c.count()
+}
+
+func (c *countingFetcher) FetchCommentedPRs(context.Context, string, string, string) (map[string]int, error) {
+	return c.count()
+}
+
+func (c *countingFetcher) FetchCreatedIssues(context.Context, string, string, string) (map[string]int, error) {
+	return c.count()
+}
+
+func (c *countingFetcher) FetchClosedIssues(context.Context, string, string, string) (map[string]int, error) {
+	return c.count()
+}
+
+func (c *countingFetcher) FetchReviewComments(context.Context, string, string, string) (map[string]int, error) {
+	return c.count()
+}
+
+func TestAggregator_WithConcurrency(t *testing.T) {
+	for _, limit := range []int{1, 2, 3} {
+		fetcher := &countingFetcher{}
+		aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0), WithConcurrency(limit))
+		_, err := aggregator.AggregateUsers(context.Background(), "org", []string{"alice", "bob", "carol"}, Options{
+			CommentStats:   true,
+			Issues:         true,
+			ReviewComments: true,
+		})
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, fetcher.peak, limit, "limit %d", limit)
+		assert.Positive(t, fetcher.peak)
+	}
+}
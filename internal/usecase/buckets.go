@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"golang.org/x/sync/errgroup"
+)
+
+// Supported time series granularities.
+const (
+	GranularityWeekly  = "weekly"
+	GranularityMonthly = "monthly"
+)
+
+// maxConcurrentBucketFetches caps the in-flight counting queries of a time series.
+const maxConcurrentBucketFetches = 4
+
+// Bucket is one period of a time series. Start and End are whole days in their location, and End is included.
+type Bucket struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Key identifies the bucket in the output by its start date, e.g. "2025-01-06".
+func (b Bucket) Key() string {
+	return b.Start.Format(githubDateLayout)
+}
+
+// ValidateGranularity returns an error when granularity is not weekly or monthly.
+func ValidateGranularity(granularity string) error {
+	if granularity != GranularityWeekly && granularity != GranularityMonthly {
+		return fmt.Errorf("unsupported granularity %q (valid: %s, %s)", granularity, GranularityWeekly, GranularityMonthly)
+	}
+	return nil
+}
+
+// SplitBuckets splits the days from..to into calendar weeks (starting on Monday) or calendar months.
+// The first and last bucket are cut to the range, so they may be shorter than the others.
+func SplitBuckets(from, to time.Time, granularity string) ([]Bucket, error) {
+	if err := ValidateGranularity(granularity); err != nil {
+		return nil, err
+	}
+	if from.IsZero() || to.IsZero() {
+		return nil, errors.New("a time series needs both the start and the end of the range")
+	}
+	from, to = startOfDay(from), startOfDay(to)
+	if to.Before(from) {
+		return nil, errors.New("the end of the range must not be before its start")
+	}
+
+	var buckets []Bucket
+	for start := from; !start.After(to); {
+		next := nextBucketStart(start, granularity)
+		end := next.AddDate(0, 0, -1)
+		if end.After(to) {
+			end = to
+		}
+		buckets = append(buckets, Bucket{Start: start, End: end})
+		start = next
+	}
+	return buckets, nil
+}
+
+// nextBucketStart returns the first day of the week or month after the one day belongs to.
+func nextBucketStart(day time.Time, granularity string) time.Time {
+	if granularity == GranularityMonthly {
+		return time.Date(day.Year(), day.Month()+1, 1, 0, 0, 0, 0, day.Location())
+	}
+	// time.Sunday is 0, so shift the weekday to make Monday the first day of the week.
+	daysSinceMonday := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, 7-daysSinceMonday)
+}
+
+// fetchSeries runs the counting queries once per bucket and stores a dense series in every repository of statsMap.
+// Repositories without activity in a bucket get zero counts, so every series has the same keys.
+func (a *Aggregator) fetchSeries(ctx context.Context, org, user string, statsMap map[string]*domain.RepoStats, opts Options) error {
+	type bucketCounts struct {
+		commits, createdPRs, reviewedPRs map[string]int
+	}
+	counts := make([]bucketCounts, len(opts.Buckets))
+
+	eg, egCtx := errgroup.WithContext(ctx)
+	eg.SetLimit(maxConcurrentBucketFetches)
+	for i, bucket := range opts.Buckets {
+		commitDateRange, prDateRange := DateRangeQualifiers(bucket.Start, bucket.End)
+		if !opts.SkipCommits {
+			eg.Go(func() error {
+				return a.limited(egCtx, func() error {
+					var err error
+					if opts.MergedCommitsOnly {
+						counts[i].commits, err = a.fetcher.FetchMergedPRCommits(egCtx, org, user, prDateRange)
+					} else {
+						counts[i].commits, err = a.fetcher.FetchCommits(egCtx, org, user, commitDateRange)
+					}
+					return err
+				})
+			})
+		}
+		if !opts.SkipCreatedPRs {
+			eg.Go(func() error {
+				return a.limited(egCtx, func() error {
+					var err error
+					counts[i].createdPRs, err = a.fetcher.FetchCreatedPRs(egCtx, org, user, prDateRange)
+					return err
+				})
+			})
+		}
+		if !opts.SkipReviewedPRs {
+			eg.Go(func() error {
+				return a.limited(egCtx, func() error {
+					var err error
+					counts[i].reviewedPRs, err = a.fetcher.FetchReviewedPRs(egCtx, org, user, prDateRange)
+					return err
+				})
+			})
+		}
+	}
+	if err := eg.Wait(); err != nil {
+		return err
+	}
+
+	for repoName, repoStat := range statsMap {
+		repoStat.Series = make(map[string]domain.BucketStats, len(opts.Buckets))
+		for i, bucket := range opts.Buckets {
+			repoStat.Series[bucket.Key()] = domain.BucketStats{
+				Commits:     counts[i].commits[repoName],
+				CreatedPRs:  counts[i].createdPRs[repoName],
+				ReviewedPRs: counts[i].reviewedPRs[repoName],
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package usecase
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func TestSplitBuckets(t *testing.T) {
+	testCases := []struct {
+		name        string
+		from, to    time.Time
+		granularity string
+		want        []Bucket
+	}{
+		{
+			name:        "three full weeks",
+			from:        day(2025, time.January, 6), // Monday
+			to:          day(2025, time.January, 26),
+			granularity: GranularityWeekly,
+			want: []Bucket{
+				{Start: day(2025, time.January, 6), End: day(2025, time.January, 12)},
+				{Start: day(2025, time.January, 13), End: day(2025, time.January, 19)},
+				{Start: day(2025, time.January, 20), End: day(2025, time.January, 26)},
+			},
+		},
+		{
+			name:        "weeks cut to the range",
+			from:        day(2025, time.January, 8), // Wednesday
+			to:          day(2025, time.January, 14),
+			granularity: GranularityWeekly,
+			want: []Bucket{
+				{Start: day(2025, time.January, 8), End: day(2025, time.January, 12)},
+				{Start: day(2025, time.January, 13), End: day(2025, time.January, 14)},
+			},
+		},
+		{
+			name:        "months",
+			from:        day(2024, time.December, 15),
+			to:          day(2025, time.February, 10),
+			granularity: GranularityMonthly,
+			want: []Bucket{
+				{Start: day(2024, time.December, 15), End: day(2024, time.December, 31)},
+				{Start: day(2025, time.January, 1), End: day(2025, time.January, 31)},
+				{Start: day(2025, time.February, 1), End: day(2025, time.February, 10)},
+			},
+		},
+		{
+			name:        "single day",
+			from:        day(2025, time.March, 2),
+			to:          day(2025, time.March, 2),
+			granularity: GranularityWeekly,
+			want:        []Bucket{{Start: day(2025, time.March, 2), End: day(2025, time.March, 2)}},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			buckets, err := SplitBuckets(tc.from, tc.to, tc.granularity)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, buckets)
+		})
+	}
+}
+
+func TestSplitBuckets_Errors(t *testing.T) {
+	_, err := SplitBuckets(day(2025, time.January, 1), day(2025, time.January, 31), "daily")
+	assert.ErrorContains(t, err, "unsupported granularity")
+
+	_, err = SplitBuckets(time.Time{}, day(2025, time.January, 31), GranularityWeekly)
+	assert.ErrorContains(t, err, "needs both the start and the end")
+
+	_, err = SplitBuckets(day(2025, time.January, 31), day(2025, time.January, 1), GranularityMonthly)
+	assert.ErrorContains(t, err, "must not be before")
+}
+
+func TestAggregator_Aggregate_Series(t *testing.T) {
+	buckets, err := SplitBuckets(day(2025, time.January, 6), day(2025, time.January, 26), GranularityWeekly)
+	require.NoError(t, err)
+
+	fetcher := new(mockFetcher)
+	// The whole-range queries.
+	fetcher.On("FetchCommits", mock.Anything, "org", "any-user", " author-date:2025-01-06..2025-01-26").Return(map[string]int{"org/api": 6, "org/web": 1}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", " created:2025-01-06..2025-01-26").Return(map[string]int{"org/api": 2}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "org", "any-user", " created:2025-01-06..2025-01-26").Return(map[string]int{}, nil)
+	// One query per bucket and metric.
+	fetcher.On("FetchCommits", mock.Anything, "org", "any-user", " author-date:2025-01-06..2025-01-12").Return(map[string]int{"org/api": 1}, nil)
+	fetcher.On("FetchCommits", mock.Anything, "org", "any-user", " author-date:2025-01-13..2025-01-19").Return(map[string]int{"org/api": 2, "org/web": 1}, nil)
+	fetcher.On("FetchCommits", mock.Anything, "org", "any-user", " author-date:2025-01-20..2025-01-26").Return(map[string]int{"org/api": 3}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", " created:2025-01-06..2025-01-12").Return(map[string]int{}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", " created:2025-01-13..2025-01-19").Return(map[string]int{"org/api": 2}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "org", "any-user", " created:2025-01-20..2025-01-26").Return(map[string]int{}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "org", "any-user", mock.Anything).Return(map[string]int{}, nil)
+
+	commitDateRange, prDateRange := DateRangeQualifiers(day(2025, time.January, 6), day(2025, time.January, 26))
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	results, err := aggregator.Aggregate(context.Background(), "org", "any-user", Options{
+		CommitDateRange: commitDateRange,
+		PRDateRange:     prDateRange,
+		Buckets:         buckets,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{
+			Name: "org/api", Commits: 6, CreatedPRs: 2,
+			Series: map[string]domain.BucketStats{
+				"2025-01-06": {Commits: 1},
+				"2025-01-13": {Commits: 2, CreatedPRs: 2},
+				"2025-01-20": {Commits: 3},
+			},
+		},
+		{
+			Name: "org/web", Commits: 1,
+			Series: map[string]domain.BucketStats{
+				"2025-01-06": {},
+				"2025-01-13": {Commits: 1},
+				"2025-01-20": {},
+			},
+		},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
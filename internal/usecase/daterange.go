@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"fmt"
+	"time"
+)
+
+// githubDateLayout and githubDateTimeLayout are the formats understood by GitHub search qualifiers.
+// GitHub interprets a bare date as UTC, so other time zones need the full timestamp with its offset.
+const (
+	githubDateLayout     = "2006-01-02"
+	githubDateTimeLayout = "2006-01-02T15:04:05-07:00"
+)
+
+// DateRangeQualifiers builds the search qualifiers for the commit and pull request queries.
+// A zero from or to leaves that side of the range open; when both are zero no qualifier is added.
+// Both bounds are whole days in their own location: from starts at midnight and to ends just before the next midnight.
+func DateRangeQualifiers(from, to time.Time) (commitDateRange, prDateRange string) {
+	if from.IsZero() && to.IsZero() {
+		return "", ""
+	}
+	fromQuery, toQuery := "*", "*"
+	if !from.IsZero() {
+		fromQuery = formatDateBound(startOfDay(from))
+	}
+	if !to.IsZero() {
+		toQuery = formatDateBound(startOfDay(to).AddDate(0, 0, 1).Add(-time.Second))
+	}
+	commitDateRange = fmt.Sprintf(" author-date:%s..%s", fromQuery, toQuery)
+	prDateRange = fmt.Sprintf(" created:%s..%s", fromQuery, toQuery)
+	return commitDateRange, prDateRange
+}
+
+// formatDateBound renders t as a bare date when it is in UTC, and as a timestamp with its offset otherwise.
+func formatDateBound(t time.Time) string {
+	if t.Location() == time.UTC {
+		return t.Format(githubDateLayout)
+	}
+	return t.Format(githubDateTimeLayout)
+}
+
+// startOfDay returns midnight of t's day in t's location.
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
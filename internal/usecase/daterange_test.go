@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDateRangeQualifiers(t *testing.T) {
+	from := time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		name           string
+		from, to       time.Time
+		expectedCommit string
+		expectedPR     string
+	}{
+		{name: "no range", expectedCommit: "", expectedPR: ""},
+		{name: "closed range", from: from, to: to, expectedCommit: " author-date:2025-04-01..2025-06-30", expectedPR: " created:2025-04-01..2025-06-30"},
+		{name: "open end", from: from, expectedCommit: " author-date:2025-04-01..*", expectedPR: " created:2025-04-01..*"},
+		{name: "open start", to: to, expectedCommit: " author-date:*..2025-06-30", expectedPR: " created:*..2025-06-30"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			commitRange, prRange := DateRangeQualifiers(tc.from, tc.to)
+			assert.Equal(t, tc.expectedCommit, commitRange)
+			assert.Equal(t, tc.expectedPR, prRange)
+		})
+	}
+}
+
+func TestDateRangeQualifiers_TimeZone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+
+	utcCommit, utcPR := DateRangeQualifiers(time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 4, 30, 0, 0, 0, 0, time.UTC))
+	tokyoCommit, tokyoPR := DateRangeQualifiers(time.Date(2025, 4, 1, 0, 0, 0, 0, tokyo), time.Date(2025, 4, 30, 0, 0, 0, 0, tokyo))
+
+	assert.Equal(t, " author-date:2025-04-01..2025-04-30", utcCommit)
+	assert.Equal(t, " created:2025-04-01..2025-04-30", utcPR)
+	assert.Equal(t, " author-date:2025-04-01T00:00:00+09:00..2025-04-30T23:59:59+09:00", tokyoCommit)
+	assert.Equal(t, " created:2025-04-01T00:00:00+09:00..2025-04-30T23:59:59+09:00", tokyoPR)
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import "github.com/naka-gawa/github-stats/internal/gateway"
+
+// PlannedQuery is a search query Aggregate would run, labeled with the data it fetches.
+type PlannedQuery struct {
+	Name  string
+	Query string
+}
+
+// PlannedQueries lists the search queries Aggregate runs for org and user with opts, in the order of its fetches.
+// Queries that depend on the results, such as the --share totals per repository, are left out,
+// as are the per-bucket queries of a time series.
+func PlannedQueries(org, user string, opts Options) []PlannedQuery {
+	var queries []PlannedQuery
+	add := func(name, query string) {
+		queries = append(queries, PlannedQuery{Name: name, Query: query})
+	}
+	if !opts.SkipCommits {
+		if opts.MergedCommitsOnly {
+			add("commits", gateway.MergedPRCommitsQuery(org, user, opts.PRDateRange))
+		} else {
+			add("commits", gateway.CommitsQuery(org, user, opts.CommitDateRange))
+		}
+	}
+	if !opts.SkipCreatedPRs {
+		add("created PRs", gateway.CreatedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+		if opts.Drafts || opts.ExcludeDrafts {
+			add("draft PRs", gateway.CreatedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+		}
+	}
+	if opts.MergedPRs {
+		add("merged PRs", gateway.MergedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	if !opts.SkipReviewedPRs {
+		add("reviewed PRs", gateway.ReviewedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	if opts.CommentStats {
+		add("commented PRs", gateway.CommentedPRsQuery(org, user, opts.PRDateRange))
+	}
+	if opts.CalculateLeadTime {
+		add("lead times", gateway.PRLeadTimesQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	if opts.Issues {
+		add("created issues", gateway.CreatedIssuesQuery(org, user, opts.PRDateRange))
+		add("closed issues", gateway.ClosedIssuesQuery(org, user, opts.PRDateRange))
+	}
+	if opts.ReviewComments {
+		add("review comments", gateway.ReviewedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	if opts.Approvals {
+		add("approvals", gateway.ReviewedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	if opts.OpenPRs {
+		add("open PRs", gateway.OpenPRsQuery(org, user))
+	}
+	if opts.Heatmap {
+		add("commit times", gateway.CommitsQuery(org, user, opts.CommitDateRange))
+	}
+	if opts.Assigned {
+		add("assigned PRs", gateway.AssignedPRsQuery(org, user, opts.PRDateRange))
+	}
+	if opts.ReviewerLatency {
+		add("reviewer latencies", gateway.ReviewedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	if opts.AuthorWait {
+		add("author waits", gateway.CreatedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	if opts.Churn {
+		add("PR sizes", gateway.CreatedPRsQuery(org, user, opts.PRDateRange, opts.Labels))
+	}
+	return queries
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlannedQueries(t *testing.T) {
+	commitDateRange, prDateRange := DateRangeQualifiers(time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC))
+
+	queries := PlannedQueries("acme,widgets", "alice", Options{
+		CommitDateRange:   commitDateRange,
+		PRDateRange:       prDateRange,
+		CalculateLeadTime: true,
+		Issues:            true,
+	})
+
+	assert.Equal(t, []PlannedQuery{
+		{Name: "commits", Query: "org:acme org:widgets author:alice author-date:2025-01-01..2025-01-31"},
+		{Name: "created PRs", Query: "org:acme org:widgets author:alice is:pr created:2025-01-01..2025-01-31"},
+		{Name: "reviewed PRs", Query: "org:acme org:widgets reviewed-by:alice is:pr created:2025-01-01..2025-01-31"},
+		{Name: "lead times", Query: "org:acme org:widgets author:alice is:pr is:closed created:2025-01-01..2025-01-31"},
+		{Name: "created issues", Query: "org:acme org:widgets author:alice is:issue created:2025-01-01..2025-01-31"},
+		{Name: "closed issues", Query: "org:acme org:widgets assignee:alice is:issue is:closed created:2025-01-01..2025-01-31"},
+	}, queries)
+}
+
+func TestPlannedQueries_MergedCommitsOnlyAndSkips(t *testing.T) {
+	queries := PlannedQueries("acme", "alice", Options{
+		MergedCommitsOnly: true,
+		SkipCreatedPRs:    true,
+		SkipReviewedPRs:   true,
+	})
+
+	assert.Equal(t, []PlannedQuery{{Name: "commits", Query: "org:acme author:alice is:pr is:merged"}}, queries)
+}
+
+func TestPlannedQueries_Labels(t *testing.T) {
+	queries := PlannedQueries("acme", "alice", Options{
+		SkipCommits:       true,
+		MergedPRs:         true,
+		CalculateLeadTime: true,
+		Issues:            true,
+		Labels:            []string{"feature", "needs review"},
+	})
+
+	// The PR searches are filtered by label, the issue searches are not.
+	assert.Equal(t, []PlannedQuery{
+		{Name: "created PRs", Query: `org:acme author:alice is:pr label:"feature" label:"needs review"`},
+		{Name: "merged PRs", Query: `org:acme author:alice is:pr is:merged label:"feature" label:"needs review"`},
+		{Name: "reviewed PRs", Query: `org:acme reviewed-by:alice is:pr label:"feature" label:"needs review"`},
+		{Name: "lead times", Query: `org:acme author:alice is:pr is:closed label:"feature" label:"needs review"`},
+		{Name: "created issues", Query: "org:acme author:alice is:issue"},
+		{Name: "closed issues", Query: "org:acme assignee:alice is:issue is:closed"},
+	}, queries)
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+)
+
+// ValidateRepoPatterns returns an error when any of the glob patterns is malformed.
+func ValidateRepoPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid repository pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// repoIncluded reports whether the repository "owner/name" passes the include and exclude patterns.
+// Patterns use path.Match syntax, so "org/*" matches every repository of org, and are compared case-insensitively
+// like GitHub names. An empty include list includes everything, and an exclude match always wins.
+func repoIncluded(repoName string, include, exclude []string) bool {
+	if matchesAny(repoName, exclude) {
+		return false
+	}
+	return len(include) == 0 || matchesAny(repoName, include)
+}
+
+func matchesAny(repoName string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// Malformed patterns are rejected by ValidateRepoPatterns, so the error is ignored here.
+		if ok, _ := path.Match(strings.ToLower(pattern), strings.ToLower(repoName)); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// filterRepos removes the repositories that do not pass the include and exclude patterns from statsMap.
+func filterRepos(statsMap map[string]*domain.RepoStats, include, exclude []string) {
+	for repoName := range statsMap {
+		if !repoIncluded(repoName, include, exclude) {
+			delete(statsMap, repoName)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRepoPatterns(t *testing.T) {
+	assert.NoError(t, ValidateRepoPatterns([]string{"org/*", "org/repo-[ab]", "exact/name"}))
+	assert.ErrorContains(t, ValidateRepoPatterns([]string{"org/[a"}), `invalid repository pattern "org/[a"`)
+}
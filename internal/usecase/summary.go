@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"github.com/montanaflynn/stats"
+	"github.com/naka-gawa/github-stats/internal/domain"
+)
+
+// Summary holds the cross-repository aggregates of a report.
+type Summary struct {
+	// ReposTouched is the number of distinct repositories with a non-zero count of the user's activity.
+	ReposTouched int
+	Commits      int
+	CreatedPRs   int
+	ReviewedPRs  int
+	// AnalyzedPRs is the number of lead time samples the percentiles are computed from.
+	AnalyzedPRs int
+	// LeadTimeP50Seconds and LeadTimeP90Seconds are taken over the lead times of every analyzed PR,
+	// not averaged from the per-repository values. Both are 0 when no PR was analyzed.
+	LeadTimeP50Seconds float64
+	LeadTimeP90Seconds float64
+	// MostActiveRepo is the repository with the most commits, created PRs and reviewed PRs combined,
+	// the first by name on a tie, or empty when there was no activity.
+	MostActiveRepo string
+}
+
+// Summarize computes the Summary of results. Rows of the same repository for different users
+// count as one repository and are combined when picking the most active one.
+func Summarize(results []*domain.RepoStats) Summary {
+	var summary Summary
+	var leadTimes []float64
+	activity := make(map[string]int)
+	touched := make(map[string]bool)
+	for _, repoStat := range results {
+		if repoStat.HasActivity() {
+			touched[repoStat.Name] = true
+		}
+		summary.Commits += repoStat.Commits
+		summary.CreatedPRs += repoStat.CreatedPRs
+		summary.ReviewedPRs += repoStat.ReviewedPRs
+		leadTimes = append(leadTimes, repoStat.LeadTimeToLastReviewSeconds...)
+		activity[repoStat.Name] += repoStat.Commits + repoStat.CreatedPRs + repoStat.ReviewedPRs
+	}
+	summary.ReposTouched = len(touched)
+
+	best := 0
+	for name, count := range activity {
+		if count > best || (count == best && count > 0 && name < summary.MostActiveRepo) {
+			best = count
+			summary.MostActiveRepo = name
+		}
+	}
+
+	summary.AnalyzedPRs = len(leadTimes)
+	if len(leadTimes) > 0 {
+		summary.LeadTimeP50Seconds, _ = stats.Percentile(leadTimes, 50)
+		summary.LeadTimeP90Seconds, _ = stats.Percentile(leadTimes, 90)
+	}
+	return summary
+}
@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummarize(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "repo-a", Commits: 10, CreatedPRs: 2, ReviewedPRs: 1, LeadTimeToLastReviewSeconds: []float64{100, 200}},
+		{Name: "repo-b", Commits: 5, CreatedPRs: 1, ReviewedPRs: 4, LeadTimeToLastReviewSeconds: []float64{300, 400, 500, 10000}},
+		{Name: "repo-c", ReviewedPRs: 2},
+	}
+
+	summary := Summarize(results)
+
+	assert.Equal(t, 3, summary.ReposTouched)
+	assert.Equal(t, 15, summary.Commits)
+	assert.Equal(t, 3, summary.CreatedPRs)
+	assert.Equal(t, 7, summary.ReviewedPRs)
+	assert.Equal(t, 6, summary.AnalyzedPRs)
+	// The percentiles are taken over all six lead times, so they differ from the
+	// mean of the per-repo medians (150 and 450).
+	assert.Equal(t, 350.0, summary.LeadTimeP50Seconds)
+	assert.Equal(t, 5250.0, summary.LeadTimeP90Seconds)
+	assert.Equal(t, "repo-a", summary.MostActiveRepo)
+}
+
+func TestSummarize_ReposTouchedSkipsZeroRepos(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "repo-a", Commits: 1},
+		{Name: "repo-b", OpenPRs: 1},
+		// Only org-wide totals and lead time samples, none of the user's counts.
+		{Name: "repo-c", OrgCommits: 10, LeadTimeToLastReviewSeconds: []float64{60}},
+		{Name: "repo-d"},
+	}
+	assert.Equal(t, 2, Summarize(results).ReposTouched)
+}
+
+func TestSummarize_CombinesUsers(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "repo-a", User: "alice", Commits: 3},
+		{Name: "repo-b", User: "alice", Commits: 4},
+		{Name: "repo-a", User: "bob", CreatedPRs: 2},
+	}
+
+	summary := Summarize(results)
+
+	assert.Equal(t, 2, summary.ReposTouched, "a repository shared by two users counts once")
+	assert.Equal(t, "repo-a", summary.MostActiveRepo, "the activity of both users is combined")
+}
+
+func TestSummarize_TieBreaksByName(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "repo-b", Commits: 2},
+		{Name: "repo-a", CreatedPRs: 2},
+	}
+	assert.Equal(t, "repo-a", Summarize(results).MostActiveRepo)
+}
+
+func TestSummarize_Empty(t *testing.T) {
+	assert.Equal(t, Summary{}, Summarize(nil))
+	assert.Equal(t, Summary{}, Summarize([]*domain.RepoStats{{Name: "repo-a"}}),
+		"a repository without activity is neither touched nor the most active one")
+}
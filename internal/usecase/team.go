@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// ParseTeam splits a team given as "org/team-slug".
+func ParseTeam(team string) (org, slug string, err error) {
+	org, slug, ok := strings.Cut(team, "/")
+	if !ok || org == "" || slug == "" || strings.Contains(slug, "/") {
+		return "", "", fmt.Errorf("invalid team %q, expected org/team-slug", team)
+	}
+	return org, slug, nil
+}
+
+// TeamMembers resolves the members of the given "org/team-slug" teams, in the order the teams are given.
+// Members of several teams are listed for each of them; callers deduplicate the combined user list.
+func (a *Aggregator) TeamMembers(ctx context.Context, teams []string) ([]string, error) {
+	var members []string
+	for _, team := range teams {
+		org, slug, err := ParseTeam(team)
+		if err != nil {
+			return nil, err
+		}
+		var teamMembers []string
+		err = a.limited(ctx, func() error {
+			var err error
+			teamMembers, err = a.fetcher.FetchTeamMembers(ctx, org, slug)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(teamMembers) == 0 {
+			return nil, fmt.Errorf("team %s has no members visible to the token", team)
+		}
+		a.logger.Printf("Usecase: Team %s has %d members.\n", team, len(teamMembers))
+		members = append(members, teamMembers...)
+	}
+	return members, nil
+}
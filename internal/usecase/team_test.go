@@ -0,0 +1,66 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTeam(t *testing.T) {
+	org, slug, err := ParseTeam("acme/platform-team")
+	require.NoError(t, err)
+	assert.Equal(t, "acme", org)
+	assert.Equal(t, "platform-team", slug)
+
+	for _, team := range []string{"platform-team", "/platform", "acme/", "acme/a/b"} {
+		_, _, err := ParseTeam(team)
+		assert.ErrorContains(t, err, "expected org/team-slug", team)
+	}
+}
+
+func TestAggregator_TeamMembers_AggregatesEveryMember(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchTeamMembers", mock.Anything, "acme", "platform").Return([]string{"alice", "bob"}, nil)
+	fetcher.On("FetchCommits", mock.Anything, "acme", "alice", mock.Anything).Return(map[string]int{"acme/api": 3}, nil)
+	fetcher.On("FetchCommits", mock.Anything, "acme", "bob", mock.Anything).Return(map[string]int{"acme/web": 1}, nil)
+	fetcher.On("FetchCreatedPRs", mock.Anything, "acme", mock.Anything, mock.Anything).Return(map[string]int{}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "acme", "bob", mock.Anything).Return(map[string]int{"acme/api": 2}, nil)
+	fetcher.On("FetchReviewedPRs", mock.Anything, "acme", "alice", mock.Anything).Return(map[string]int{}, nil)
+
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+	members, err := aggregator.TeamMembers(context.Background(), []string{"acme/platform"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alice", "bob"}, members)
+
+	results, err := aggregator.AggregateUsers(context.Background(), "acme", members, Options{})
+	require.NoError(t, err)
+	assert.Equal(t, []*domain.RepoStats{
+		{Name: "acme/api", User: "alice", Commits: 3},
+		{Name: "acme/api", User: "bob", ReviewedPRs: 2},
+		{Name: "acme/web", User: "bob", Commits: 1},
+	}, results)
+	fetcher.AssertExpectations(t)
+}
+
+func TestAggregator_TeamMembers_Errors(t *testing.T) {
+	fetcher := new(mockFetcher)
+	fetcher.On("FetchTeamMembers", mock.Anything, "acme", "empty").Return([]string{}, nil)
+	fetcher.On("FetchTeamMembers", mock.Anything, "acme", "missing").Return(nil, errors.New("404 Not Found"))
+	aggregator := NewAggregator(fetcher, log.New(io.Discard, "", 0))
+
+	_, err := aggregator.TeamMembers(context.Background(), []string{"acme/empty"})
+	assert.ErrorContains(t, err, "team acme/empty has no members")
+
+	_, err = aggregator.TeamMembers(context.Background(), []string{"acme/missing"})
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	_, err = aggregator.TeamMembers(context.Background(), []string{"missing-slash"})
+	assert.ErrorContains(t, err, "expected org/team-slug")
+}
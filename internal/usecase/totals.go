@@ -0,0 +1,62 @@
+package usecase
+
+import "github.com/naka-gawa/github-stats/internal/domain"
+
+// TotalName is the name of the synthetic row that sums every repository.
+const TotalName = "TOTAL"
+
+// Totals sums the counts of all repositories into a single synthetic RepoStats.
+// Lead time samples are concatenated rather than summarized, so percentiles computed
+// from the total reflect every analyzed PR instead of an average of per-repo percentiles.
+func Totals(results []*domain.RepoStats) *domain.RepoStats {
+	total := &domain.RepoStats{Name: TotalName}
+	for _, repoStat := range results {
+		total.Commits += repoStat.Commits
+		total.CreatedPRs += repoStat.CreatedPRs
+		total.DraftPRs += repoStat.DraftPRs
+		total.MergedPRs += repoStat.MergedPRs
+		total.ReviewedPRs += repoStat.ReviewedPRs
+		total.CommentedPRs += repoStat.CommentedPRs
+		total.ReviewComments += repoStat.ReviewComments
+		total.ApprovalsGiven += repoStat.ApprovalsGiven
+		total.AssignedPRs += repoStat.AssignedPRs
+		total.OpenPRs += repoStat.OpenPRs
+		total.CreatedIssues += repoStat.CreatedIssues
+		total.ClosedIssues += repoStat.ClosedIssues
+		total.OrgCommits += repoStat.OrgCommits
+		total.OrgCreatedPRs += repoStat.OrgCreatedPRs
+		total.TotalAdditions += repoStat.TotalAdditions
+		total.TotalDeletions += repoStat.TotalDeletions
+		total.CommitAdditions += repoStat.CommitAdditions
+		total.CommitDeletions += repoStat.CommitDeletions
+		total.PRSizeLines = append(total.PRSizeLines, repoStat.PRSizeLines...)
+		total.LeadTimeToLastReviewSeconds = append(total.LeadTimeToLastReviewSeconds, repoStat.LeadTimeToLastReviewSeconds...)
+		total.TimeToFirstReviewSeconds = append(total.TimeToFirstReviewSeconds, repoStat.TimeToFirstReviewSeconds...)
+		total.MergeTimeSeconds = append(total.MergeTimeSeconds, repoStat.MergeTimeSeconds...)
+		total.ReviewerLatencySeconds = append(total.ReviewerLatencySeconds, repoStat.ReviewerLatencySeconds...)
+		total.AuthorWaitSeconds = append(total.AuthorWaitSeconds, repoStat.AuthorWaitSeconds...)
+		total.PullRequests = append(total.PullRequests, repoStat.PullRequests...)
+		if repoStat.CommitHeatmap != nil {
+			if total.CommitHeatmap == nil {
+				total.CommitHeatmap = &domain.Heatmap{}
+			}
+			for day := range repoStat.CommitHeatmap {
+				for hour, count := range repoStat.CommitHeatmap[day] {
+					total.CommitHeatmap[day][hour] += count
+				}
+			}
+		}
+		for key, bucket := range repoStat.Series {
+			if total.Series == nil {
+				total.Series = make(map[string]domain.BucketStats, len(repoStat.Series))
+			}
+			sum := total.Series[key]
+			sum.Commits += bucket.Commits
+			sum.CreatedPRs += bucket.CreatedPRs
+			sum.ReviewedPRs += bucket.ReviewedPRs
+			total.Series[key] = sum
+		}
+	}
+	total.MedianPRSizeLines = medianOf(total.PRSizeLines)
+	return total
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/montanaflynn/stats"
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTotals(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "repo-a", Commits: 10, CreatedPRs: 2, ReviewedPRs: 1, LeadTimeToLastReviewSeconds: []float64{100, 200}},
+		{Name: "repo-b", Commits: 5, CreatedPRs: 1, ReviewedPRs: 4, LeadTimeToLastReviewSeconds: []float64{300, 400, 500, 10000}},
+		{Name: "repo-c", ReviewedPRs: 2},
+	}
+
+	total := Totals(results)
+
+	assert.Equal(t, TotalName, total.Name)
+	assert.Equal(t, 15, total.Commits)
+	assert.Equal(t, 3, total.CreatedPRs)
+	assert.Equal(t, 7, total.ReviewedPRs)
+	assert.Equal(t, []float64{100, 200, 300, 400, 500, 10000}, total.LeadTimeToLastReviewSeconds)
+
+	// The median of the concatenation differs from the mean of the per-repo medians (150 and 450).
+	median, err := stats.Percentile(total.LeadTimeToLastReviewSeconds, 50)
+	assert.NoError(t, err)
+	assert.Equal(t, 350.0, median)
+}
+
+func TestTotals_Empty(t *testing.T) {
+	total := Totals(nil)
+	assert.Equal(t, &domain.RepoStats{Name: TotalName}, total)
+}
+
+func TestTotals_CommitHeatmap(t *testing.T) {
+	a, b := &domain.Heatmap{}, &domain.Heatmap{}
+	a[1][9], b[1][9], b[5][17] = 2, 1, 3
+	results := []*domain.RepoStats{{Name: "repo-a", CommitHeatmap: a}, {Name: "repo-b", CommitHeatmap: b}, {Name: "repo-c"}}
+
+	expected := &domain.Heatmap{}
+	expected[1][9], expected[5][17] = 3, 3
+	assert.Equal(t, expected, Totals(results).CommitHeatmap)
+	assert.Nil(t, Totals(results[2:]).CommitHeatmap)
+}
+
+func TestTotals_Series(t *testing.T) {
+	results := []*domain.RepoStats{
+		{Name: "repo-a", Series: map[string]domain.BucketStats{"2025-01-06": {Commits: 1}, "2025-01-13": {Commits: 2, CreatedPRs: 1}}},
+		{Name: "repo-b", Series: map[string]domain.BucketStats{"2025-01-06": {Commits: 3, ReviewedPRs: 2}, "2025-01-13": {}}},
+	}
+
+	assert.Equal(t, map[string]domain.BucketStats{
+		"2025-01-06": {Commits: 4, ReviewedPRs: 2},
+		"2025-01-13": {Commits: 2, CreatedPRs: 1},
+	}, Totals(results).Series)
+}
@@ -0,0 +1,234 @@
+// Package stats is the public entry point for embedding github-stats in other Go programs.
+// It wires the GitHub gateway and the aggregator together so callers don't need the CLI.
+package stats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/domain"
+	"github.com/naka-gawa/github-stats/internal/gateway"
+	"github.com/naka-gawa/github-stats/internal/usecase"
+)
+
+// RepoStats holds the aggregated activity for a single repository.
+type RepoStats = domain.RepoStats
+
+// PRLeadTime holds the lead time details of a single analyzed pull request.
+type PRLeadTime = domain.PRLeadTime
+
+// Config describes a single aggregation run.
+type Config struct {
+	// Token is a GitHub personal access token. It is required.
+	Token string
+	// BaseURL points at a GitHub Enterprise Server, e.g. https://github.example.com. Empty means github.com.
+	BaseURL string
+	// Org and User select whose activity is aggregated. Both are required.
+	// Org may list several organizations separated by commas.
+	Org  string
+	User string
+	// From and To limit the aggregation period. A zero value leaves that side of the range open.
+	// Both are whole days in their own location, so use time.UTC unless another time zone is wanted.
+	From time.Time
+	To   time.Time
+
+	SkipCommits       bool
+	SkipCreatedPRs    bool
+	SkipReviewedPRs   bool
+	Drafts            bool
+	ExcludeDrafts     bool
+	MergedPRs         bool
+	MergedCommitsOnly bool
+	DedupeCommits     bool
+	CommentStats      bool
+	ReviewComments    bool
+	Approvals         bool
+	Assigned          bool
+	OpenPRs           bool
+	Issues            bool
+	Churn             bool
+	ReviewerLatency   bool
+	AuthorWait        bool
+	CommitChurn       bool
+	CalculateLeadTime bool
+	HandleReopens     bool
+	Share             bool
+	WithNodeIDs       bool
+	Languages         bool
+
+	// CommitsSource is "rest" (the default when empty) to count commits with the commit search, or "graphql" to
+	// count them in the default branch history of every repository of Org. GraphQL avoids the search rate limit
+	// but costs more requests for organizations with many inactive repositories.
+	CommitsSource string
+
+	// ReviewStates are the review states that count as a review for the lead times, such as "APPROVED".
+	// Empty means gateway.DefaultReviewStates: approvals, change requests and comments.
+	ReviewStates []string
+
+	// ExcludeBots leaves PRs authored by bots out of the reviewed PR count.
+	// BotLogins are logins treated as bots in addition to GitHub Apps and logins ending in "[bot]".
+	ExcludeBots bool
+	BotLogins   []string
+
+	// Heatmap additionally counts the commits per weekday and hour of their author date (RepoStats.CommitHeatmap)
+	// in HeatmapLocation, which is UTC when nil.
+	Heatmap         bool
+	HeatmapLocation *time.Location
+
+	// Labels limit the created, merged and reviewed PRs and the lead times to PRs carrying every one of the labels.
+	Labels []string
+
+	// Granularity is "weekly" or "monthly" to also split the counts into a time series (RepoStats.Series).
+	// It needs From; a zero To ends the series today.
+	Granularity string
+
+	// IncludeRepos and ExcludeRepos are glob patterns matched against "owner/name".
+	// ExcludeRepos wins when a repository matches both.
+	IncludeRepos []string
+	ExcludeRepos []string
+
+	// Concurrency bounds the number of API calls in flight. Zero means 4.
+	Concurrency int
+
+	// Logger receives progress messages. When nil, they are discarded.
+	Logger *log.Logger
+}
+
+// Run aggregates the configured user's activity and returns one entry per repository, sorted by name.
+func Run(ctx context.Context, cfg Config) ([]RepoStats, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	logger := cfg.Logger
+	if logger == nil {
+		logger = log.New(io.Discard, "", 0)
+	}
+
+	var opts []gateway.Option
+	if cfg.BaseURL != "" {
+		opts = append(opts, gateway.WithBaseURL(cfg.BaseURL))
+	}
+	if cfg.DedupeCommits {
+		opts = append(opts, gateway.WithCommitDedupe())
+	}
+	if cfg.CommitsSource != "" {
+		opts = append(opts, gateway.WithCommitsSource(cfg.CommitsSource))
+	}
+	if len(cfg.ReviewStates) > 0 {
+		// validate has already checked them.
+		states, _ := gateway.ParseReviewStates(cfg.ReviewStates)
+		opts = append(opts, gateway.WithReviewStates(states))
+	}
+	if cfg.ExcludeBots {
+		opts = append(opts, gateway.WithBotExclusion(cfg.BotLogins))
+	}
+	if len(cfg.Labels) > 0 {
+		opts = append(opts, gateway.WithLabels(cfg.Labels))
+	}
+	fetcher, err := gateway.NewGitHubGateway(cfg.Token, logger, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return run(ctx, cfg, fetcher, logger)
+}
+
+// run performs the aggregation with the given fetcher.
+func run(ctx context.Context, cfg Config, fetcher gateway.Fetcher, logger *log.Logger) ([]RepoStats, error) {
+	commitDateRange, prDateRange := usecase.DateRangeQualifiers(cfg.From, cfg.To)
+	var buckets []usecase.Bucket
+	if cfg.Granularity != "" {
+		to := cfg.To
+		if to.IsZero() {
+			to = time.Now().In(cfg.From.Location())
+		}
+		var err error
+		buckets, err = usecase.SplitBuckets(cfg.From, to, cfg.Granularity)
+		if err != nil {
+			return nil, fmt.Errorf("stats: %w", err)
+		}
+	}
+	var aggregatorOpts []usecase.AggregatorOption
+	if cfg.Concurrency > 0 {
+		aggregatorOpts = append(aggregatorOpts, usecase.WithConcurrency(cfg.Concurrency))
+	}
+	results, err := usecase.NewAggregator(fetcher, logger, aggregatorOpts...).Aggregate(ctx, cfg.Org, cfg.User, usecase.Options{
+		CommitDateRange:   commitDateRange,
+		PRDateRange:       prDateRange,
+		SkipCommits:       cfg.SkipCommits,
+		SkipCreatedPRs:    cfg.SkipCreatedPRs,
+		SkipReviewedPRs:   cfg.SkipReviewedPRs,
+		Drafts:            cfg.Drafts,
+		ExcludeDrafts:     cfg.ExcludeDrafts,
+		MergedPRs:         cfg.MergedPRs,
+		MergedCommitsOnly: cfg.MergedCommitsOnly,
+		CommentStats:      cfg.CommentStats,
+		ReviewComments:    cfg.ReviewComments,
+		Approvals:         cfg.Approvals,
+		Assigned:          cfg.Assigned,
+		OpenPRs:           cfg.OpenPRs,
+		Issues:            cfg.Issues,
+		Churn:             cfg.Churn,
+		ReviewerLatency:   cfg.ReviewerLatency,
+		AuthorWait:        cfg.AuthorWait,
+		CommitChurn:       cfg.CommitChurn,
+		CalculateLeadTime: cfg.CalculateLeadTime,
+		HandleReopens:     cfg.HandleReopens,
+		Share:             cfg.Share,
+		WithNodeIDs:       cfg.WithNodeIDs,
+		Languages:         cfg.Languages,
+		Heatmap:           cfg.Heatmap,
+		HeatmapLocation:   cfg.HeatmapLocation,
+		Labels:            cfg.Labels,
+		IncludeRepos:      cfg.IncludeRepos,
+		ExcludeRepos:      cfg.ExcludeRepos,
+		Buckets:           buckets,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	repoStats := make([]RepoStats, 0, len(results))
+	for _, result := range results {
+		repoStats = append(repoStats, *result)
+	}
+	return repoStats, nil
+}
+
+// validate checks that the required fields are set.
+func (cfg Config) validate() error {
+	switch {
+	case cfg.Token == "":
+		return errors.New("stats: Token is required")
+	case cfg.Org == "":
+		return errors.New("stats: Org is required")
+	case cfg.User == "":
+		return errors.New("stats: User is required")
+	case !cfg.From.IsZero() && !cfg.To.IsZero() && cfg.To.Before(cfg.From):
+		return errors.New("stats: To must not be before From")
+	case cfg.Concurrency < 0:
+		return errors.New("stats: Concurrency must not be negative")
+	}
+	if cfg.CommitsSource != "" {
+		if err := gateway.ValidateCommitsSource(cfg.CommitsSource); err != nil {
+			return fmt.Errorf("stats: %w", err)
+		}
+	}
+	if len(cfg.ReviewStates) > 0 {
+		if _, err := gateway.ParseReviewStates(cfg.ReviewStates); err != nil {
+			return fmt.Errorf("stats: %w", err)
+		}
+	}
+	if cfg.Share && cfg.MergedCommitsOnly {
+		return errors.New("stats: Share cannot be combined with MergedCommitsOnly")
+	}
+	for _, patterns := range [][]string{cfg.IncludeRepos, cfg.ExcludeRepos} {
+		if err := usecase.ValidateRepoPatterns(patterns); err != nil {
+			return fmt.Errorf("stats: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package stats
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/naka-gawa/github-stats/internal/gateway"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubFetcher implements only the fetches exercised here; calling any other method panics.
+type stubFetcher struct {
+	gateway.Fetcher
+	gotDateRange string
+}
+
+func (s *stubFetcher) FetchCommits(ctx context.Context, org, user, dateRange string) (map[string]int, error) {
+	s.gotDateRange = dateRange
+	return map[string]int{"org/repo-b": 1, "org/repo-a": 2}, nil
+}
+
+func TestRun_Validation(t *testing.T) {
+	testCases := []struct {
+		name   string
+		cfg    Config
+		errMsg string
+	}{
+		{name: "missing token", cfg: Config{Org: "org", User: "user"}, errMsg: "Token is required"},
+		{name: "missing org", cfg: Config{Token: "t", User: "user"}, errMsg: "Org is required"},
+		{name: "missing user", cfg: Config{Token: "t", Org: "org"}, errMsg: "User is required"},
+		{
+			name:   "inverted range",
+			cfg:    Config{Token: "t", Org: "org", User: "user", From: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+			errMsg: "To must not be before From",
+		},
+		{name: "unknown review state", cfg: Config{Token: "t", Org: "org", User: "user", ReviewStates: []string{"MERGED"}}, errMsg: "unknown review state"},
+		{name: "negative concurrency", cfg: Config{Token: "t", Org: "org", User: "user", Concurrency: -1}, errMsg: "Concurrency must not be negative"},
+		{
+			name:   "share of merged commits",
+			cfg:    Config{Token: "t", Org: "org", User: "user", Share: true, MergedCommitsOnly: true},
+			errMsg: "Share cannot be combined with MergedCommitsOnly",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Run(context.Background(), tc.cfg)
+			assert.ErrorContains(t, err, tc.errMsg)
+		})
+	}
+}
+
+func TestRun_AggregatesWithFetcher(t *testing.T) {
+	fetcher := &stubFetcher{}
+	cfg := Config{
+		Org:             "org",
+		User:            "user",
+		From:            time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		SkipCreatedPRs:  true,
+		SkipReviewedPRs: true,
+	}
+
+	results, err := run(context.Background(), cfg, fetcher, log.New(io.Discard, "", 0))
+	require.NoError(t, err)
+	assert.Equal(t, " author-date:2025-04-01..*", fetcher.gotDateRange)
+	assert.Equal(t, []RepoStats{{Name: "org/repo-a", Commits: 2}, {Name: "org/repo-b", Commits: 1}}, results)
+}